@@ -378,8 +378,12 @@ func (b *Block) getHashData() string {
 	merkleRoot := mt.GetRoot()
 	rmt := b.GetReceiptsMerkleTree()
 	rMerkleRoot := rmt.GetRoot()
-	hashData := b.MinerID + ":" + b.PrevHash + ":" + common.TimeToString(b.CreationDate) + ":" + strconv.FormatInt(b.Round, 10) + ":" + strconv.FormatInt(b.GetRoundRandomSeed(), 10) + ":" + merkleRoot + ":" + rMerkleRoot
-	return hashData
+	return blockHashData(b.MinerID, b.PrevHash, b.CreationDate, b.Round, b.GetRoundRandomSeed(), merkleRoot, rMerkleRoot)
+}
+
+// blockHashData - data used to hash a block
+func blockHashData(minerID, prevHash string, creationDate common.Timestamp, round, roundRandomSeed int64, merkleRoot, rMerkleRoot string) string {
+	return minerID + ":" + prevHash + ":" + common.TimeToString(creationDate) + ":" + strconv.FormatInt(round, 10) + ":" + strconv.FormatInt(roundRandomSeed, 10) + ":" + merkleRoot + ":" + rMerkleRoot
 }
 
 /*ComputeHash - compute the hash of the block */
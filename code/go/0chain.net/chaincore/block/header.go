@@ -0,0 +1,179 @@
+package block
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+/*NotarizationThreshold - the percentage of the miners of a magic block with
+* valid verification tickets a light client requires to accept a block header;
+* it's a client side constant, never taken from a sharder */
+const NotarizationThreshold = 66
+
+/*Header - the header of a finalized block for light clients. It has all the
+* fields the block hash is computed from, the links to the previous block and
+* the latest finalized magic block and the verification tickets, so the block
+* can be verified without its transactions. The magic block is set only for
+* the blocks that introduce a new magic block. */
+type Header struct {
+	*BlockSummary
+	PrevHash                       string                `json:"prev_hash"`
+	LatestFinalizedMagicBlockHash  string                `json:"latest_finalized_magic_block_hash"`
+	LatestFinalizedMagicBlockRound int64                 `json:"latest_finalized_magic_block_round"`
+	Signature                      string                `json:"signature"`
+	VerificationTickets            []*VerificationTicket `json:"verification_tickets"`
+}
+
+/*GetHeader - get the header of this block */
+func (b *Block) GetHeader() *Header {
+	return &Header{
+		BlockSummary:                   b.GetSummary(),
+		PrevHash:                       b.PrevHash,
+		LatestFinalizedMagicBlockHash:  b.LatestFinalizedMagicBlockHash,
+		LatestFinalizedMagicBlockRound: b.LatestFinalizedMagicBlockRound,
+		Signature:                      b.Signature,
+		VerificationTickets:            b.GetVerificationTickets(),
+	}
+}
+
+/*ComputeHash - compute the hash of the block of the header */
+func (h *Header) ComputeHash() string {
+	return encryption.Hash(blockHashData(h.MinerID, h.PrevHash,
+		h.CreationDate, h.Round, h.RoundRandomSeed, h.MerkleTreeRoot,
+		h.ReceiptMerkleTreeRoot))
+}
+
+/*VerifyMinerKeys - verify the magic block is complete, its hash is the
+* hash of its content and the IDs of its miners are the hashes of their public
+* keys, so the keys of the miners are covered by the hash */
+func (mb *MagicBlock) VerifyMinerKeys() error {
+	if mb == nil || mb.Miners == nil || mb.Sharders == nil ||
+		mb.ShareOrSigns == nil || mb.Mpks == nil {
+		return common.NewError("invalid_magic_block", "incomplete magic block")
+	}
+	if mb.GetHash() != mb.Hash {
+		return common.NewError("invalid_magic_block",
+			"magic block hash mismatch")
+	}
+	for id, miner := range mb.Miners.CopyNodesMap() {
+		var key, err = hex.DecodeString(miner.PublicKey)
+		if err != nil || miner.ID != id || encryption.Hash(key) != id {
+			return common.NewError("invalid_magic_block",
+				"public key doesn't match miner id "+id)
+		}
+	}
+	return nil
+}
+
+/*VerifyMagicBlock - verify the magic block of the header is the trusted
+* magic block or the next one, referring to the trusted one by its hash; the
+* block introducing the next magic block must be notarized by the miners of
+* the trusted one */
+func (h *Header) VerifyMagicBlock(trusted *MagicBlock) error {
+	if h.BlockSummary == nil || trusted == nil {
+		return common.NewError("invalid_header", "missing block summary")
+	}
+	if h.ComputeHash() != h.Hash {
+		return ErrBlockHashMismatch
+	}
+	var mb = h.MagicBlock
+	if err := mb.VerifyMinerKeys(); err != nil {
+		return err
+	}
+	if mb.Hash == trusted.Hash {
+		return nil
+	}
+	if mb.PreviousMagicBlockHash != trusted.Hash ||
+		mb.MagicBlockNumber != trusted.MagicBlockNumber+1 {
+		return common.NewError("untrusted_magic_block",
+			"magic block doesn't follow the trusted one")
+	}
+	if h.Round < trusted.StartingRound {
+		return common.NewError("untrusted_magic_block",
+			"magic block is before the starting round of the trusted one")
+	}
+	return h.verifyTickets(trusted)
+}
+
+/*VerifyMagicBlockHeaders - verify the headers of the blocks with the
+* consecutive magic blocks starting from the trusted one or the next one; the
+* latest magic block verified is returned, it can be trusted then */
+func VerifyMagicBlockHeaders(trusted *MagicBlock, mbhs []*Header) (
+	*MagicBlock, error) {
+
+	for _, mbh := range mbhs {
+		if mbh == nil {
+			return nil, common.NewError("invalid_header", "missing header")
+		}
+		if err := mbh.VerifyMagicBlock(trusted); err != nil {
+			return nil, err
+		}
+		trusted = mbh.MagicBlock
+	}
+	return trusted, nil
+}
+
+/*VerifyNotarization - verify the header is the header of a block notarized
+* by the miners of the trusted magic block. The mbh is the header of the block
+* with the magic block the header refers to, its magic block must be the
+* trusted one. NotarizationThreshold percents of the miners of the magic
+* block must have valid verification tickets */
+func (h *Header) VerifyNotarization(mbh *Header, trusted *MagicBlock) error {
+	if h.BlockSummary == nil || mbh == nil || mbh.BlockSummary == nil {
+		return common.NewError("invalid_header", "missing block summary")
+	}
+	if h.ComputeHash() != h.Hash {
+		return ErrBlockHashMismatch
+	}
+	if h.LatestFinalizedMagicBlockHash != mbh.Hash ||
+		h.LatestFinalizedMagicBlockRound != mbh.Round {
+		return common.NewError("magic_block_mismatch",
+			"header refers to another magic block")
+	}
+	if err := mbh.VerifyMagicBlock(trusted); err != nil {
+		return err
+	}
+	// use the trusted magic block, not the one given with the header
+	var mb = trusted
+	if mbh.MagicBlock.Hash != mb.Hash {
+		return common.NewError("untrusted_magic_block",
+			"header refers to not trusted magic block")
+	}
+	if h.Round < mb.StartingRound {
+		return common.NewError("magic_block_mismatch",
+			"block is before the starting round of the magic block")
+	}
+	return h.verifyTickets(mb)
+}
+
+// verifyTickets verifies NotarizationThreshold percents of the miners of the
+// magic block have signed the hash of the header
+func (h *Header) verifyTickets(mb *MagicBlock) error {
+	var verified = make(map[string]struct{}, len(h.VerificationTickets))
+	for _, vt := range h.VerificationTickets {
+		if vt == nil {
+			continue
+		}
+		if _, ok := verified[vt.VerifierID]; ok {
+			continue
+		}
+		var miner = mb.Miners.GetNode(vt.VerifierID)
+		if miner == nil {
+			continue
+		}
+		if ok, err := miner.Verify(vt.Signature, h.Hash); err != nil || !ok {
+			continue
+		}
+		verified[vt.VerifierID] = struct{}{}
+	}
+	var miners = mb.Miners.MapSize()
+	if miners == 0 || len(verified)*100 < miners*NotarizationThreshold {
+		return common.NewError("not_notarized",
+			fmt.Sprintf("%d of %d miners verified the block, %d%% required",
+				len(verified), miners, NotarizationThreshold))
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package block
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/client"
+	"0chain.net/chaincore/node"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMiner(t *testing.T) (*node.Node, *encryption.ED25519Scheme) {
+	var scheme = encryption.NewED25519Scheme()
+	require.NoError(t, scheme.GenerateKeys())
+	var n = node.Provider()
+	n.Type = node.NodeTypeMiner
+	n.SetPublicKey(scheme.GetPublicKey())
+	return n, scheme
+}
+
+func newTestHeader(round int64, mbh *Header) (h *Header) {
+	h = &Header{BlockSummary: &BlockSummary{}}
+	h.MinerID = "miner"
+	h.Round = round
+	h.RoundRandomSeed = 42
+	h.CreationDate = common.Now()
+	h.MerkleTreeRoot = encryption.Hash("txns")
+	h.ReceiptMerkleTreeRoot = encryption.Hash("receipts")
+	h.PrevHash = encryption.Hash("prev")
+	if mbh != nil {
+		h.LatestFinalizedMagicBlockHash = mbh.Hash
+		h.LatestFinalizedMagicBlockRound = mbh.Round
+	}
+	h.Hash = h.ComputeHash()
+	return
+}
+
+func newTestMagicBlock(t *testing.T, number int64, prev *MagicBlock,
+	miners int) (mb *MagicBlock, schemes []*encryption.ED25519Scheme,
+	ids []string) {
+
+	mb = NewMagicBlock()
+	mb.MagicBlockNumber = number
+	mb.StartingRound = number * 10
+	if prev != nil {
+		mb.PreviousMagicBlockHash = prev.Hash
+	}
+	mb.Miners = node.NewPool(node.NodeTypeMiner)
+	mb.Sharders = node.NewPool(node.NodeTypeSharder)
+	for i := 0; i < miners; i++ {
+		n, scheme := newTestMiner(t)
+		mb.Miners.AddNode(n)
+		schemes = append(schemes, scheme)
+		ids = append(ids, n.GetKey())
+	}
+	mb.Hash = mb.GetHash()
+	return
+}
+
+// notarize the header by the miners of given schemes
+func notarize(t *testing.T, h *Header, schemes []*encryption.ED25519Scheme,
+	ids []string) {
+
+	h.VerificationTickets = nil
+	for i, scheme := range schemes {
+		sig, err := scheme.Sign(h.Hash)
+		require.NoError(t, err)
+		h.VerificationTickets = append(h.VerificationTickets,
+			&VerificationTicket{VerifierID: ids[i], Signature: sig})
+	}
+}
+
+func TestVerifyMagicBlockHeaders(t *testing.T) {
+	client.SetClientSignatureScheme("ed25519")
+
+	var (
+		mb1, s1, ids1 = newTestMagicBlock(t, 1, nil, 3)
+		mb2, s2, ids2 = newTestMagicBlock(t, 2, mb1, 3)
+		mb3, _, _     = newTestMagicBlock(t, 3, mb2, 3)
+		mbh1          = newTestHeader(10, nil)
+		mbh2          = newTestHeader(20, nil)
+		mbh3          = newTestHeader(30, nil)
+	)
+	mbh1.MagicBlock, mbh2.MagicBlock, mbh3.MagicBlock = mb1, mb2, mb3
+	// the transitions are notarized by the miners of the previous ones
+	notarize(t, mbh2, s1[:2], ids1[:2])
+	notarize(t, mbh3, s2, ids2)
+
+	latest, err := VerifyMagicBlockHeaders(mb1,
+		[]*Header{mbh1, mbh2, mbh3})
+	require.NoError(t, err)
+	assert.Equal(t, mb3, latest)
+
+	// gap
+	_, err = VerifyMagicBlockHeaders(mb1, []*Header{mbh3})
+	assert.Error(t, err)
+
+	// not notarized transition
+	var tickets = mbh2.VerificationTickets
+	mbh2.VerificationTickets = nil
+	_, err = VerifyMagicBlockHeaders(mb1, []*Header{mbh2})
+	assert.Error(t, err)
+	mbh2.VerificationTickets = tickets[:1]
+	_, err = VerifyMagicBlockHeaders(mb1, []*Header{mbh2})
+	assert.Error(t, err)
+	mbh2.VerificationTickets = tickets
+
+	// forged successor with keys of the forger, notarized by the forger
+	var (
+		forged, fs, fids = newTestMagicBlock(t, 2, mb1, 3)
+		forgedMBH        = newTestHeader(20, nil)
+	)
+	forgedMBH.MagicBlock = forged
+	notarize(t, forgedMBH, fs, fids)
+	_, err = VerifyMagicBlockHeaders(mb1, []*Header{mbh1, forgedMBH})
+	assert.Error(t, err)
+	// and the blocks notarized by the forged miners aren't accepted then
+	var h = newTestHeader(25, forgedMBH)
+	notarize(t, h, fs, fids)
+	assert.Error(t, h.VerifyNotarization(forgedMBH, mb1))
+
+	// not linked to the trusted one
+	var other, _, _ = newTestMagicBlock(t, 2, nil, 3)
+	mbh2.MagicBlock = other
+	_, err = VerifyMagicBlockHeaders(mb1, []*Header{mbh2})
+	assert.Error(t, err)
+
+	// the content doesn't match the hash
+	mb2.StartingRound++
+	mbh2.MagicBlock = mb2
+	_, err = VerifyMagicBlockHeaders(mb1, []*Header{mbh2})
+	assert.Error(t, err)
+	mb2.StartingRound--
+
+	// the key of a miner replaced, the miner ID kept
+	var swapped, _ = newTestMiner(t)
+	var id = mb2.Miners.Keys()[0]
+	swapped.ID = id
+	mb2.Miners.NodesMap[id] = swapped
+	_, err = VerifyMagicBlockHeaders(mb1, []*Header{mbh2})
+	assert.Error(t, err)
+}
+
+func TestHeader_VerifyNotarization(t *testing.T) {
+	client.SetClientSignatureScheme("ed25519")
+
+	var mb, schemes, ids = newTestMagicBlock(t, 1, nil, 3)
+
+	var mbh = newTestHeader(1, nil)
+	mbh.MagicBlock = mb
+
+	var h = newTestHeader(10, mbh)
+	for i, scheme := range schemes[:2] {
+		sig, err := scheme.Sign(h.Hash)
+		require.NoError(t, err)
+		h.VerificationTickets = append(h.VerificationTickets,
+			&VerificationTicket{VerifierID: ids[i], Signature: sig})
+	}
+	// duplicate
+	h.VerificationTickets = append(h.VerificationTickets,
+		h.VerificationTickets[0])
+
+	require.NoError(t, h.VerifyNotarization(mbh, mb))
+
+	// less than the threshold
+	var single = *h
+	single.VerificationTickets = h.VerificationTickets[:1]
+	assert.Error(t, single.VerifyNotarization(mbh, mb))
+
+	// wrong magic block
+	var other = newTestHeader(2, nil)
+	other.MagicBlock = mb
+	assert.Error(t, h.VerifyNotarization(other, mb))
+
+	// not trusted magic block with keys of the forger
+	var forgedMB, _, _ = newTestMagicBlock(t, 1, nil, 3)
+	assert.Error(t, h.VerifyNotarization(mbh, forgedMB))
+	var forgedMBH = *mbh
+	forgedMBH.MagicBlock = forgedMB
+	assert.Error(t, h.VerifyNotarization(&forgedMBH, mb))
+
+	// forged signature
+	var forged = *h
+	forged.VerificationTickets = []*VerificationTicket{
+		h.VerificationTickets[0],
+		{VerifierID: ids[2], Signature: h.VerificationTickets[1].Signature},
+	}
+	assert.Error(t, forged.VerifyNotarization(mbh, mb))
+
+	// tampered header
+	var summary = *h.BlockSummary
+	summary.MerkleTreeRoot = encryption.Hash("other txns")
+	var tampered = *h
+	tampered.BlockSummary = &summary
+	assert.Equal(t, ErrBlockHashMismatch,
+		tampered.VerifyNotarization(mbh, mb))
+}
@@ -0,0 +1,74 @@
+package chain
+
+import (
+	"sync"
+
+	"0chain.net/chaincore/block"
+)
+
+// BlockSubscription - subscription to the blocks finalized by the chain. The
+// blocks are sent to the channel in order of their finalization. A slow
+// subscriber which channel is full is dropped and its channel is closed.
+type BlockSubscription struct {
+	C <-chan *block.Block
+
+	c    chan *block.Block
+	feed *blockFeed
+}
+
+// Unsubscribe - stop receiving finalized blocks, the channel is closed if
+// not closed yet.
+func (bs *BlockSubscription) Unsubscribe() {
+	bs.feed.remove(bs)
+}
+
+// blockFeed publishes finalized blocks to subscribers.
+type blockFeed struct {
+	mutex sync.Mutex
+	subs  map[*BlockSubscription]struct{}
+}
+
+func (bf *blockFeed) subscribe(size int) (bs *BlockSubscription) {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	if bf.subs == nil {
+		bf.subs = make(map[*BlockSubscription]struct{})
+	}
+	var c = make(chan *block.Block, size)
+	bs = &BlockSubscription{C: c, c: c, feed: bf}
+	bf.subs[bs] = struct{}{}
+	return
+}
+
+func (bf *blockFeed) remove(bs *BlockSubscription) {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	if _, ok := bf.subs[bs]; ok {
+		delete(bf.subs, bs)
+		close(bs.c)
+	}
+}
+
+// publish never blocks, the subscribers not keeping up are dropped
+func (bf *blockFeed) publish(fb *block.Block) {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	for bs := range bf.subs {
+		select {
+		case bs.c <- fb:
+		default:
+			delete(bf.subs, bs)
+			close(bs.c)
+		}
+	}
+}
+
+// SubscribeFinalizedBlocks - subscribe to the blocks finalized by the
+// FinalizedBlockWorker, the size is the capacity of the channel of the
+// subscription. The subscription should be unsubscribed when not used.
+func (c *Chain) SubscribeFinalizedBlocks(size int) *BlockSubscription {
+	return c.blockFeed.subscribe(size)
+}
@@ -0,0 +1,41 @@
+package chain
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_blockFeed(t *testing.T) {
+	var (
+		bf   blockFeed
+		fast = bf.subscribe(3)
+		slow = bf.subscribe(1)
+	)
+
+	for round := int64(1); round <= 3; round++ {
+		bf.publish(&block.Block{UnverifiedBlockBody: block.UnverifiedBlockBody{Round: round}})
+	}
+
+	for round := int64(1); round <= 3; round++ {
+		b, ok := <-fast.C
+		require.True(t, ok)
+		assert.Equal(t, round, b.Round)
+	}
+
+	// the slow subscriber is dropped on the second block
+	b, ok := <-slow.C
+	require.True(t, ok)
+	assert.Equal(t, int64(1), b.Round)
+	_, ok = <-slow.C
+	assert.False(t, ok)
+	slow.Unsubscribe() // no double close
+
+	fast.Unsubscribe()
+	_, ok = <-fast.C
+	assert.False(t, ok)
+	assert.Len(t, bf.subs, 0)
+	bf.publish(&block.Block{}) // no subscribers
+}
@@ -83,4 +83,10 @@ type Config struct {
 	RoundTimeoutSofttoMult int           `json:"softto_mult"`            // multiplier of mean network time for soft timeout
 	RoundRestartMult       int           `json:"round_restart_mult"`     // multiplier of soft timeouts to restart a round
 
+	StateSnapshotInterval  int64  `json:"state_snapshot_interval"`   // rounds between snapshots of the state, 0 to disable
+	StateSnapshotKeep      int    `json:"state_snapshot_keep"`       // number of the latest snapshots kept
+	StateSnapshotChunkSize int    `json:"state_snapshot_chunk_size"` // approximate size of a chunk of a snapshot in bytes
+	StateSnapshotDir       string `json:"state_snapshot_dir"`        // directory of the snapshots
+	StateArchive           bool   `json:"state_archive"`             // keep the state of all the finalized rounds for the historical queries
+
 }
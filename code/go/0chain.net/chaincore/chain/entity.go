@@ -108,7 +108,9 @@ type Chain struct {
 
 	CurrentRound int64 `json:"-"`
 
-	FeeStats transaction.TransactionFeeStats `json:"fee_stats"`
+	FeeStats   transaction.TransactionFeeStats `json:"fee_stats"`
+	feeHistory feeHistory
+	blockFeed  blockFeed
 
 	LatestFinalizedBlock *block.Block `json:"latest_finalized_block,omitempty"` // Latest block on the chain the program is aware of
 	lfbMutex             sync.RWMutex
@@ -148,6 +150,9 @@ type Chain struct {
 	magicBlockSaver              MagicBlockSaver
 
 	pruneStats *util.PruneStats
+	// round of the state snapshot being created, the state pruning
+	// doesn't remove its nodes; accessed atomically
+	stateSnapshotRound int64
 
 	configInfoDB string
 
@@ -316,6 +321,11 @@ func NewChainFromConfig() *Chain {
 	chain.RoundRange = viper.GetInt64("server_chain.round_range")
 	chain.TxnMaxPayload = viper.GetInt("server_chain.transaction.payload.max_size")
 	chain.PruneStateBelowCount = viper.GetInt("server_chain.state.prune_below_count")
+	chain.StateSnapshotInterval = viper.GetInt64("server_chain.state.snapshot.interval")
+	chain.StateSnapshotKeep = viper.GetInt("server_chain.state.snapshot.keep")
+	chain.StateSnapshotChunkSize = viper.GetInt("server_chain.state.snapshot.chunk_size")
+	chain.StateSnapshotDir = viper.GetString("server_chain.state.snapshot.dir")
+	chain.StateArchive = viper.GetBool("server_chain.state.archive")
 	verificationTicketsTo := viper.GetString("server_chain.messages.verification_tickets_to")
 	if verificationTicketsTo == "" || verificationTicketsTo == "all_miners" || verificationTicketsTo == "11" {
 		chain.VerificationTicketsTo = AllMiners
@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"sort"
+	"sync"
+
+	"0chain.net/chaincore/block"
+)
+
+// FeeStatsMaxBlocks - max number of the last finalized blocks the fee
+// percentiles are computed over.
+const FeeStatsMaxBlocks = 100
+
+// FeePercentiles - percentiles of fees per KB of the transactions of the last
+// finalized blocks, used by clients to estimate a competitive fee.
+type FeePercentiles struct {
+	Blocks int   `json:"blocks"`
+	Txns   int   `json:"txns"`
+	P10    int64 `json:"p10"`
+	P25    int64 `json:"p25"`
+	P50    int64 `json:"p50"`
+	P75    int64 `json:"p75"`
+	P90    int64 `json:"p90"`
+}
+
+// feeHistory keeps fees per KB of the transactions of the last finalized
+// blocks.
+type feeHistory struct {
+	mutex  sync.Mutex
+	blocks [][]int64 // the latest last
+}
+
+func (fh *feeHistory) add(fb *block.Block) {
+	var fees = make([]int64, 0, len(fb.Txns))
+	for _, txn := range fb.Txns {
+		if txn.Fee > 0 {
+			fees = append(fees, txn.GetFeePerKB())
+		}
+	}
+
+	fh.mutex.Lock()
+	defer fh.mutex.Unlock()
+
+	fh.blocks = append(fh.blocks, fees)
+	if len(fh.blocks) > FeeStatsMaxBlocks {
+		fh.blocks = fh.blocks[len(fh.blocks)-FeeStatsMaxBlocks:]
+	}
+}
+
+// percentiles over given number of the last blocks.
+func (fh *feeHistory) percentiles(n int) (fp *FeePercentiles) {
+	fh.mutex.Lock()
+	defer fh.mutex.Unlock()
+
+	if n <= 0 || n > len(fh.blocks) {
+		n = len(fh.blocks)
+	}
+
+	var fees []int64
+	for _, bf := range fh.blocks[len(fh.blocks)-n:] {
+		fees = append(fees, bf...)
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+
+	fp = &FeePercentiles{Blocks: n, Txns: len(fees)}
+	if len(fees) == 0 {
+		return
+	}
+	var pct = func(p int) int64 {
+		return fees[(len(fees)-1)*p/100]
+	}
+	fp.P10, fp.P25, fp.P50, fp.P75, fp.P90 = pct(10), pct(25), pct(50),
+		pct(75), pct(90)
+	return
+}
+
+// GetFeePercentiles - fee per KB percentiles over given number of the last
+// finalized blocks (all kept blocks for non-positive n).
+func (c *Chain) GetFeePercentiles(n int) *FeePercentiles {
+	return c.feeHistory.percentiles(n)
+}
@@ -0,0 +1,42 @@
+package chain
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func blockWithFees(fees ...int64) (b *block.Block) {
+	b = new(block.Block)
+	for _, fee := range fees {
+		b.Txns = append(b.Txns, &transaction.Transaction{Fee: fee})
+	}
+	return
+}
+
+func Test_feeHistory(t *testing.T) {
+	var fh feeHistory
+
+	fp := fh.percentiles(10)
+	assert.Equal(t, 0, fp.Blocks)
+	assert.Equal(t, 0, fp.Txns)
+
+	fh.add(blockWithFees(256, 0, 512))
+	for i := 0; i < FeeStatsMaxBlocks; i++ {
+		fh.add(blockWithFees(1024, 2048, 4096))
+	}
+	assert.Len(t, fh.blocks, FeeStatsMaxBlocks)
+
+	fp = fh.percentiles(1)
+	assert.Equal(t, 1, fp.Blocks)
+	assert.Equal(t, 3, fp.Txns)
+	// fee per KB of a transaction with empty data
+	assert.EqualValues(t, 1024*1024/256, fp.P10)
+	assert.EqualValues(t, 2048*1024/256, fp.P50)
+	assert.EqualValues(t, 2048*1024/256, fp.P75)
+
+	fp = fh.percentiles(0)
+	assert.Equal(t, FeeStatsMaxBlocks, fp.Blocks)
+}
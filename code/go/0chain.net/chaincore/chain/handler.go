@@ -51,6 +51,7 @@ func SetupHandlers() {
 
 	transactionEntityMetadata := datastore.GetEntityMetadata("txn")
 	http.HandleFunc("/v1/transaction/put", common.UserRateLimit(datastore.ToJSONEntityReqResponse(datastore.DoAsyncEntityJSONHandler(memorystore.WithConnectionEntityJSONHandler(PutTransaction, transactionEntityMetadata), transaction.TransactionEntityChannel), transactionEntityMetadata)))
+	http.HandleFunc("/v1/transaction/simulate", common.UserRateLimit(datastore.ToJSONEntityReqResponse(SimulateTransactionHandler, transactionEntityMetadata)))
 
 	http.HandleFunc("/_diagnostics/state_dump", common.UserRateLimit(StateDumpHandler))
 
@@ -62,8 +63,27 @@ func GetChainHandler(ctx context.Context, r *http.Request) (interface{}, error)
 	return datastore.GetEntityHandler(ctx, r, chainEntityMetadata, "id")
 }
 
+// FeeStats - fee statistics of the latest finalized blocks.
+type FeeStats struct {
+	transaction.TransactionFeeStats
+	Percentiles *FeePercentiles `json:"percentiles"`
+}
+
+// LatestBlockFeeStatsHandler - provide fee statistics with fee per KB
+// percentiles over the given number of the last finalized blocks.
 func LatestBlockFeeStatsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	return GetServerChain().FeeStats, nil
+	var n = FeeStatsMaxBlocks
+	if blocks := r.FormValue("blocks"); blocks != "" {
+		var err error
+		if n, err = strconv.Atoi(blocks); err != nil || n <= 0 {
+			return nil, common.InvalidRequest("invalid number of blocks")
+		}
+	}
+	c := GetServerChain()
+	return &FeeStats{
+		TransactionFeeStats: c.FeeStats,
+		Percentiles:         c.GetFeePercentiles(n),
+	}, nil
 }
 
 /*PutChainHandler - Given a chain data, it stores it */
@@ -1170,6 +1190,12 @@ func PutTransaction(ctx context.Context, entity datastore.Entity) (interface{},
 		}
 	}
 
+	if txn.TransactionType == transaction.TxnTypeSmartContractBatch {
+		if _, err := txn.GetBatchCalls(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Calculate and update fee
 	if err := txn.ValidateFee(); err != nil {
 		return nil, err
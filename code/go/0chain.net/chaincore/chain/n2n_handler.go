@@ -40,6 +40,12 @@ var (
 
 	// FBRequestor represents FB from sharders reqeustor.
 	FBRequestor node.EntityRequestor
+
+	// StateSnapshotRequestor - request the manifest of the latest state
+	// snapshot of a sharder.
+	StateSnapshotRequestor node.EntityRequestor
+	// StateSnapshotChunkRequestor - request a chunk of a state snapshot.
+	StateSnapshotChunkRequestor node.EntityRequestor
 )
 
 /*SetupX2MRequestors - setup requestors */
@@ -73,6 +79,13 @@ func SetupX2SRequestors() {
 	}
 	FBRequestor = node.RequestEntityHandler("/v1/_x2s/block/get", &opts,
 		datastore.GetEntityMetadata("block"))
+
+	StateSnapshotRequestor = node.RequestEntityHandler(
+		"/v1/_x2s/state/snapshot/get", &opts,
+		datastore.GetEntityMetadata("state_snapshot"))
+	StateSnapshotChunkRequestor = node.RequestEntityHandler(
+		"/v1/_x2s/state/snapshot/chunk/get", &opts,
+		datastore.GetEntityMetadata("state_snapshot_chunk"))
 }
 
 func SetupX2XResponders() {
@@ -261,6 +261,8 @@ func (c *Chain) finalizeBlock(ctx context.Context, fb *block.Block, bsh BlockSta
 	}
 	c.rebaseState(fb)
 	c.updateFeeStats(fb)
+	c.feeHistory.add(fb)
+	c.blockFeed.publish(fb)
 
 	if fb.MagicBlock != nil {
 		c.SetLatestFinalizedMagicBlock(fb)
@@ -0,0 +1,186 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+// touchedKeysMPT records the paths of the state nodes read, inserted and
+// deleted by a transaction.
+type touchedKeysMPT struct {
+	util.MerklePatriciaTrieI
+
+	mutex   sync.Mutex
+	read    map[string]struct{}
+	written map[string]struct{}
+}
+
+func newTouchedKeysMPT(mpt util.MerklePatriciaTrieI) *touchedKeysMPT {
+	return &touchedKeysMPT{
+		MerklePatriciaTrieI: mpt,
+		read:                make(map[string]struct{}),
+		written:             make(map[string]struct{}),
+	}
+}
+
+func (tk *touchedKeysMPT) touch(keys map[string]struct{}, path util.Path) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	keys[string(path)] = struct{}{}
+}
+
+// GetNodeValue - MerklePatriciaTrieI implementation.
+func (tk *touchedKeysMPT) GetNodeValue(path util.Path) (util.Serializable,
+	error) {
+
+	tk.touch(tk.read, path)
+	return tk.MerklePatriciaTrieI.GetNodeValue(path)
+}
+
+// Insert - MerklePatriciaTrieI implementation.
+func (tk *touchedKeysMPT) Insert(path util.Path, value util.Serializable) (
+	util.Key, error) {
+
+	tk.touch(tk.written, path)
+	return tk.MerklePatriciaTrieI.Insert(path, value)
+}
+
+// Delete - MerklePatriciaTrieI implementation.
+func (tk *touchedKeysMPT) Delete(path util.Path) (util.Key, error) {
+	tk.touch(tk.written, path)
+	return tk.MerklePatriciaTrieI.Delete(path)
+}
+
+// hexKeys returns sorted hex encoded paths.
+func hexKeys(paths map[string]struct{}) (list []string) {
+	list = make([]string, 0, len(paths))
+	for path := range paths {
+		list = append(list, util.ToHex([]byte(path)))
+	}
+	sort.Strings(list)
+	return
+}
+
+// SimulationResult - result of execution of a transaction against the latest
+// finalized state, nothing of which is committed.
+type SimulationResult struct {
+	Hash            datastore.Key           `json:"hash"`
+	Round           int64                   `json:"round"`
+	Output          string                  `json:"output"`
+	Transfers       []*state.Transfer       `json:"transfers"`
+	SignedTransfers []*state.SignedTransfer `json:"signed_transfers"`
+	Mints           []*state.Mint           `json:"mints"`
+	Events          []*transaction.Event    `json:"events"`
+	ReadKeys        []string                `json:"read_keys"`    // state keys read
+	WrittenKeys     []string                `json:"written_keys"` // state keys written
+	Error           string                  `json:"error,omitempty"`
+}
+
+// newSimulationBlock creates a block following the latest finalized one,
+// with its own copy of the state, in the context of which the simulated
+// transaction executes.
+func (c *Chain) newSimulationBlock(lfb *block.Block) (b *block.Block) {
+	b = block.NewBlock(c.GetKey(), lfb.Round+1)
+	b.PrevHash = lfb.Hash
+	b.PrevBlock = lfb
+	b.CreationDate = common.Now()
+	b.MinerID = node.Self.Underlying().GetKey()
+	b.MagicBlock = lfb.MagicBlock
+	b.ClientState = CreateTxnMPT(lfb.ClientState)
+	return
+}
+
+// simulationBlock creates the simulation block over a snapshot of the state
+// of the latest finalized block. The state lock is held while the snapshot is
+// taken only: the nodes of the finalized state are never changed, and its
+// node db is synchronized on its own, thus the snapshot is safe to read while
+// the state is rebased or new blocks are computed.
+func (c *Chain) simulationBlock() (*block.Block, error) {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+
+	var lfb = c.GetLatestFinalizedBlock()
+	if lfb == nil || lfb.ClientState == nil {
+		return nil, common.NewError("empty_lfb",
+			"empty latest finalized block or state")
+	}
+	return c.newSimulationBlock(lfb), nil
+}
+
+// SimulateTransaction - execute the transaction against a copy of the state
+// of the latest finalized block without committing anything. Execution
+// errors are reported in the result. The state lock is not held during the
+// execution.
+func (c *Chain) SimulateTransaction(txn *transaction.Transaction) (
+	sr *SimulationResult, err error) {
+
+	var b *block.Block
+	if b, err = c.simulationBlock(); err != nil {
+		return
+	}
+
+	var (
+		clientState = newTouchedKeysMPT(b.ClientState)
+		sctx        = c.newStateContext(b, clientState, txn)
+	)
+
+	sr = &SimulationResult{Hash: txn.Hash, Round: b.Round}
+	if execErr := c.executeTxn(sctx, txn); execErr != nil {
+		sr.Error = execErr.Error()
+	}
+
+	sr.Output = txn.TransactionOutput
+	sr.Transfers = sctx.GetTransfers()
+	sr.SignedTransfers = sctx.GetSignedTransfers()
+	sr.Mints = sctx.GetMints()
+	sr.Events = sctx.GetEvents()
+
+	clientState.mutex.Lock()
+	defer clientState.mutex.Unlock()
+
+	sr.ReadKeys = hexKeys(clientState.read)
+	sr.WrittenKeys = hexKeys(clientState.written)
+	return
+}
+
+// SimulateTransactionHandler - execute the given transaction against the
+// latest finalized state (dry-run). The transaction signature is not
+// verified and its hash is computed when omitted.
+func SimulateTransactionHandler(ctx context.Context, entity datastore.Entity) (
+	interface{}, error) {
+
+	txn, ok := entity.(*transaction.Transaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid request %T", entity)
+	}
+	var c = GetServerChain()
+	if c.TxnMaxPayload > 0 && len(txn.TransactionData) > c.TxnMaxPayload {
+		return nil, common.NewError("txn_exceed_max_payload",
+			fmt.Sprintf("transaction payload exceeds the max payload (%d)",
+				c.TxnMaxPayload))
+	}
+	txn.ComputeProperties()
+	if txn.ClientID == "" {
+		return nil, common.InvalidRequest("missing client_id or public_key")
+	}
+	if txn.CreationDate == 0 {
+		txn.CreationDate = common.Now()
+	}
+	if txn.Hash == "" {
+		txn.Hash = txn.ComputeHash()
+	}
+	if err := txn.ValidateFee(); err != nil {
+		return nil, err
+	}
+	return c.SimulateTransaction(txn)
+}
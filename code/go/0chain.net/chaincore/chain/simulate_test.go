@@ -0,0 +1,167 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	bcstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/smartcontract"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/memorystore"
+	"0chain.net/core/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_touchedKeysMPT(t *testing.T) {
+	logging.Logger = zap.NewNop()
+
+	var (
+		mpt = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 0)
+		tk  = newTouchedKeysMPT(CreateTxnMPT(mpt))
+		st  = &state.State{Balance: 10}
+	)
+
+	_, err := tk.Insert(util.Path("aa"), st)
+	require.NoError(t, err)
+	_, err = tk.GetNodeValue(util.Path("ab"))
+	assert.Equal(t, util.ErrValueNotPresent, err)
+	_, err = tk.GetNodeValue(util.Path("aa"))
+	require.NoError(t, err)
+	_, err = tk.Delete(util.Path("aa"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"6161", "6162"}, hexKeys(tk.read))
+	assert.Equal(t, []string{"6161"}, hexKeys(tk.written))
+	// the underlying state is not changed
+	assert.Equal(t, mpt.GetRoot(), util.Key(nil))
+}
+
+const simulateTestSCAddress = "2bc9e6e5b9e1c6d8d7a5f6e0b5d3c4a1f2e3d4c5b6a79881726354453627180a"
+
+// simulateTestSC locks value of the transaction, it fails if the state lock
+// of the chain is held while it executes.
+type simulateTestSC struct {
+	c *Chain
+}
+
+func (sc *simulateTestSC) Execute(t *transaction.Transaction, funcName string,
+	input []byte, balances bcstate.StateContextI) (string, error) {
+
+	if !sc.c.stateMutex.TryLock() {
+		return "", errors.New("state is locked")
+	}
+	sc.c.stateMutex.Unlock()
+	var transfer = state.NewTransfer(t.ClientID, t.ToClientID,
+		state.Balance(t.Value))
+	if err := balances.AddTransfer(transfer); err != nil {
+		return "", err
+	}
+	return "locked", nil
+}
+
+func (*simulateTestSC) SetSC(*sci.SmartContract, sci.BCContextI) {}
+func (*simulateTestSC) GetRestPoints() map[string]sci.SmartContractRestHandler {
+	return nil
+}
+func (*simulateTestSC) GetName() string    { return "simulate_test" }
+func (*simulateTestSC) GetAddress() string { return simulateTestSCAddress }
+func (*simulateTestSC) InitSC()            {}
+
+func TestChain_SimulateTransaction(t *testing.T) {
+	logging.Logger = zap.NewNop()
+	block.SetupEntity(memorystore.GetStorageProvider())
+
+	var (
+		c = &Chain{
+			Config:                  &Config{},
+			clientStateDeserializer: &state.Deserializer{},
+			stateDB:                 util.NewMemoryNodeDB(),
+			stateMutex:              &sync.RWMutex{},
+		}
+		lfb      = block.Provider().(*block.Block)
+		clientID = encryption.Hash("client")
+		toID     = encryption.Hash("to")
+	)
+
+	smartcontract.ContractMap[simulateTestSCAddress] = &simulateTestSC{c: c}
+	defer delete(smartcontract.ContractMap, simulateTestSCAddress)
+
+	// no latest finalized block
+	_, err := c.SimulateTransaction(&transaction.Transaction{})
+	require.Error(t, err)
+
+	lfb.Round = 10
+	lfb.ClientState = util.NewMerklePatriciaTrie(c.stateDB, 0)
+	var cs = &state.State{Balance: 100}
+	require.NoError(t, cs.SetTxnHash(encryption.Hash("genesis")))
+	_, err = lfb.ClientState.Insert(util.Path(clientID), cs)
+	require.NoError(t, err)
+	c.LatestFinalizedBlock = lfb
+
+	var (
+		root      = lfb.ClientState.GetRoot()
+		nodes     = c.stateDB.(*util.MemoryNodeDB).Size(context.Background())
+		balanceOf = func(id string) state.Balance {
+			s, err := c.getState(lfb.ClientState, id)
+			require.True(t, isValid(err))
+			return s.Balance
+		}
+		newTxn = func(typ int, to string, value int64) *transaction.Transaction {
+			var txn = &transaction.Transaction{
+				ClientID:        clientID,
+				ToClientID:      to,
+				Value:           value,
+				Nonce:           1,
+				TransactionType: typ,
+				TransactionData: `{"name":"lock"}`,
+			}
+			txn.Hash = txn.ComputeHash()
+			return txn
+		}
+		unchanged = func() {
+			assert.Equal(t, root, lfb.ClientState.GetRoot())
+			assert.Equal(t, nodes, c.stateDB.(*util.MemoryNodeDB).Size(context.Background()))
+			assert.EqualValues(t, 100, balanceOf(clientID))
+			assert.Zero(t, balanceOf(toID))
+			assert.Zero(t, balanceOf(simulateTestSCAddress))
+		}
+	)
+
+	// send
+	sr, err := c.SimulateTransaction(newTxn(transaction.TxnTypeSend, toID, 10))
+	require.NoError(t, err)
+	assert.Empty(t, sr.Error)
+	assert.EqualValues(t, 11, sr.Round)
+	require.Len(t, sr.Transfers, 1)
+	assert.EqualValues(t, 10, sr.Transfers[0].Amount)
+	assert.Contains(t, sr.WrittenKeys, util.ToHex([]byte(clientID)))
+	assert.Contains(t, sr.WrittenKeys, util.ToHex([]byte(toID)))
+	unchanged()
+
+	// smart contract, executed without the state lock held
+	sr, err = c.SimulateTransaction(newTxn(transaction.TxnTypeSmartContract,
+		simulateTestSCAddress, 20))
+	require.NoError(t, err)
+	assert.Empty(t, sr.Error)
+	assert.Equal(t, "locked", sr.Output)
+	require.Len(t, sr.Transfers, 1)
+	assert.EqualValues(t, 20, sr.Transfers[0].Amount)
+	assert.Contains(t, sr.WrittenKeys,
+		util.ToHex([]byte(simulateTestSCAddress)))
+	unchanged()
+
+	// failed execution is reported in the result
+	sr, err = c.SimulateTransaction(newTxn(transaction.TxnTypeSend, toID, 1000))
+	require.NoError(t, err)
+	assert.NotEmpty(t, sr.Error)
+	unchanged()
+}
@@ -3,6 +3,7 @@ package chain
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"time"
@@ -51,6 +52,8 @@ var ErrStateMismatch = common.NewError(StateMismatch, "Computed state hash doesn
 
 var ErrInsufficientBalance = common.NewError("insufficient_balance", "Balance not sufficient for transfer")
 
+var ErrInvalidNonce = common.NewError("invalid_nonce", "Transaction nonce doesn't match the next expected nonce of the client")
+
 /*ComputeState - compute the state for the block */
 func (c *Chain) ComputeState(ctx context.Context, b *block.Block) error {
 	lock := b.StateMutex
@@ -310,6 +313,9 @@ func (c *Chain) SaveChanges(ctx context.Context, b *block.Block) error {
 		if ok {
 			c.stateDB.(*util.PNodeDB).TrackDBVersion(lndb.GetDBVersion())
 		}
+		if err == nil && c.StateArchive {
+			c.archiveStateRoot(b)
+		}
 	default:
 		return common.NewError("state_save_without_success", "State can't be saved without successful computation")
 	}
@@ -417,6 +423,51 @@ func (c *Chain) updateState(b *block.Block, txn *transaction.Transaction) (
 		sctx        = c.newStateContext(b, clientState, txn)
 	)
 
+	if err = c.executeTxn(sctx, txn); err != nil {
+		return
+	}
+
+	// commit transaction
+	if err = b.ClientState.MergeMPTChanges(clientState); err != nil {
+		if state.DebugTxn() {
+			Logger.DPanic("update state - merge mpt error",
+				zap.Int64("round", b.Round), zap.String("block", b.Hash),
+				zap.Any("txn", txn), zap.Error(err))
+		}
+
+		Logger.Error("error committing txn", zap.Any("error", err))
+		return
+	}
+
+	if state.DebugTxn() {
+		if err = c.validateState(context.TODO(), b, startRoot); err != nil {
+			Logger.DPanic("update state - state validation failure",
+				zap.Any("txn", txn), zap.Error(err))
+		}
+		var os *state.State
+		os, err = c.getState(b.ClientState, c.OwnerID)
+		if err != nil || os == nil || os.Balance == 0 {
+			Logger.DPanic("update state - owner account",
+				zap.Int64("round", b.Round), zap.String("block", b.Hash),
+				zap.Any("txn", txn), zap.Any("os", os), zap.Error(err))
+		}
+	}
+
+	txn.Status = transaction.TxnSuccess
+	return
+}
+
+// executeTxn executes the transaction and applies its transfers and mints
+// to the state of the given context without committing it to the block. The
+// events emitted are recorded with the transaction on success only.
+func (c *Chain) executeTxn(sctx *bcstate.StateContext,
+	txn *transaction.Transaction) (err error) {
+
+	txn.Events = nil
+	if err = c.validateNonce(sctx, txn); err != nil {
+		return
+	}
+
 	switch txn.TransactionType {
 
 	case transaction.TxnTypeSmartContract:
@@ -431,6 +482,13 @@ func (c *Chain) updateState(b *block.Block, txn *transaction.Transaction) (
 			zap.Any("txn_output", txn.TransactionOutput),
 			zap.Any("txn_hash", txn.Hash))
 
+	case transaction.TxnTypeSmartContractBatch:
+		if err = c.executeSmartContractBatch(sctx, txn); err != nil {
+			Logger.Error("Error executing the SC batch", zap.Any("txn", txn),
+				zap.Error(err))
+			return
+		}
+
 	case transaction.TxnTypeData:
 
 	case transaction.TxnTypeSend:
@@ -452,6 +510,22 @@ func (c *Chain) updateState(b *block.Block, txn *transaction.Transaction) (
 		}
 	}
 
+	if err = c.applyTransfers(sctx, txn); err != nil {
+		return
+	}
+
+	if err = c.updateNonce(sctx, txn); err != nil {
+		return
+	}
+	txn.Events = sctx.GetEvents()
+	return
+}
+
+// applyTransfers validates the transfers and mints of the state context and
+// applies them to its state.
+func (c *Chain) applyTransfers(sctx *bcstate.StateContext,
+	txn *transaction.Transaction) (err error) {
+
 	if err = sctx.Validate(); err != nil {
 		return
 	}
@@ -481,33 +555,48 @@ func (c *Chain) updateState(b *block.Block, txn *transaction.Transaction) (
 		}
 	}
 
-	// commit transaction
-	if err = b.ClientState.MergeMPTChanges(clientState); err != nil {
-		if state.DebugTxn() {
-			Logger.DPanic("update state - merge mpt error",
-				zap.Int64("round", b.Round), zap.String("block", b.Hash),
-				zap.Any("txn", txn), zap.Error(err))
-		}
+	return
+}
 
-		Logger.Error("error committing txn", zap.Any("error", err))
+// executeSmartContractBatch executes the calls of the batch transaction one
+// by one. Every call executes in its own state context over the state of the
+// transaction and its transfers and mints are applied before the next call,
+// so any failed call fails the whole transaction and nothing of the batch is
+// committed. The output is JSON array of the outputs of the calls.
+func (c *Chain) executeSmartContractBatch(sctx *bcstate.StateContext,
+	txn *transaction.Transaction) (err error) {
+
+	var calls []*transaction.SmartContractBatchCall
+	if calls, err = txn.GetBatchCalls(); err != nil {
 		return
 	}
 
-	if state.DebugTxn() {
-		if err = c.validateState(context.TODO(), b, startRoot); err != nil {
-			Logger.DPanic("update state - state validation failure",
-				zap.Any("txn", txn), zap.Error(err))
+	var outputs = make([]string, 0, len(calls))
+	for i, call := range calls {
+		var (
+			ctxn   = txn.BatchCallTxn(i, call)
+			cctx   = c.newStateContext(sctx.GetBlock(), sctx.GetState(), ctxn)
+			output string
+		)
+		if output, err = c.ExecuteSmartContract(ctxn, cctx); err != nil {
+			return common.NewError("batch_call_failed",
+				fmt.Sprintf("call %d (%s): %v", i, call.FunctionName, err))
 		}
-		var os *state.State
-		os, err = c.getState(b.ClientState, c.OwnerID)
-		if err != nil || os == nil || os.Balance == 0 {
-			Logger.DPanic("update state - owner account",
-				zap.Int64("round", b.Round), zap.String("block", b.Hash),
-				zap.Any("txn", txn), zap.Any("os", os), zap.Error(err))
+		if err = c.applyTransfers(cctx, ctxn); err != nil {
+			return common.NewError("batch_call_failed",
+				fmt.Sprintf("call %d (%s): %v", i, call.FunctionName, err))
+		}
+		outputs = append(outputs, output)
+		for _, event := range cctx.GetEvents() {
+			sctx.EmitEvent(event.Type, event.Attributes)
 		}
 	}
 
-	txn.Status = transaction.TxnSuccess
+	var data []byte
+	if data, err = json.Marshal(outputs); err != nil {
+		return
+	}
+	txn.TransactionOutput = string(data)
 	return
 }
 
@@ -563,7 +652,8 @@ func (c *Chain) transferAmount(sctx bcstate.StateContextI, fromClient, toClient
 	}
 	sctx.SetStateContext(fs)
 	fs.Balance -= amount
-	if fs.Balance == 0 {
+	// a client with a used nonce is kept in the state to prevent replays
+	if fs.Balance == 0 && fs.Nonce == 0 {
 		Logger.Info("transfer amount - remove client", zap.Int64("round", b.Round), zap.String("block", b.Hash), zap.String("client", fromClient), zap.Any("txn", txn))
 		_, err = clientState.Delete(util.Path(fromClient))
 	} else {
@@ -649,6 +739,47 @@ func (c *Chain) mintAmount(sctx bcstate.StateContextI, toClient datastore.Key, a
 	return nil
 }
 
+// validateNonce checks out the nonce of a nonce based transaction against the
+// nonce of the client w.r.t. the given state. Transactions without a nonce
+// are deduplicated by hash (see ChainHasTransaction).
+func (c *Chain) validateNonce(sctx bcstate.StateContextI,
+	txn *transaction.Transaction) error {
+
+	if txn.Nonce == 0 {
+		return nil
+	}
+	s, err := c.getState(sctx.GetState(), txn.ClientID)
+	if !isValid(err) {
+		return err
+	}
+	if txn.Nonce != s.Nonce+1 {
+		Logger.Debug("update state - invalid nonce",
+			zap.String("txn", txn.Hash), zap.String("client", txn.ClientID),
+			zap.Int64("nonce", txn.Nonce), zap.Int64("expected", s.Nonce+1))
+		return ErrInvalidNonce
+	}
+	return nil
+}
+
+// updateNonce stores the nonce of the applied transaction as the last nonce
+// of its client.
+func (c *Chain) updateNonce(sctx bcstate.StateContextI,
+	txn *transaction.Transaction) error {
+
+	if txn.Nonce == 0 {
+		return nil
+	}
+	clientState := sctx.GetState()
+	s, err := c.getState(clientState, txn.ClientID)
+	if !isValid(err) {
+		return err
+	}
+	sctx.SetStateContext(s)
+	s.Nonce = txn.Nonce
+	_, err = clientState.Insert(util.Path(txn.ClientID), s)
+	return err
+}
+
 func CreateTxnMPT(mpt util.MerklePatriciaTrieI) util.MerklePatriciaTrieI {
 	tdb := util.NewLevelNodeDB(util.NewMemoryNodeDB(), mpt.GetNodeDB(), false)
 	tmpt := util.NewMerklePatriciaTrie(tdb, mpt.GetVersion())
@@ -694,6 +825,18 @@ func (c *Chain) GetState(b *block.Block, clientID string) (*state.State, error)
 	return st, nil
 }
 
+// GetNextNonce returns the nonce expected in the next transaction of the
+// client w.r.t. the given block's state.
+func (c *Chain) GetNextNonce(b *block.Block, clientID string) (int64, error) {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+	s, err := c.getState(b.ClientState, clientID)
+	if !isValid(err) {
+		return 0, err
+	}
+	return s.Nonce + 1, nil
+}
+
 func isValid(err error) bool {
 	if err == nil {
 		return true
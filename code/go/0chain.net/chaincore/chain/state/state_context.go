@@ -23,6 +23,7 @@ var (
 * The smart contract logic can use
 *    GetClientBalance - to get the balance of a client at the beginning of executing the transaction.
 *    AddTransfer - to add transfer of tokens from one client to another.
+*    NewCallContext - to call another smart contract on behalf of a client.
 *  Restrictions:
 *    1) The total transfer out from the txn.ClientID should be <= txn.Value
 *    2) The only from clients valid are txn.ClientID and txn.ToClientID (which will be the smart contract's client id)
@@ -46,9 +47,12 @@ type StateContextI interface {
 	GetTransfers() []*state.Transfer
 	GetSignedTransfers() []*state.SignedTransfer
 	GetMints() []*state.Mint
+	EmitEvent(eventType string, attributes map[string]string)
+	GetEvents() []*transaction.Event
 	Validate() error
 	GetBlockSharders(b *block.Block) []string
 	GetSignatureScheme() encryption.SignatureScheme
+	NewCallContext(t *transaction.Transaction) StateContextI
 }
 
 //StateContext - a context object used to manipulate global state
@@ -59,6 +63,8 @@ type StateContext struct {
 	transfers                     []*state.Transfer
 	signedTransfers               []*state.SignedTransfer
 	mints                         []*state.Mint
+	events                        []*transaction.Event
+	calls                         []*StateContext
 	clientStateDeserializer       state.DeserializerI
 	getSharders                   func(*block.Block) []string
 	getLastestFinalizedMagicBlock func() *block.Block
@@ -139,19 +145,59 @@ func (sc *StateContext) isApprovedMinter(m *state.Mint) bool {
 	return false
 }
 
+//NewCallContext - create state context of a smart contract call made by a
+//smart contract on behalf of the client of the given transaction. The calling
+//smart contract is responsible for authorizing the call. Transfers, mints and
+//events of the call are added to this context, and the transfers are limited
+//by the value of the given transaction.
+func (sc *StateContext) NewCallContext(t *transaction.Transaction) StateContextI {
+	cc := NewStateContext(sc.block, sc.state, sc.clientStateDeserializer, t,
+		sc.getSharders, sc.getLastestFinalizedMagicBlock, sc.getSignature)
+	sc.calls = append(sc.calls, cc)
+	return cc
+}
+
 //GetTransfers - get all the transfers
 func (sc *StateContext) GetTransfers() []*state.Transfer {
-	return sc.transfers
+	transfers := sc.transfers[:len(sc.transfers):len(sc.transfers)] // copy on append
+	for _, cc := range sc.calls {
+		transfers = append(transfers, cc.GetTransfers()...)
+	}
+	return transfers
 }
 
 //GetTransfers - get all the transfers
 func (sc *StateContext) GetSignedTransfers() []*state.SignedTransfer {
-	return sc.signedTransfers
+	signedTransfers := sc.signedTransfers[:len(sc.signedTransfers):len(sc.signedTransfers)] // copy on append
+	for _, cc := range sc.calls {
+		signedTransfers = append(signedTransfers, cc.GetSignedTransfers()...)
+	}
+	return signedTransfers
 }
 
 //GetMints - get all the mints and fight bad breath
 func (sc *StateContext) GetMints() []*state.Mint {
-	return sc.mints
+	mints := sc.mints[:len(sc.mints):len(sc.mints)] // copy on append
+	for _, cc := range sc.calls {
+		mints = append(mints, cc.GetMints()...)
+	}
+	return mints
+}
+
+//EmitEvent - emit a structured event recorded with the transaction output
+func (sc *StateContext) EmitEvent(eventType string,
+	attributes map[string]string) {
+
+	sc.events = append(sc.events, transaction.NewEvent(eventType, attributes))
+}
+
+//GetEvents - get all the events emitted
+func (sc *StateContext) GetEvents() []*transaction.Event {
+	events := sc.events[:len(sc.events):len(sc.events)] // copy on append
+	for _, cc := range sc.calls {
+		events = append(events, cc.GetEvents()...)
+	}
+	return events
 }
 
 //Validate - implement interface
@@ -187,6 +233,12 @@ func (sc *StateContext) Validate() error {
 		}
 	}
 
+	for _, cc := range sc.calls {
+		if err := cc.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
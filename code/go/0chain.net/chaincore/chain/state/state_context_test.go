@@ -0,0 +1,105 @@
+package state
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMultisigAddress = "27b5ef7120252b79f9dd9c05505dd28f328c80f6863ee446daede08a84d651a7"
+	testStorageAddress  = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d7"
+)
+
+func newTestCallContexts(value int64) (sc *StateContext, cc StateContextI) {
+	sc = NewStateContext(nil, nil, nil, &transaction.Transaction{
+		ClientID:   "signer",
+		ToClientID: testMultisigAddress,
+	}, nil, nil, nil)
+	cc = sc.NewCallContext(&transaction.Transaction{
+		ClientID:   "wallet",
+		ToClientID: testStorageAddress,
+		Value:      value,
+	})
+	return
+}
+
+func TestStateContext_NewCallContext_Validate(t *testing.T) {
+	var sc, cc = newTestCallContexts(10)
+
+	// only the client of the call and the called smart contract transfer
+	assert.Equal(t, state.ErrInvalidTransfer,
+		cc.AddTransfer(state.NewTransfer("signer", "to", 1)))
+	assert.Equal(t, state.ErrInvalidTransfer,
+		sc.AddTransfer(state.NewTransfer("wallet", "to", 1)))
+
+	// transfers of the wallet are limited by the value of the call
+	require.NoError(t, cc.AddTransfer(state.NewTransfer("wallet",
+		testStorageAddress, 7)))
+	require.NoError(t, cc.AddTransfer(state.NewTransfer(testStorageAddress,
+		"wallet", 20)))
+	require.NoError(t, cc.AddTransfer(state.NewTransfer("wallet", "to", 3)))
+	require.NoError(t, cc.Validate())
+	require.NoError(t, sc.Validate())
+
+	require.NoError(t, cc.AddTransfer(state.NewTransfer("wallet", "to", 1)))
+	assert.Equal(t, state.ErrInvalidTransfer, cc.Validate())
+	assert.Equal(t, state.ErrInvalidTransfer, sc.Validate(),
+		"calls are validated by the caller")
+
+	// a call of a call
+	sc, cc = newTestCallContexts(0)
+	var ccc = cc.NewCallContext(&transaction.Transaction{
+		ClientID:   "wallet",
+		ToClientID: testStorageAddress,
+		Value:      5,
+	})
+	require.NoError(t, ccc.AddTransfer(state.NewTransfer("wallet",
+		testStorageAddress, 5)))
+	require.NoError(t, sc.Validate())
+	require.NoError(t, ccc.AddTransfer(state.NewTransfer("wallet",
+		testStorageAddress, 1)))
+	assert.Equal(t, state.ErrInvalidTransfer, sc.Validate())
+}
+
+func TestStateContext_NewCallContext_merge(t *testing.T) {
+	var sc, cc = newTestCallContexts(10)
+
+	var (
+		parentTransfer = state.NewTransfer(testMultisigAddress, "signer", 1)
+		callTransfer   = state.NewTransfer("wallet", testStorageAddress, 2)
+		laterTransfer  = state.NewTransfer(testMultisigAddress, "signer", 3)
+		callMint       = state.NewMint(testStorageAddress, "wallet", 4)
+	)
+
+	require.NoError(t, sc.AddTransfer(parentTransfer))
+	sc.EmitEvent("parent", map[string]string{"n": "1"})
+	require.NoError(t, cc.AddTransfer(callTransfer))
+	require.NoError(t, cc.AddMint(callMint))
+	cc.EmitEvent("call", map[string]string{"n": "2"})
+	require.NoError(t, sc.AddTransfer(laterTransfer))
+
+	// the multi-sig smart contract is not a minter, the called one is
+	assert.Equal(t, state.ErrInvalidMint,
+		sc.AddMint(state.NewMint(testMultisigAddress, "signer", 1)))
+
+	// the calls come after the caller's own ones
+	assert.Equal(t, []*state.Transfer{parentTransfer, laterTransfer,
+		callTransfer}, sc.GetTransfers())
+	assert.Equal(t, []*state.Transfer{callTransfer}, cc.GetTransfers())
+	assert.Equal(t, []*state.Mint{callMint}, sc.GetMints())
+
+	var events = sc.GetEvents()
+	require.Len(t, events, 2)
+	assert.Equal(t, "parent", events[0].Type)
+	assert.Equal(t, "call", events[1].Type)
+	assert.Equal(t, "2", events[1].Attributes["n"])
+
+	// merging doesn't change the transfers of the caller
+	require.NoError(t, sc.AddTransfer(parentTransfer))
+	assert.Len(t, sc.GetTransfers(), 4)
+	assert.Len(t, cc.GetTransfers(), 1)
+}
@@ -0,0 +1,91 @@
+package chain
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	. "0chain.net/core/logging"
+	"0chain.net/core/util"
+	"go.uber.org/zap"
+)
+
+// ErrStateNotArchived - the state of the round is not kept by the node
+var ErrStateNotArchived = common.NewError("state_not_archived",
+	"historical state is not archived by this node")
+
+// archiveStateRoot records the root of the state of the finalized block, so
+// the state of the round can be queried later in the archive mode.
+func (c *Chain) archiveStateRoot(b *block.Block) {
+	pndb, ok := c.stateDB.(*util.PNodeDB)
+	if !ok {
+		return
+	}
+	err := pndb.PutVersionRoot(util.Sequence(b.Round), b.ClientStateHash)
+	if err != nil {
+		Logger.Error("archive state root", zap.Int64("round", b.Round),
+			zap.String("block", b.Hash), zap.Error(err))
+	}
+}
+
+// getRoundParam returns the optional round parameter, zero if missing
+func getRoundParam(r *http.Request) (int64, error) {
+	var value = r.FormValue("round")
+	if value == "" {
+		return 0, nil
+	}
+	var round, err = strconv.ParseInt(value, 10, 64)
+	if err != nil || round < 0 {
+		return 0, common.InvalidRequest("invalid round parameter")
+	}
+	return round, nil
+}
+
+// GetStateAt - get a block with the client state of the given finalized
+// round, the latest finalized one for zero. The state of a round before the
+// latest finalized one is available in the archive mode only, the block has
+// no other fields but the round and the state hash then.
+func (c *Chain) GetStateAt(round int64) (*block.Block, error) {
+	lfb := c.GetLatestFinalizedBlock()
+	if lfb == nil || lfb.ClientState == nil {
+		return nil, common.NewError("empty_lfb",
+			"empty latest finalized block or state")
+	}
+	if round == 0 || round == lfb.Round {
+		return lfb, nil
+	}
+	if round > lfb.Round {
+		return nil, common.InvalidRequest(fmt.Sprintf(
+			"round %d is not finalized yet", round))
+	}
+	if !c.StateArchive {
+		return nil, ErrStateNotArchived
+	}
+	pndb, ok := c.stateDB.(*util.PNodeDB)
+	if !ok {
+		return nil, ErrStateNotArchived
+	}
+	root, err := pndb.GetVersionRoot(util.Sequence(round))
+	if err != nil {
+		return nil, common.NewError("state_not_archived",
+			fmt.Sprintf("no state of round %d", round))
+	}
+	b := block.NewBlock(c.GetKey(), round)
+	b.ClientStateHash = root
+	b.ClientState = util.NewMerklePatriciaTrie(c.stateDB, util.Sequence(round))
+	b.ClientState.SetRoot(root)
+	b.SetStateStatus(block.StateSuccessful)
+	return b, nil
+}
+
+// getStateAtParam returns a block with the state of the round of the round
+// parameter of the request
+func (c *Chain) getStateAtParam(r *http.Request) (*block.Block, error) {
+	round, err := getRoundParam(r)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetStateAt(round)
+}
@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"net/http"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain_GetStateAt(t *testing.T) {
+	var (
+		c   = &Chain{Config: &Config{}, stateDB: util.NewMemoryNodeDB()}
+		lfb = block.Provider().(*block.Block)
+	)
+	_, err := c.GetStateAt(0)
+	require.Error(t, err)
+
+	lfb.Round = 10
+	lfb.ClientState = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 10)
+	c.LatestFinalizedBlock = lfb
+
+	b, err := c.GetStateAt(0)
+	require.NoError(t, err)
+	assert.True(t, b == lfb)
+	b, err = c.GetStateAt(10)
+	require.NoError(t, err)
+	assert.True(t, b == lfb)
+
+	_, err = c.GetStateAt(11)
+	assert.Error(t, err)
+	_, err = c.GetStateAt(5)
+	assert.Equal(t, ErrStateNotArchived, err)
+
+	// the roots are kept by the persistent node db only
+	c.StateArchive = true
+	_, err = c.GetStateAt(5)
+	assert.Equal(t, ErrStateNotArchived, err)
+}
+
+func Test_getRoundParam(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		round int64
+		err   bool
+	}{
+		{"", 0, false},
+		{"round=25", 25, false},
+		{"round=-1", 0, true},
+		{"round=x", 0, true},
+	} {
+		r, err := http.NewRequest(http.MethodGet, "/v1/client/get/balance?"+tc.query, nil)
+		require.NoError(t, err)
+		round, err := getRoundParam(r)
+		if tc.err {
+			assert.Error(t, err, tc.query)
+			continue
+		}
+		require.NoError(t, err, tc.query)
+		assert.Equal(t, tc.round, round)
+	}
+}
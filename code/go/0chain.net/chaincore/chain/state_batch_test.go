@@ -0,0 +1,124 @@
+package chain
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	bcstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/smartcontract"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const batchTestSCAddress = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712e0"
+
+// batchTestSC locks value of the transaction, emitting an event, or fails.
+type batchTestSC struct{}
+
+func (*batchTestSC) Execute(t *transaction.Transaction, funcName string,
+	input []byte, balances bcstate.StateContextI) (string, error) {
+
+	switch funcName {
+	case "lock":
+		var transfer = state.NewTransfer(t.ClientID, t.ToClientID,
+			state.Balance(t.Value))
+		if err := balances.AddTransfer(transfer); err != nil {
+			return "", err
+		}
+		balances.EmitEvent("locked", map[string]string{
+			"value": strconv.FormatInt(t.Value, 10),
+		})
+		return "locked", nil
+	case "check":
+		// sees the transfers of the previous calls
+		var balance, err = balances.GetClientBalance(t.ToClientID)
+		if err != nil {
+			return "", err
+		}
+		if balance == 0 {
+			return "", errors.New("nothing locked")
+		}
+		return "checked", nil
+	}
+	return "", errors.New("fail")
+}
+
+func (*batchTestSC) SetSC(*sci.SmartContract, sci.BCContextI) {}
+func (*batchTestSC) GetRestPoints() map[string]sci.SmartContractRestHandler {
+	return nil
+}
+func (*batchTestSC) GetName() string    { return "batch_test" }
+func (*batchTestSC) GetAddress() string { return batchTestSCAddress }
+func (*batchTestSC) InitSC()            {}
+
+func TestChain_executeSmartContractBatch(t *testing.T) {
+	logging.Logger = zap.NewNop()
+
+	smartcontract.ContractMap[batchTestSCAddress] = &batchTestSC{}
+	defer delete(smartcontract.ContractMap, batchTestSCAddress)
+
+	const clientID = "client_id"
+
+	var (
+		c = &Chain{
+			Config:                  &Config{},
+			clientStateDeserializer: &state.Deserializer{},
+		}
+		b = block.Provider().(*block.Block)
+	)
+	b.ClientState = util.NewMerklePatriciaTrie(util.NewLevelNodeDB(
+		util.NewMemoryNodeDB(), util.NewMemoryNodeDB(), false), 0)
+	var cs = &state.State{Balance: 100}
+	require.NoError(t, cs.SetTxnHash(batchTestSCAddress))
+	_, err := b.ClientState.Insert(util.Path(clientID), cs)
+	require.NoError(t, err)
+
+	var balanceOf = func(id string) state.Balance {
+		s, err := c.getState(b.ClientState, id)
+		require.True(t, isValid(err))
+		return s.Balance
+	}
+
+	var newBatch = func(value int64, data string) *transaction.Transaction {
+		var txn = &transaction.Transaction{
+			ClientID:        clientID,
+			ToClientID:      batchTestSCAddress,
+			Value:           value,
+			TransactionType: transaction.TxnTypeSmartContractBatch,
+			TransactionData: data,
+		}
+		txn.Hash = txn.ComputeHash()
+		return txn
+	}
+
+	// a failed call rolls back the whole batch
+	var root = b.ClientState.GetRoot()
+	var failed = newBatch(10, `[{"name":"lock","value":10},{"name":"fail"}]`)
+	err = c.updateState(b, failed)
+	require.Error(t, err)
+	assert.Equal(t, root, b.ClientState.GetRoot())
+	assert.EqualValues(t, 100, balanceOf(clientID))
+	assert.Empty(t, failed.Events)
+
+	// the transfers of a call are applied before the next call
+	var txn = newBatch(30, `[{"name":"lock","value":10},{"name":"check"},`+
+		`{"name":"lock","value":20}]`)
+	require.NoError(t, c.updateState(b, txn))
+	assert.EqualValues(t, 70, balanceOf(clientID))
+	assert.EqualValues(t, 30, balanceOf(batchTestSCAddress))
+	assert.Equal(t, `["locked","checked","locked"]`, txn.TransactionOutput)
+
+	// the events of the calls, in order
+	require.Len(t, txn.Events, 2)
+	assert.Equal(t, "locked", txn.Events[0].Type)
+	assert.Equal(t, "10", txn.Events[0].Attributes["value"])
+	assert.Equal(t, "20", txn.Events[1].Attributes["value"])
+}
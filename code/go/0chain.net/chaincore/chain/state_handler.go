@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"0chain.net/chaincore/smartcontract"
+	"0chain.net/chaincore/state"
 	sci "0chain.net/chaincore/smartcontractinterface"
 
 	"0chain.net/chaincore/transaction"
@@ -26,6 +27,8 @@ func SetupStateHandlers() {
 	c := GetServerChain()
 	http.HandleFunc("/v1/client/get/balance", common.UserRateLimit(common.ToJSONResponse(c.GetBalanceHandler)))
 	http.HandleFunc("/v1/scstate/get", common.UserRateLimit(common.ToJSONResponse(c.GetNodeFromSCState)))
+	http.HandleFunc("/v1/client/get/balance_proof", common.UserRateLimit(common.ToJSONResponse(c.GetBalanceProofHandler)))
+	http.HandleFunc("/v1/scstate/get_proof", common.UserRateLimit(common.ToJSONResponse(c.GetSCStateProofHandler)))
 	http.HandleFunc("/v1/scstats/", common.UserRateLimit(c.GetSCStats))
 	http.HandleFunc("/v1/screst/", common.UserRateLimit(c.HandleSCRest))
 	http.HandleFunc("/_smart_contract_stats", common.UserRateLimit(c.SCStats))
@@ -58,15 +61,15 @@ func (c *Chain) GetSCRestOutput(ctx context.Context, r *http.Request) (interface
 
 	scAddress := pathParams[1]
 	scRestPath := "/" + pathParams[2]
+	b, err := c.getStateAtParam(r)
+	if err != nil {
+		return nil, err
+	}
 	c.stateMutex.RLock()
 	defer c.stateMutex.RUnlock()
 
-	lfb := c.GetLatestFinalizedBlock()
-	if lfb == nil || lfb.ClientState == nil {
-		return nil, common.NewError("empty_lfb", "empty latest finalized block or state")
-	}
-	clientState := CreateTxnMPT(lfb.ClientState) // begin transaction
-	sctx := c.newStateContext(lfb, clientState, &transaction.Transaction{})
+	clientState := CreateTxnMPT(b.ClientState) // begin transaction
+	sctx := c.newStateContext(b, clientState, &transaction.Transaction{})
 	resp, err := smartcontract.ExecuteRestAPI(ctx, scAddress, scRestPath, r.URL.Query(), sctx)
 
 	if err != nil {
@@ -79,16 +82,13 @@ func (c *Chain) GetSCRestOutput(ctx context.Context, r *http.Request) (interface
 func (c *Chain) GetNodeFromSCState(ctx context.Context, r *http.Request) (interface{}, error) {
 	scAddress := r.FormValue("sc_address")
 	key := r.FormValue("key")
-	lfb := c.GetLatestFinalizedBlock()
-	if lfb == nil {
-		return nil, common.NewError("failed to get sc state", "finalized block doesn't exist")
-	}
-	if lfb.ClientState == nil {
-		return nil, common.NewError("failed to get sc state", "finalized block's state doesn't exist")
+	b, err := c.getStateAtParam(r)
+	if err != nil {
+		return nil, err
 	}
 	c.stateMutex.RLock()
 	defer c.stateMutex.RUnlock()
-	node, err := lfb.ClientState.GetNodeValue(util.Path(encryption.Hash(scAddress + key)))
+	node, err := b.ClientState.GetNodeValue(util.Path(encryption.Hash(scAddress + key)))
 	if err != nil {
 		return nil, err
 	}
@@ -103,19 +103,94 @@ func (c *Chain) GetNodeFromSCState(ctx context.Context, r *http.Request) (interf
 	return retObj, nil
 }
 
-/*GetBalanceHandler - get the balance of a client */
+// StateProof - a value of the state of the latest finalized block with the
+// proof of its inclusion in the state (see util.VerifyMPTProof).
+type StateProof struct {
+	BlockHash string `json:"block_hash"`
+	Round     int64  `json:"round"`
+	*util.MPTProof
+	Value interface{} `json:"value"`
+}
+
+// getStateProof returns the value at the given path of the state of the
+// latest finalized block with the proof of its inclusion.
+func (c *Chain) getStateProof(path util.Path) (*StateProof, util.Serializable, error) {
+	lfb := c.GetLatestFinalizedBlock()
+	if lfb == nil || lfb.ClientState == nil {
+		return nil, nil, common.NewError("empty_lfb", "empty latest finalized block or state")
+	}
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+	value, err := lfb.ClientState.GetNodeValue(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := util.GetMPTProof(lfb.ClientState, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &StateProof{BlockHash: lfb.Hash, Round: lfb.Round, MPTProof: proof}, value, nil
+}
+
+/*GetBalanceProofHandler - get the balance of a client with the proof of its
+inclusion in the state of the latest finalized block */
+func (c *Chain) GetBalanceProofHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	clientID := r.FormValue("client_id")
+	sp, value, err := c.getStateProof(util.Path(clientID))
+	if err != nil {
+		return nil, err
+	}
+	st := c.clientStateDeserializer.Deserialize(value).(*state.State)
+	st.ComputeProperties()
+	sp.Value = st
+	return sp, nil
+}
+
+/*GetSCStateProofHandler - get a node of a smart contract state with the proof
+of its inclusion in the state of the latest finalized block */
+func (c *Chain) GetSCStateProofHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	scAddress := r.FormValue("sc_address")
+	key := r.FormValue("key")
+	sp, value, err := c.getStateProof(util.Path(encryption.Hash(scAddress + key)))
+	if err != nil {
+		return nil, err
+	}
+	var retObj interface{}
+	if err = json.Unmarshal(value.Encode(), &retObj); err != nil {
+		return nil, err
+	}
+	sp.Value = retObj
+	return sp, nil
+}
+
+// ClientBalance is the client state with the next nonce the client is
+// expected to use.
+type ClientBalance struct {
+	*state.State
+	NextNonce int64 `json:"next_nonce"`
+}
+
+/*GetBalanceHandler - get the balance of a client, of the given round in the
+archive mode */
 func (c *Chain) GetBalanceHandler(ctx context.Context, r *http.Request) (interface{}, error) {
 	clientID := r.FormValue("client_id")
-	lfb := c.GetLatestFinalizedBlock()
-	if lfb == nil {
+	if c.GetLatestFinalizedBlock() == nil {
 		return nil, common.ErrTemporaryFailure
 	}
-	state, err := c.GetState(lfb, clientID)
+	b, err := c.getStateAtParam(r)
+	if err != nil {
+		return nil, err
+	}
+	st, err := c.GetState(b, clientID)
+	if err == util.ErrValueNotPresent {
+		// a client not in the state yet starts with the first nonce
+		return &ClientBalance{State: &state.State{}, NextNonce: 1}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	state.ComputeProperties()
-	return state, nil
+	st.ComputeProperties()
+	return &ClientBalance{State: st, NextNonce: st.Nonce + 1}, nil
 }
 
 func (c *Chain) GetSCStats(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestChain_validateAndUpdateNonce(t *testing.T) {
+	logging.Logger = zap.NewNop()
+
+	var (
+		c = &Chain{
+			Config:                  &Config{},
+			clientStateDeserializer: &state.Deserializer{},
+		}
+		b        = block.Provider().(*block.Block)
+		clientID = encryption.Hash("client")
+		toID     = encryption.Hash("to")
+	)
+	b.ClientState = util.NewMerklePatriciaTrie(util.NewLevelNodeDB(
+		util.NewMemoryNodeDB(), util.NewMemoryNodeDB(), false), 0)
+	var cs = &state.State{Balance: 100}
+	require.NoError(t, cs.SetTxnHash(encryption.Hash("genesis")))
+	_, err := b.ClientState.Insert(util.Path(clientID), cs)
+	require.NoError(t, err)
+
+	var newSend = func(nonce int64) *transaction.Transaction {
+		var txn = &transaction.Transaction{
+			ClientID:        clientID,
+			ToClientID:      toID,
+			Value:           1,
+			Nonce:           nonce,
+			TransactionType: transaction.TxnTypeSend,
+		}
+		txn.Hash = txn.ComputeHash()
+		return txn
+	}
+
+	var nextNonce = func() int64 {
+		s, err := c.getState(b.ClientState, clientID)
+		require.True(t, isValid(err))
+		return s.Nonce + 1
+	}
+
+	// validate against the state
+	var sctx = c.newStateContext(b, b.ClientState, newSend(1))
+	assert.NoError(t, c.validateNonce(sctx, newSend(0))) // not nonce based
+	assert.NoError(t, c.validateNonce(sctx, newSend(1)))
+	assert.Equal(t, ErrInvalidNonce, c.validateNonce(sctx, newSend(2)))
+
+	// a client not in the state yet expects the first nonce
+	var other = newSend(1)
+	other.ClientID = encryption.Hash("other")
+	assert.NoError(t, c.validateNonce(sctx, other))
+
+	// update stores the nonce, not nonce based transactions don't touch it
+	require.NoError(t, c.updateNonce(sctx, newSend(0)))
+	assert.EqualValues(t, 1, nextNonce())
+	require.NoError(t, c.updateNonce(sctx, newSend(1)))
+	assert.EqualValues(t, 2, nextNonce())
+
+	// the state transition validates and updates the nonce
+	require.NoError(t, c.updateState(b, newSend(2)))
+	assert.EqualValues(t, 3, nextNonce())
+
+	// replay and gap
+	var root = b.ClientState.GetRoot()
+	assert.Equal(t, ErrInvalidNonce, c.updateState(b, newSend(2)))
+	assert.Equal(t, ErrInvalidNonce, c.updateState(b, newSend(4)))
+	assert.Equal(t, root, b.ClientState.GetRoot())
+
+	// a client with a used nonce is kept in the state with zero balance
+	var all = newSend(3)
+	all.Value = 99
+	all.Hash = all.ComputeHash()
+	require.NoError(t, c.updateState(b, all))
+	s, err := c.getState(b.ClientState, clientID)
+	require.NoError(t, err)
+	assert.Zero(t, s.Balance)
+	assert.EqualValues(t, 3, s.Nonce)
+}
@@ -3,6 +3,7 @@ package chain
 import (
 	"0chain.net/chaincore/node"
 	"context"
+	"sync/atomic"
 	"time"
 
 	"0chain.net/chaincore/block"
@@ -147,6 +148,21 @@ func (c *Chain) pruneClientState(ctx context.Context) {
 		return
 	}
 
+	if c.isStateSnapshotPinned(bs.Round) {
+		// the nodes of the state being snapshotted can be older
+		Logger.Info("prune client state - hold off for state snapshot",
+			zap.Int64("round", bs.Round),
+			zap.Int64("snapshot_round", atomic.LoadInt64(&c.stateSnapshotRound)))
+		ps.Stage = util.PruneStateAbandoned
+		return
+	}
+
+	if c.StateArchive {
+		// the older versions are kept for the historical queries
+		ps.Stage = util.PruneStateCommplete
+		return
+	}
+
 	var t1 = time.Now()
 	ps.Stage = util.PruneStateDelete
 	err = c.stateDB.PruneBelowVersion(pctx, newVersion)
@@ -0,0 +1,361 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	. "0chain.net/core/logging"
+	"0chain.net/core/util"
+	"go.uber.org/zap"
+)
+
+// snapshotManifestFile is the name of the file of the manifest of a
+// snapshot, the chunks are stored next to it named by their hashes
+const snapshotManifestFile = "manifest.json"
+
+// ErrSnapshotNotFound - no snapshot of the state stored
+var ErrSnapshotNotFound = common.NewError("snapshot_not_found",
+	"state snapshot not found")
+
+func (c *Chain) snapshotDir(round int64) string {
+	return filepath.Join(c.StateSnapshotDir, strconv.FormatInt(round, 10))
+}
+
+// isStateSnapshotPinned returns true if pruning state versions below the
+// given round removes nodes of the state snapshot being created. A snapshot
+// started while pruning is always of a newer round than the pruned one.
+func (c *Chain) isStateSnapshotPinned(round int64) bool {
+	var pinned = atomic.LoadInt64(&c.stateSnapshotRound)
+	return pinned > 0 && pinned < round
+}
+
+// CreateStateSnapshot - export the client state of the finalized block as
+// chunks addressed by their hashes. The snapshot is written to a temporary
+// directory renamed once complete, so a partial snapshot is never served.
+// The round of the snapshot is pinned meanwhile, so the state pruning
+// holds off.
+func (c *Chain) CreateStateSnapshot(ctx context.Context, b *block.Block) (
+	snap *state.Snapshot, err error) {
+
+	if b.ClientState == nil {
+		return nil, common.NewError("create_state_snapshot",
+			"block has no state")
+	}
+	atomic.StoreInt64(&c.stateSnapshotRound, b.Round)
+	defer atomic.StoreInt64(&c.stateSnapshotRound, 0)
+	var (
+		dir = c.snapshotDir(b.Round)
+		tmp = dir + ".tmp"
+	)
+	if err = os.RemoveAll(tmp); err != nil {
+		return
+	}
+	if err = os.MkdirAll(tmp, 0755); err != nil {
+		return
+	}
+	defer os.RemoveAll(tmp)
+
+	snap = state.SnapshotProvider().(*state.Snapshot)
+	snap.Round = b.Round
+	snap.BlockHash = b.Hash
+	snap.Root = util.ToHex(b.ClientStateHash)
+	snap.SetKey(strconv.FormatInt(b.Round, 10))
+
+	snap.NumNodes, err = util.SnapshotMPT(ctx, b.ClientState,
+		c.StateSnapshotChunkSize,
+		func(chunk []byte) error {
+			var hash = encryption.Hash(chunk)
+			snap.Chunks = append(snap.Chunks, hash)
+			return ioutil.WriteFile(filepath.Join(tmp, hash), chunk, 0644)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest []byte
+	if manifest, err = json.Marshal(snap); err != nil {
+		return
+	}
+	err = ioutil.WriteFile(filepath.Join(tmp, snapshotManifestFile), manifest,
+		0644)
+	if err != nil {
+		return
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		return
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		return
+	}
+	return snap, nil
+}
+
+// getStateSnapshotRounds returns rounds of the stored snapshots, ascending
+func (c *Chain) getStateSnapshotRounds() (rounds []int64) {
+	var infos, err = ioutil.ReadDir(c.StateSnapshotDir)
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		round, err := strconv.ParseInt(info.Name(), 10, 64)
+		if err != nil {
+			continue // including the temporary ones
+		}
+		rounds = append(rounds, round)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	return
+}
+
+// pruneStateSnapshots removes all the snapshots but the latest ones
+func (c *Chain) pruneStateSnapshots() {
+	var rounds = c.getStateSnapshotRounds()
+	for len(rounds) > c.StateSnapshotKeep {
+		if err := os.RemoveAll(c.snapshotDir(rounds[0])); err != nil {
+			Logger.Error("prune state snapshots", zap.Int64("round", rounds[0]),
+				zap.Error(err))
+		}
+		rounds = rounds[1:]
+	}
+}
+
+// GetStateSnapshot - get the manifest of the stored snapshot of the given
+// round, or the latest one if the round is zero.
+func (c *Chain) GetStateSnapshot(round int64) (*state.Snapshot, error) {
+	if round == 0 {
+		var rounds = c.getStateSnapshotRounds()
+		if len(rounds) == 0 {
+			return nil, ErrSnapshotNotFound
+		}
+		round = rounds[len(rounds)-1]
+	}
+	var manifest, err = ioutil.ReadFile(filepath.Join(c.snapshotDir(round),
+		snapshotManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	var snap = state.SnapshotProvider().(*state.Snapshot)
+	if err = json.Unmarshal(manifest, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// GetStateSnapshotChunk - get a chunk of the stored snapshot of the round.
+func (c *Chain) GetStateSnapshotChunk(round int64, hash string) (
+	*state.SnapshotChunk, error) {
+
+	if !encryption.IsHash(hash) {
+		return nil, common.InvalidRequest("invalid chunk hash")
+	}
+	var data, err = ioutil.ReadFile(filepath.Join(c.snapshotDir(round), hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	var chunk = state.SnapshotChunkProvider().(*state.SnapshotChunk)
+	chunk.SetKey(hash)
+	chunk.Data = data
+	return chunk, nil
+}
+
+// StateSnapshotWorker - snapshot the state of the finalized blocks of the
+// rounds multiple of the configured interval.
+func (c *Chain) StateSnapshotWorker(ctx context.Context) {
+	if c.StateSnapshotInterval <= 0 {
+		return
+	}
+	var sub = c.SubscribeFinalizedBlocks(1)
+	defer func() { sub.Unsubscribe() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case fb, ok := <-sub.C:
+			if !ok {
+				// dropped while snapshotting
+				sub = c.SubscribeFinalizedBlocks(1)
+				continue
+			}
+			if fb.Round%c.StateSnapshotInterval != 0 {
+				continue
+			}
+			snap, err := c.CreateStateSnapshot(ctx, fb)
+			if err != nil {
+				Logger.Error("create state snapshot", zap.Int64("round", fb.Round),
+					zap.Error(err))
+				continue
+			}
+			Logger.Info("create state snapshot", zap.Int64("round", fb.Round),
+				zap.String("root", snap.Root), zap.Int64("nodes", snap.NumNodes),
+				zap.Int("chunks", len(snap.Chunks)))
+			c.pruneStateSnapshots()
+		}
+	}
+}
+
+// getStateSnapshotFromSharders returns the latest snapshot manifest given by
+// the sharders and the sharder given it.
+func (c *Chain) getStateSnapshotFromSharders(ctx context.Context) (
+	snap *state.Snapshot, sharder *node.Node) {
+
+	var mb = c.GetLatestFinalizedMagicBlock()
+	for _, sh := range mb.Sharders.CopyNodes() {
+		if node.Self.IsEqual(sh) || sh.GetStatus() == node.NodeStatusInactive {
+			continue
+		}
+		var handler = func(ctx context.Context, entity datastore.Entity) (
+			interface{}, error) {
+
+			var s, ok = entity.(*state.Snapshot)
+			if !ok {
+				return nil, datastore.ErrInvalidEntity
+			}
+			if s.Round <= 0 || len(s.Chunks) == 0 || s.BlockHash == "" {
+				return nil, common.NewError("invalid_state_snapshot",
+					"incomplete manifest")
+			}
+			if snap == nil || s.Round > snap.Round {
+				snap, sharder = s, sh
+			}
+			return s, nil
+		}
+		sh.RequestEntityFromNode(ctx, StateSnapshotRequestor, &url.Values{},
+			handler)
+	}
+	return
+}
+
+// getStateSnapshotChunk requests the chunk from the sharder given the
+// manifest, then from the other sharders. The data of the chunk is verified
+// against its hash.
+func (c *Chain) getStateSnapshotChunk(ctx context.Context,
+	sharder *node.Node, round int64, hash string) (nodes []util.Node,
+	err error) {
+
+	var params = &url.Values{}
+	params.Add("round", strconv.FormatInt(round, 10))
+	params.Add("hash", hash)
+
+	var handler = func(ctx context.Context, entity datastore.Entity) (
+		interface{}, error) {
+
+		var chunk, ok = entity.(*state.SnapshotChunk)
+		if !ok {
+			return nil, datastore.ErrInvalidEntity
+		}
+		if chunk.ComputeHash() != hash {
+			return nil, common.NewError("invalid_state_snapshot_chunk",
+				"chunk hash mismatch")
+		}
+		var cnodes, err = util.DecodeSnapshotChunk(chunk.Data)
+		if err != nil {
+			return nil, err
+		}
+		nodes = cnodes
+		return chunk, nil
+	}
+	if sharder != nil &&
+		sharder.RequestEntityFromNode(ctx, StateSnapshotChunkRequestor, params,
+			handler) && nodes != nil {
+		return
+	}
+	var mb = c.GetLatestFinalizedMagicBlock()
+	mb.Sharders.RequestEntity(ctx, StateSnapshotChunkRequestor, params, handler)
+	if nodes == nil {
+		return nil, common.NewErrorf("get_state_snapshot_chunk",
+			"can't get chunk %s of round %d", hash, round)
+	}
+	return
+}
+
+// SyncStateFromSnapshot - sync the client state from the latest snapshot of
+// the sharders of the latest finalized magic block. The root of the snapshot
+// is verified against the client state hash of the notarized block of its
+// round and the state is verified to be complete. Returns the block with the
+// synced state, the blocks are synced starting from it. Only a snapshot newer
+// than the given round is synced.
+func (c *Chain) SyncStateFromSnapshot(ctx context.Context, after int64) (
+	fb *block.Block, err error) {
+
+	var snap, sharder = c.getStateSnapshotFromSharders(ctx)
+	if snap == nil || snap.Round <= after {
+		return nil, ErrSnapshotNotFound
+	}
+	Logger.Info("sync state from snapshot", zap.Int64("round", snap.Round),
+		zap.String("block", snap.BlockHash), zap.String("root", snap.Root),
+		zap.Int("chunks", len(snap.Chunks)))
+
+	var ticket = &LFBTicket{Round: snap.Round, LFBHash: snap.BlockHash}
+	if fb, err = c.getFinalizedBlockFromSharders(ctx, ticket); err != nil {
+		return nil, err
+	}
+	err = c.syncStateSnapshot(ctx, fb, snap,
+		func(hash string) ([]util.Node, error) {
+			return c.getStateSnapshotChunk(ctx, sharder, snap.Round, hash)
+		})
+	if err != nil {
+		return nil, err
+	}
+	Logger.Info("sync state from snapshot - done",
+		zap.Int64("round", fb.Round), zap.String("block", fb.Hash))
+	return fb, nil
+}
+
+// syncStateSnapshot saves the nodes of the chunks of the snapshot given by
+// the getChunk and sets the synced state of the snapshot to the block.
+func (c *Chain) syncStateSnapshot(ctx context.Context, fb *block.Block,
+	snap *state.Snapshot, getChunk func(hash string) ([]util.Node, error)) (
+	err error) {
+
+	if util.ToHex(fb.ClientStateHash) != snap.Root {
+		return block.ErrBlockStateHashMismatch
+	}
+
+	for i, hash := range snap.Chunks {
+		var nodes []util.Node
+		if nodes, err = getChunk(hash); err != nil {
+			return
+		}
+		var ns = state.NewStateNodes()
+		ns.Nodes = nodes
+		if err = c.SaveStateNodes(ctx, ns); err != nil {
+			return
+		}
+		Logger.Debug("sync state from snapshot - chunk saved",
+			zap.Int("chunk", i), zap.Int("nodes", len(nodes)))
+	}
+
+	// the nodes are stored by their hashes, a valid trie from the root
+	// is the state of the block
+	fb.CreateState(c.stateDB)
+	fb.ClientState.SetRoot(fb.ClientStateHash)
+	if err = util.IsMPTValid(fb.ClientState); err != nil {
+		Logger.Error("sync state from snapshot - incomplete state",
+			zap.Int64("round", fb.Round), zap.Error(err))
+		return
+	}
+	fb.SetStateStatus(block.StateSynched)
+	return
+}
@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newSnapshotTestChain(t *testing.T) (c *Chain) {
+	dir, err := ioutil.TempDir("", "state_snapshot")
+	require.NoError(t, err)
+	return &Chain{
+		Config: &Config{
+			StateSnapshotKeep:      2,
+			StateSnapshotChunkSize: 1024,
+			StateSnapshotDir:       dir,
+		},
+		stateDB:    util.NewMemoryNodeDB(),
+		stateMutex: &sync.RWMutex{},
+	}
+}
+
+func newSnapshotTestBlock(t *testing.T, round int64, n int) (
+	b *block.Block, keys []string) {
+
+	b = block.Provider().(*block.Block)
+	b.Round = round
+	b.Hash = encryption.Hash("block " + strconv.FormatInt(round, 10))
+	b.ClientState = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(),
+		util.Sequence(round))
+	for i := 0; i < n; i++ {
+		var (
+			key = encryption.Hash(strconv.Itoa(i))
+			cs  = &state.State{Balance: state.Balance(i + 1)}
+		)
+		require.NoError(t, cs.SetTxnHash(encryption.Hash(key)))
+		_, err := b.ClientState.Insert(util.Path(key), cs)
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	b.ClientStateHash = b.ClientState.GetRoot()
+	return
+}
+
+func TestChain_StateSnapshot(t *testing.T) {
+	logging.Logger = zap.NewNop()
+	state.SetupStateNodes(nil)
+	state.SetupStateSnapshot(nil)
+
+	var (
+		ctx     = context.Background()
+		src     = newSnapshotTestChain(t)
+		b, keys = newSnapshotTestBlock(t, 100, 200)
+	)
+	defer os.RemoveAll(src.StateSnapshotDir)
+
+	_, err := src.GetStateSnapshot(0)
+	assert.Equal(t, ErrSnapshotNotFound, err)
+
+	// create and keep the latest snapshots only
+	for _, round := range []int64{50, 75} {
+		old, _ := newSnapshotTestBlock(t, round, 1)
+		_, err = src.CreateStateSnapshot(ctx, old)
+		require.NoError(t, err)
+	}
+	snap, err := src.CreateStateSnapshot(ctx, b)
+	require.NoError(t, err)
+	assert.Zero(t, src.stateSnapshotRound, "pinned after creation")
+	assert.True(t, len(snap.Chunks) > 1, "expected several chunks")
+	src.pruneStateSnapshots()
+	assert.Equal(t, []int64{75, 100}, src.getStateSnapshotRounds())
+
+	got, err := src.GetStateSnapshot(0)
+	require.NoError(t, err)
+	assert.Equal(t, snap.Round, got.Round)
+	assert.Equal(t, snap.BlockHash, got.BlockHash)
+	assert.Equal(t, util.ToHex(b.ClientStateHash), got.Root)
+	assert.Equal(t, snap.Chunks, got.Chunks)
+	_, err = src.GetStateSnapshot(50)
+	assert.Equal(t, ErrSnapshotNotFound, err)
+	_, err = src.GetStateSnapshotChunk(100, "../manifest.json")
+	assert.Error(t, err)
+
+	// sync the snapshot by another node
+	var getChunk = func(hash string) ([]util.Node, error) {
+		var chunk, err = src.GetStateSnapshotChunk(got.Round, hash)
+		if err != nil {
+			return nil, err
+		}
+		require.Equal(t, hash, chunk.ComputeHash())
+		return util.DecodeSnapshotChunk(chunk.Data)
+	}
+
+	var (
+		dst = newSnapshotTestChain(t)
+		fb  = block.Provider().(*block.Block)
+	)
+	defer os.RemoveAll(dst.StateSnapshotDir)
+	fb.Round, fb.Hash = b.Round, b.Hash
+
+	// state hash of the block mismatches the snapshot
+	fb.ClientStateHash = util.Key(encryption.RawHash("other"))
+	err = dst.syncStateSnapshot(ctx, fb, got, getChunk)
+	assert.Equal(t, block.ErrBlockStateHashMismatch, err)
+	fb.ClientStateHash = b.ClientStateHash
+
+	// a chunk is missing, the state is incomplete
+	err = dst.syncStateSnapshot(ctx, fb, got,
+		func(hash string) ([]util.Node, error) {
+			if hash == got.Chunks[0] {
+				return nil, nil
+			}
+			return getChunk(hash)
+		})
+	require.Error(t, err)
+	assert.NotEqual(t, int8(block.StateSynched), fb.GetStateStatus())
+
+	require.NoError(t, dst.syncStateSnapshot(ctx, fb, got, getChunk))
+	assert.Equal(t, int8(block.StateSynched), fb.GetStateStatus())
+	assert.Equal(t, b.ClientStateHash, fb.ClientState.GetRoot())
+	for i, key := range keys {
+		var val, err = fb.ClientState.GetNodeValue(util.Path(key))
+		require.NoError(t, err)
+		var cs = &state.State{}
+		require.NoError(t, cs.Decode(val.Encode()))
+		assert.EqualValues(t, i+1, cs.Balance)
+	}
+}
+
+func TestChain_isStateSnapshotPinned(t *testing.T) {
+	var c = &Chain{Config: &Config{}}
+	assert.False(t, c.isStateSnapshotPinned(100))
+
+	c.stateSnapshotRound = 200
+	assert.False(t, c.isStateSnapshotPinned(100))
+	assert.False(t, c.isStateSnapshotPinned(200))
+	assert.True(t, c.isStateSnapshotPinned(300))
+}
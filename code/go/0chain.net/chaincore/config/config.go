@@ -21,9 +21,16 @@ func SetupDefaultConfig() {
 	viper.SetDefault("server_chain.round_range", 10000000)
 	viper.SetDefault("server_chain.transaction.payload.max_size", 32)
 	viper.SetDefault("server_chain.state.prune_below_count", 100)
+	viper.SetDefault("server_chain.state.snapshot.interval", 0)
+	viper.SetDefault("server_chain.state.snapshot.keep", 2)
+	viper.SetDefault("server_chain.state.snapshot.chunk_size", 1024*1024)
+	viper.SetDefault("server_chain.state.snapshot.dir", "data/snapshots")
+	viper.SetDefault("server_chain.state.snapshot.bootstrap", false)
+	viper.SetDefault("server_chain.state.archive", false)
 	viper.SetDefault("server_chain.block.consensus.threshold_by_count", 67)
 	viper.SetDefault("server_chain.block.generation.timeout", 37)
 	viper.SetDefault("server_chain.transaction.timeout", 30)
+	viper.SetDefault("server_chain.transaction.mempool.replace_fee_bump", 10)
 	viper.SetDefault("server_chain.block.generation.retry_wait_time", 5)
 	viper.SetDefault("server_chain.block.proposal.max_wait_time", 200)
 	viper.SetDefault("server_chain.block.proposal.wait_mode", "static")
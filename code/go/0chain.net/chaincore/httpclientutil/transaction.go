@@ -41,6 +41,9 @@ const (
 
 	//TxnTypeSmartContract A smart contract transaction type
 	TxnTypeSmartContract = 1000
+
+	//TxnTypeSmartContractBatch A list of smart contract calls executed atomically
+	TxnTypeSmartContractBatch = 1002
 )
 
 //SmartContractTxnData Smart Contract Txn Data
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"context"
+
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+)
+
+/*Snapshot - manifest of a snapshot of the client state at a finalized round.
+* The nodes of the state are exported as chunks addressed by their hashes, the
+* root is the client state hash of the block of the round. */
+type Snapshot struct {
+	datastore.IDField
+	Version   string   `json:"version"`
+	Round     int64    `json:"round"`
+	BlockHash string   `json:"block_hash"`
+	Root      string   `json:"root"`
+	Chunks    []string `json:"chunks"`
+	NumNodes  int64    `json:"num_nodes"`
+}
+
+var snapshotEntityMetadata *datastore.EntityMetadataImpl
+
+/*SnapshotProvider - a state snapshot instance provider */
+func SnapshotProvider() datastore.Entity {
+	s := &Snapshot{}
+	s.Version = "1.0"
+	return s
+}
+
+/*GetEntityMetadata - implement interface */
+func (s *Snapshot) GetEntityMetadata() datastore.EntityMetadata {
+	return snapshotEntityMetadata
+}
+
+/*Read - store read */
+func (s *Snapshot) Read(ctx context.Context, key datastore.Key) error {
+	return s.GetEntityMetadata().GetStore().Read(ctx, key, s)
+}
+
+/*Write - store read */
+func (s *Snapshot) Write(ctx context.Context) error {
+	return s.GetEntityMetadata().GetStore().Write(ctx, s)
+}
+
+/*Delete - store read */
+func (s *Snapshot) Delete(ctx context.Context) error {
+	return s.GetEntityMetadata().GetStore().Delete(ctx, s)
+}
+
+/*SetupStateSnapshot - setup the state snapshot entities */
+func SetupStateSnapshot(store datastore.Store) {
+	snapshotEntityMetadata = datastore.MetadataProvider()
+	snapshotEntityMetadata.Name = "state_snapshot"
+	snapshotEntityMetadata.Provider = SnapshotProvider
+	snapshotEntityMetadata.Store = store
+	snapshotEntityMetadata.IDColumnName = "id"
+	datastore.RegisterEntityMetadata("state_snapshot", snapshotEntityMetadata)
+
+	snapshotChunkEntityMetadata = datastore.MetadataProvider()
+	snapshotChunkEntityMetadata.Name = "state_snapshot_chunk"
+	snapshotChunkEntityMetadata.Provider = SnapshotChunkProvider
+	snapshotChunkEntityMetadata.Store = store
+	snapshotChunkEntityMetadata.IDColumnName = "id"
+	datastore.RegisterEntityMetadata("state_snapshot_chunk", snapshotChunkEntityMetadata)
+}
+
+/*SnapshotChunk - a chunk of a state snapshot, the id is the hash of the data */
+type SnapshotChunk struct {
+	datastore.IDField
+	Data []byte `json:"data"`
+}
+
+var snapshotChunkEntityMetadata *datastore.EntityMetadataImpl
+
+/*SnapshotChunkProvider - a state snapshot chunk instance provider */
+func SnapshotChunkProvider() datastore.Entity {
+	return &SnapshotChunk{}
+}
+
+/*GetEntityMetadata - implement interface */
+func (sc *SnapshotChunk) GetEntityMetadata() datastore.EntityMetadata {
+	return snapshotChunkEntityMetadata
+}
+
+/*Read - store read */
+func (sc *SnapshotChunk) Read(ctx context.Context, key datastore.Key) error {
+	return sc.GetEntityMetadata().GetStore().Read(ctx, key, sc)
+}
+
+/*Write - store read */
+func (sc *SnapshotChunk) Write(ctx context.Context) error {
+	return sc.GetEntityMetadata().GetStore().Write(ctx, sc)
+}
+
+/*Delete - store read */
+func (sc *SnapshotChunk) Delete(ctx context.Context) error {
+	return sc.GetEntityMetadata().GetStore().Delete(ctx, sc)
+}
+
+/*ComputeHash - hash of the data of the chunk */
+func (sc *SnapshotChunk) ComputeHash() string {
+	return encryption.Hash(sc.Data)
+}
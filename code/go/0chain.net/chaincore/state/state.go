@@ -23,6 +23,9 @@ type State struct {
 	TxnHashBytes []byte  `json:"-" msgpack:"t"`
 	Round        int64   `json:"round" msgpack:"r"`
 	Balance      Balance `json:"balance" msgpack:"b"`
+	// Nonce of the last transaction of the client applied to the state,
+	// zero for clients that never sent a nonce based transaction.
+	Nonce int64 `json:"nonce" msgpack:"n"`
 }
 
 /*GetHash - implement SecureSerializableValueI interface */
@@ -41,6 +44,11 @@ func (s *State) Encode() []byte {
 	buf.Write(s.TxnHashBytes)
 	binary.Write(buf, binary.LittleEndian, s.Round)
 	binary.Write(buf, binary.LittleEndian, s.Balance)
+	// the nonce is appended only when it's used to keep hashes of the
+	// states of clients that never sent a nonce based transaction intact
+	if s.Nonce != 0 {
+		binary.Write(buf, binary.LittleEndian, s.Nonce)
+	}
 	return buf.Bytes()
 }
 
@@ -49,14 +57,19 @@ func (s *State) Decode(data []byte) error {
 	buf := bytes.NewBuffer(data)
 	var origin int64
 	var balance Balance
+	var nonce int64
 	s.TxnHashBytes = make([]byte, 32)
 	if n, err := buf.Read(s.TxnHashBytes); err != nil || n != 32 {
 		Logger.Error("invalid state")
 	}
 	binary.Read(buf, binary.LittleEndian, &origin)
 	binary.Read(buf, binary.LittleEndian, &balance)
+	if buf.Len() > 0 {
+		binary.Read(buf, binary.LittleEndian, &nonce)
+	}
 	s.Round = origin
 	s.Balance = Balance(balance)
+	s.Nonce = nonce
 	return nil
 }
 
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"testing"
+
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_EncodeDecode(t *testing.T) {
+	var s = &State{Round: 10, Balance: 100}
+	require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+
+	// without a nonce the encoding is the same as before the nonces
+	var legacy = s.Encode()
+	assert.Len(t, legacy, 32+8+8)
+
+	var got State
+	require.NoError(t, got.Decode(legacy))
+	assert.Equal(t, s.TxnHashBytes, got.TxnHashBytes)
+	assert.EqualValues(t, 10, got.Round)
+	assert.EqualValues(t, 100, got.Balance)
+	assert.Zero(t, got.Nonce)
+
+	// with a nonce
+	s.Nonce = 5
+	var encoded = s.Encode()
+	assert.Len(t, encoded, 32+8+8+8)
+	assert.NotEqual(t, legacy, encoded)
+
+	got = State{}
+	require.NoError(t, got.Decode(encoded))
+	assert.Equal(t, s.TxnHashBytes, got.TxnHashBytes)
+	assert.EqualValues(t, 10, got.Round)
+	assert.EqualValues(t, 100, got.Balance)
+	assert.EqualValues(t, 5, got.Nonce)
+}
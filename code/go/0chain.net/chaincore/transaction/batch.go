@@ -0,0 +1,90 @@
+package transaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+)
+
+// MaxBatchCalls is the max number of calls in a batch transaction.
+const MaxBatchCalls = 20
+
+// SmartContractBatchCall - a smart contract call of a batch transaction.
+type SmartContractBatchCall struct {
+	// Address of the smart contract, the transaction's to_client_id
+	// if omitted.
+	Address datastore.Key `json:"address,omitempty"`
+	// Value of the call, the values of all calls sum up to the value
+	// of the transaction.
+	Value        int64           `json:"value,omitempty"`
+	FunctionName string          `json:"name"`
+	InputData    json.RawMessage `json:"input"`
+}
+
+// GetBatchCalls - decode and validate the calls of a batch transaction.
+func (t *Transaction) GetBatchCalls() (calls []*SmartContractBatchCall,
+	err error) {
+
+	if err = json.Unmarshal([]byte(t.TransactionData), &calls); err != nil {
+		return nil, common.NewError("invalid_batch",
+			"decoding batch calls: "+err.Error())
+	}
+	if len(calls) == 0 {
+		return nil, common.NewError("invalid_batch", "empty batch")
+	}
+	if len(calls) > MaxBatchCalls {
+		return nil, common.NewError("invalid_batch",
+			fmt.Sprintf("too many calls in batch: %d > %d", len(calls),
+				MaxBatchCalls))
+	}
+	var total int64
+	for i, call := range calls {
+		if call == nil || call.FunctionName == "" {
+			return nil, common.NewError("invalid_batch",
+				fmt.Sprintf("missing function name of call %d", i))
+		}
+		if call.Value < 0 {
+			return nil, common.NewError("invalid_batch",
+				fmt.Sprintf("negative value of call %d", i))
+		}
+		if call.Address == "" {
+			call.Address = t.ToClientID
+		}
+		total += call.Value
+	}
+	if total != t.Value {
+		return nil, common.NewError("invalid_batch",
+			fmt.Sprintf("values of calls sum up to %d, transaction value %d",
+				total, t.Value))
+	}
+	return
+}
+
+// BatchCallTxn - create transaction of i-th call of the batch transaction.
+// It has the same client and nonce, while its address, value and data are
+// given by the call. Its hash is derived from the hash of the batch
+// transaction and the index of the call, thus entities keyed by transaction
+// hash created by different calls don't collide. It doesn't pay a fee, the
+// batch transaction does.
+func (t *Transaction) BatchCallTxn(i int, call *SmartContractBatchCall) (
+	ct *Transaction) {
+
+	var data, _ = json.Marshal(&smartContractTransactionData{
+		FunctionName: call.FunctionName,
+		InputData:    call.InputData,
+	})
+	ct = new(Transaction)
+	*ct = *t
+	ct.Hash = encryption.Hash(t.Hash + ":" + strconv.Itoa(i))
+	ct.TransactionType = TxnTypeSmartContract
+	ct.ToClientID = call.Address
+	ct.Value = call.Value
+	ct.TransactionData = string(data)
+	ct.TransactionOutput = ""
+	ct.Fee = 0
+	return
+}
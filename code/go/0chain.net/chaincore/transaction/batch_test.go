@@ -0,0 +1,61 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_GetBatchCalls(t *testing.T) {
+	var txn = &Transaction{
+		ToClientID:      "sc_address",
+		Value:           30,
+		TransactionType: TxnTypeSmartContractBatch,
+	}
+
+	txn.TransactionData = `[{"name":"new_allocation_request","input":{}},` +
+		`{"address":"other_sc","value":10,"name":"read_pool_lock","input":{"a":1}},` +
+		`{"value":20,"name":"stake_pool_lock","input":null}]`
+
+	calls, err := txn.GetBatchCalls()
+	require.NoError(t, err)
+	require.Len(t, calls, 3)
+	assert.EqualValues(t, "sc_address", calls[0].Address)
+	assert.EqualValues(t, "other_sc", calls[1].Address)
+	assert.EqualValues(t, "sc_address", calls[2].Address)
+
+	txn.Hash = encryption.Hash("batch")
+	var ct = txn.BatchCallTxn(1, calls[1])
+	assert.Equal(t, TxnTypeSmartContract, ct.TransactionType)
+	assert.NotEqual(t, txn.Hash, ct.Hash)
+	assert.NotEqual(t, txn.BatchCallTxn(0, calls[0]).Hash, ct.Hash)
+	assert.Equal(t, txn.BatchCallTxn(1, calls[1]).Hash, ct.Hash)
+	assert.EqualValues(t, "other_sc", ct.ToClientID)
+	assert.EqualValues(t, 10, ct.Value)
+	assert.Zero(t, ct.Fee)
+
+	var data smartContractTransactionData
+	require.NoError(t, json.Unmarshal([]byte(ct.TransactionData), &data))
+	assert.Equal(t, "read_pool_lock", data.FunctionName)
+	assert.JSONEq(t, `{"a":1}`, string(data.InputData))
+
+	// the batch transaction is not changed
+	assert.EqualValues(t, "sc_address", txn.ToClientID)
+	assert.EqualValues(t, 30, txn.Value)
+
+	for _, data := range []string{
+		`not a json`,
+		`[]`,
+		`[{"input":{}}]`,
+		`[{"name":"lock","value":-1}]`,
+		`[{"name":"lock","value":10}]`, // doesn't match txn value
+	} {
+		txn.TransactionData = data
+		_, err = txn.GetBatchCalls()
+		assert.Error(t, err, data)
+	}
+}
@@ -53,11 +53,13 @@ type Transaction struct {
 	Signature       string           `json:"signature" msgpack:"s"`
 	CreationDate    common.Timestamp `json:"creation_date" msgpack:"ts"`
 	Fee             int64            `json:"transaction_fee" msgpack:"f"`
+	Nonce           int64            `json:"transaction_nonce,omitempty" msgpack:"n,omitempty"`
 
-	TransactionType   int    `json:"transaction_type" msgpack:"tt"`
-	TransactionOutput string `json:"transaction_output,omitempty" msgpack:"o,omitempty"`
-	OutputHash        string `json:"txn_output_hash" msgpack:"oh"`
-	Status            int    `json:"transaction_status" msgpack:"sot"`
+	TransactionType   int      `json:"transaction_type" msgpack:"tt"`
+	TransactionOutput string   `json:"transaction_output,omitempty" msgpack:"o,omitempty"`
+	Events            []*Event `json:"events,omitempty" msgpack:"ev,omitempty"`
+	OutputHash        string   `json:"txn_output_hash" msgpack:"oh"`
+	Status            int      `json:"transaction_status" msgpack:"sot"`
 }
 
 type TransactionFeeStats struct {
@@ -98,18 +100,27 @@ var exemptedSCFunctions = map[string]bool{
 	"wait":                 true,
 }
 
-// ValidateFee - Validate fee
-func (t *Transaction) ValidateFee() error {
+// isExemptedFromFee - is the transaction a call of a SC function that
+// doesn't require a fee
+func (t *Transaction) isExemptedFromFee() bool {
 	if t.TransactionData != "" {
 		var smartContractData smartContractTransactionData
 		dataBytes := []byte(t.TransactionData)
 		err := json.Unmarshal(dataBytes, &smartContractData)
 		if err == nil {
 			if _, ok := exemptedSCFunctions[smartContractData.FunctionName]; ok {
-				return nil
+				return true
 			}
 		}
 	}
+	return false
+}
+
+// ValidateFee - Validate fee
+func (t *Transaction) ValidateFee() error {
+	if t.isExemptedFromFee() {
+		return nil
+	}
 	if t.Fee < TXN_MIN_FEE {
 		return common.InvalidRequest("The given fee is less than the minimum required fee to process the txn")
 	}
@@ -163,6 +174,9 @@ func (t *Transaction) ValidateWrtTimeForBlock(ctx context.Context, ts common.Tim
 	if t.ClientID == t.ToClientID {
 		return common.InvalidRequest("from and to client should be different")
 	}
+	if t.Nonce < 0 {
+		return common.InvalidRequest("nonce must be greater than or equal to zero")
+	}
 	err = t.VerifyHash(ctx)
 	if err != nil {
 		return err
@@ -187,10 +201,16 @@ func (t *Transaction) Validate(ctx context.Context) error {
 	return t.ValidateWrtTime(ctx, common.Now())
 }
 
-/*GetScore - score for write*/
+/*GetScore - score for write, transactions paying more per byte go first */
 func (t *Transaction) GetScore() int64 {
 	if config.DevConfiguration.IsFeeEnabled {
-		return t.Fee
+		if t.isExemptedFromFee() {
+			return exemptedTxnScore
+		}
+		if score := t.GetFeePerKB(); score > 0 {
+			return score
+		}
+		return 1 // zero score stands for time based one
 	}
 	return 0
 }
@@ -239,6 +259,11 @@ func (t *Transaction) GetClient(ctx context.Context) (*client.Client, error) {
 /*HashData - data used to hash the transaction */
 func (t *Transaction) HashData() string {
 	hashdata := common.TimeToString(t.CreationDate) + ":" + t.ClientID + ":" + t.ToClientID + ":" + strconv.FormatInt(t.Value, 10) + ":" + encryption.Hash(t.TransactionData)
+	// the nonce is signed only when it's set to keep the transactions of the
+	// clients that don't use nonces valid
+	if t.Nonce != 0 {
+		hashdata += ":" + strconv.FormatInt(t.Nonce, 10)
+	}
 	return hashdata
 }
 
@@ -352,8 +377,13 @@ func (t *Transaction) DebugTxn() bool {
 	return strings.Index(t.TransactionData, "debug") >= 0
 }
 
-/*ComputeOutputHash - compute the hash from the transaction output */
+/*ComputeOutputHash - compute the hash from the transaction output and the
+events emitted */
 func (t *Transaction) ComputeOutputHash() string {
+	if len(t.Events) > 0 {
+		return encryption.Hash(t.TransactionOutput + ":" +
+			eventsHashData(t.Events))
+	}
 	if t.TransactionOutput == "" {
 		return encryption.EmptyHash
 	}
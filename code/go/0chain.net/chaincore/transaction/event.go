@@ -0,0 +1,27 @@
+package transaction
+
+import (
+	"encoding/json"
+)
+
+// Event - a structured event emitted by a smart contract executing the
+// transaction, recorded with the transaction output.
+type Event struct {
+	Type       string            `json:"type" msgpack:"t"`
+	Attributes map[string]string `json:"attributes,omitempty" msgpack:"a,omitempty"`
+}
+
+// NewEvent - create a new event of the given type.
+func NewEvent(eventType string, attributes map[string]string) *Event {
+	return &Event{Type: eventType, Attributes: attributes}
+}
+
+// eventsHashData - deterministic (JSON, sorted attribute keys) representation
+// of the events for the output hash.
+func eventsHashData(events []*Event) string {
+	var buf, err = json.Marshal(events)
+	if err != nil {
+		return "" // never happens, the events are strings only
+	}
+	return string(buf)
+}
@@ -0,0 +1,57 @@
+package transaction
+
+import (
+	"testing"
+
+	"0chain.net/core/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_ComputeOutputHash(t *testing.T) {
+	var txn Transaction
+	assert.Equal(t, encryption.EmptyHash, txn.ComputeOutputHash())
+
+	txn.TransactionOutput = "output"
+	var noEvents = txn.ComputeOutputHash()
+	assert.Equal(t, encryption.Hash("output"), noEvents)
+
+	// the events are committed to by the output hash
+	txn.Events = []*Event{NewEvent("created", map[string]string{
+		"a": "1", "b": "2", "c": "3",
+	})}
+	var withEvents = txn.ComputeOutputHash()
+	assert.NotEqual(t, noEvents, withEvents)
+
+	// regardless the order of the attributes
+	for i := 0; i < 10; i++ {
+		txn.Events = []*Event{NewEvent("created", map[string]string{
+			"c": "3", "b": "2", "a": "1",
+		})}
+		assert.Equal(t, withEvents, txn.ComputeOutputHash())
+	}
+
+	txn.Events[0].Attributes["a"] = "0"
+	assert.NotEqual(t, withEvents, txn.ComputeOutputHash())
+}
+
+func TestTransaction_GetEvents(t *testing.T) {
+	SetupTxnEventEntity(nil)
+
+	var txn = Transaction{Events: []*Event{
+		NewEvent("created", map[string]string{"a": "1"}),
+		NewEvent("updated", map[string]string{"b": "2"}),
+	}}
+	txn.Hash = "hash"
+
+	var round int64 = 3*EventRoundBucketSize + 5
+	var events = txn.GetEvents(round)
+	assert.Len(t, events, 2)
+	for i, event := range events {
+		assert.Equal(t, txn.Events[i].Type, event.Type)
+		assert.Equal(t, round, event.Round)
+		assert.Equal(t, int64(3), event.RoundBucket)
+		assert.Equal(t, "hash", event.TxnHash)
+		assert.Equal(t, i, event.Index)
+	}
+	assert.Equal(t, int64(2), GetEventRoundBucket(3*EventRoundBucketSize-1))
+}
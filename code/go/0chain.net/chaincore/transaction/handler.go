@@ -43,12 +43,18 @@ func PutTransaction(ctx context.Context, entity datastore.Entity) (interface{},
 	if err != nil || cli == nil  || cli.PublicKey == "" {
 		return nil, common.NewError("put transaction error", fmt.Sprintf("client %v doesn't exist, please register", txn.ClientID))
 	}
+	if err = admitToMempool(ctx, txn); err != nil {
+		Logger.Info("put transaction - rejected by mempool",
+			zap.String("txn", txn.Hash), zap.Error(err))
+		return nil, err
+	}
 	if datastore.DoAsync(ctx, txn) {
 		IncTransactionCount()
 		return txn, nil
 	}
 	err = entity.GetEntityMetadata().GetStore().Write(ctx, txn)
 	if err != nil {
+		mempool.remove(txn.ClientID, txn.Hash)
 		Logger.Info("put transaction", zap.Any("error", err), zap.Any("txn", txn.Hash), zap.Any("txn_obj", datastore.ToJSON(txn).String()))
 		return nil, err
 	}
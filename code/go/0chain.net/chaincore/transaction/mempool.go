@@ -0,0 +1,318 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	. "0chain.net/core/logging"
+	"0chain.net/core/memorystore"
+	"go.uber.org/zap"
+)
+
+// txnBaseSize is approximate size of the fields of a transaction other than
+// its data (hashes, keys, signature, value, fee and so on), used to compute
+// the fee per byte.
+const txnBaseSize = 256
+
+// exemptedTxnScore is the collection score of the transactions exempted from
+// fees (node registration, DKG, health checks) when the fees are enabled. It's
+// far above any fee based score, so the system transactions go first.
+const exemptedTxnScore = 1 << 52
+
+var (
+	// ErrMempoolFull is returned when the pool is full and the transaction
+	// pays less per byte than the cheapest pending one.
+	ErrMempoolFull = common.NewError("mempool_full",
+		"mempool is full and the transaction fee is too low to evict others")
+	// ErrClientCapReached is returned when the client has too many pending
+	// transactions.
+	ErrClientCapReached = common.NewError("mempool_client_cap",
+		"too many pending transactions of the client")
+	// ErrReplacementUnderpriced is returned when a transaction doesn't pay
+	// enough to replace a pending one with the same nonce.
+	ErrReplacementUnderpriced = common.NewError("replacement_underpriced",
+		"fee is too low to replace the pending transaction with the same nonce")
+)
+
+var (
+	mempoolMaxSize        int64 // zero for unlimited
+	mempoolClientCap      int   // zero for unlimited
+	mempoolReplaceFeeBump int64 // percents
+)
+
+// SetMempoolLimits - set the admission policy of the transactions pool: the
+// max number of pending transactions, the max number of pending transactions
+// of a client and the min fee increase (in percents) required to replace a
+// pending transaction with the same nonce.
+func SetMempoolLimits(maxSize int64, clientCap int, replaceFeeBump int64) {
+	mempoolMaxSize = maxSize
+	mempoolClientCap = clientCap
+	mempoolReplaceFeeBump = replaceFeeBump
+}
+
+// Size - approximate size of the transaction in bytes.
+func (t *Transaction) Size() int64 {
+	return int64(txnBaseSize + len(t.TransactionData))
+}
+
+// GetFeePerKB - fee of the transaction per 1024 bytes of its size.
+func (t *Transaction) GetFeePerKB() int64 {
+	return int64(float64(t.Fee) * 1024 / float64(t.Size()))
+}
+
+// GetDemotedCollectionScore - score of a transaction included in a generated
+// block, so that it doesn't show up at the top when the next blocks are
+// generated while the block is not finalized yet.
+func (t *Transaction) GetDemotedCollectionScore() int64 {
+	var score = t.GetCollectionScore()
+	if t.GetScore() == 0 {
+		return score - 10*60 // time based score
+	}
+	if score > 0 {
+		return -score
+	}
+	return score
+}
+
+// pendingTxn is a transaction accepted to the pool and not finalized yet.
+type pendingTxn struct {
+	hash         datastore.Key
+	nonce        int64
+	fee          int64
+	creationDate common.Timestamp
+}
+
+// mempoolIndex keeps track of the pending transactions of clients to apply
+// the per client cap and the replace-by-fee policy.
+type mempoolIndex struct {
+	mutex   sync.Mutex
+	clients map[datastore.Key]map[datastore.Key]*pendingTxn
+}
+
+func newMempoolIndex() *mempoolIndex {
+	return &mempoolIndex{
+		clients: make(map[datastore.Key]map[datastore.Key]*pendingTxn),
+	}
+}
+
+var mempool = newMempoolIndex()
+
+// put adds the transaction to the index, if it's allowed by the policy, and
+// returns hash of the pending transaction it replaces, if any.
+func (mi *mempoolIndex) put(t *Transaction, now common.Timestamp) (
+	replaced datastore.Key, err error) {
+
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	var ctxns, ok = mi.clients[t.ClientID]
+	if !ok {
+		ctxns = make(map[datastore.Key]*pendingTxn)
+		mi.clients[t.ClientID] = ctxns
+	}
+
+	var pending = 0
+	for hash, pt := range ctxns {
+		// expired transactions can't make it into a block anymore
+		if !common.WithinTime(int64(now), int64(pt.creationDate),
+			TXN_TIME_TOLERANCE) {
+			delete(ctxns, hash)
+			continue
+		}
+		if hash == t.Hash {
+			return "", common.NewError("duplicate_entity",
+				fmt.Sprintf("txn with key %v already exists", t.Hash))
+		}
+		if t.Nonce != 0 && pt.nonce == t.Nonce {
+			if t.Fee <= pt.fee ||
+				t.Fee*100 < pt.fee*(100+mempoolReplaceFeeBump) {
+				return "", ErrReplacementUnderpriced
+			}
+			replaced = hash
+			continue
+		}
+		pending++
+	}
+
+	if replaced == "" && mempoolClientCap > 0 && pending >= mempoolClientCap {
+		return "", ErrClientCapReached
+	}
+
+	if replaced != "" {
+		delete(ctxns, replaced)
+	}
+	ctxns[t.Hash] = &pendingTxn{
+		hash:         t.Hash,
+		nonce:        t.Nonce,
+		fee:          t.Fee,
+		creationDate: t.CreationDate,
+	}
+	return
+}
+
+// add the transaction to the index regardless of the policy, to restore
+// the index of the transactions accepted to the pool before.
+func (mi *mempoolIndex) add(t *Transaction) {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	var ctxns, ok = mi.clients[t.ClientID]
+	if !ok {
+		ctxns = make(map[datastore.Key]*pendingTxn)
+		mi.clients[t.ClientID] = ctxns
+	}
+	ctxns[t.Hash] = &pendingTxn{
+		hash:         t.Hash,
+		nonce:        t.Nonce,
+		fee:          t.Fee,
+		creationDate: t.CreationDate,
+	}
+}
+
+// remove the transaction from the index.
+func (mi *mempoolIndex) remove(clientID, hash datastore.Key) {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	var ctxns, ok = mi.clients[clientID]
+	if !ok {
+		return
+	}
+	delete(ctxns, hash)
+	if len(ctxns) == 0 {
+		delete(mi.clients, clientID)
+	}
+}
+
+// prune removes the expired transactions from the index, including the
+// ones deleted from the pool without RemoveFromMempool.
+func (mi *mempoolIndex) prune(now common.Timestamp) {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	for clientID, ctxns := range mi.clients {
+		for hash, pt := range ctxns {
+			if !common.WithinTime(int64(now), int64(pt.creationDate),
+				TXN_TIME_TOLERANCE) {
+				delete(ctxns, hash)
+			}
+		}
+		if len(ctxns) == 0 {
+			delete(mi.clients, clientID)
+		}
+	}
+}
+
+// RemoveFromMempool - remove the transactions deleted from the pool (e.g.
+// finalized or invalid ones) from the admission policy tracking.
+func RemoveFromMempool(txns []datastore.Entity) {
+	for _, entity := range txns {
+		if txn, ok := entity.(*Transaction); ok {
+			mempool.remove(txn.ClientID, txn.Hash)
+		}
+	}
+}
+
+// rebuildMempool restores the index of the pending transactions from the
+// pool, which outlives restarts of the node.
+func rebuildMempool(ctx context.Context, mstore *memorystore.Store,
+	collectionName string) error {
+
+	var handler = func(ctx context.Context, qe datastore.CollectionEntity) bool {
+		// a transaction missing in the store has no client
+		if txn, ok := qe.(*Transaction); ok && txn.ClientID != "" {
+			mempool.add(txn)
+		}
+		return true
+	}
+	err := mstore.IterateCollectionAsc(ctx, transactionEntityMetadata,
+		collectionName, handler)
+	if err != nil {
+		return err
+	}
+	mempool.prune(common.Now())
+	return nil
+}
+
+// admitToMempool applies the admission policy to the transaction, deleting
+// the pending transaction it replaces and the cheapest pending transaction
+// if the pool is full.
+func admitToMempool(ctx context.Context, txn *Transaction) error {
+	replaced, err := mempool.put(txn, common.Now())
+	if err != nil {
+		return err
+	}
+	if replaced != "" {
+		if err = deletePendingTxn(ctx, replaced); err != nil {
+			Logger.Error("mempool - delete replaced transaction",
+				zap.String("txn", txn.Hash), zap.String("replaced", replaced),
+				zap.Error(err))
+		}
+		return nil // the pool size is not changed
+	}
+	if err = evictUnderpriced(ctx, txn); err != nil {
+		mempool.remove(txn.ClientID, txn.Hash)
+		return err
+	}
+	return nil
+}
+
+// evictUnderpriced deletes the cheapest pending transaction when the pool is
+// full, if the given transaction pays more. Transactions included in
+// generated blocks have demoted (negative) scores and are never evicted.
+// When the fees are disabled the scores are time based, not prices, and a
+// full pool rejects new transactions keeping the older ones.
+func evictUnderpriced(ctx context.Context, txn *Transaction) error {
+	if mempoolMaxSize <= 0 {
+		return nil
+	}
+	var (
+		emd        = txn.GetEntityMetadata()
+		collection = txn.GetCollectionName()
+	)
+	if emd.GetStore().GetCollectionSize(ctx, emd, collection) < mempoolMaxSize {
+		return nil
+	}
+	if txn.GetScore() == 0 {
+		return ErrMempoolFull // fees disabled
+	}
+
+	con := memorystore.GetEntityCon(ctx, emd)
+	con.Send("ZRANGEBYSCORE", collection, 1, "+inf", "WITHSCORES",
+		"LIMIT", 0, 1)
+	con.Flush()
+	data, err := con.Receive()
+	if err != nil {
+		return err
+	}
+	lowest, ok := data.([]interface{})
+	if !ok || len(lowest) != 2 {
+		return ErrMempoolFull
+	}
+	scoreData, _ := lowest[1].([]byte)
+	score, err := strconv.ParseInt(string(scoreData), 10, 63)
+	if err != nil {
+		return ErrMempoolFull
+	}
+	if txn.GetScore() <= score {
+		return ErrMempoolFull
+	}
+	return deletePendingTxn(ctx, datastore.ToKey(lowest[0]))
+}
+
+// deletePendingTxn deletes the transaction from the pool.
+func deletePendingTxn(ctx context.Context, hash datastore.Key) error {
+	var (
+		emd = transactionEntityMetadata
+		txn = emd.Instance().(*Transaction)
+	)
+	if err := emd.GetStore().Read(ctx, hash, txn); err == nil {
+		mempool.remove(txn.ClientID, hash)
+	}
+	txn.SetKey(hash)
+	return emd.GetStore().Delete(ctx, txn)
+}
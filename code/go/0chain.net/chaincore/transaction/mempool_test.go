@@ -0,0 +1,234 @@
+package transaction
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/config"
+	"0chain.net/chaincore/node"
+	"0chain.net/core/common"
+	"0chain.net/core/memorystore"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPendingTxn(hash, clientID string, nonce, fee int64,
+	now common.Timestamp) *Transaction {
+
+	var t = &Transaction{}
+	t.Hash = hash
+	t.ClientID = clientID
+	t.Nonce = nonce
+	t.Fee = fee
+	t.CreationDate = now
+	return t
+}
+
+func Test_mempoolIndex(t *testing.T) {
+	SetTxnTimeout(30)
+	SetMempoolLimits(0, 2, 10)
+	defer SetMempoolLimits(0, 0, 0)
+
+	var (
+		mi  = newMempoolIndex()
+		now = common.Now()
+	)
+
+	replaced, err := mi.put(newPendingTxn("h1", "c1", 1, 100, now), now)
+	require.NoError(t, err)
+	assert.Zero(t, replaced)
+
+	// duplicate
+	_, err = mi.put(newPendingTxn("h1", "c1", 1, 100, now), now)
+	assert.Error(t, err)
+
+	// replace-by-fee, not enough
+	_, err = mi.put(newPendingTxn("h2", "c1", 1, 105, now), now)
+	assert.Equal(t, ErrReplacementUnderpriced, err)
+
+	// replace-by-fee
+	replaced, err = mi.put(newPendingTxn("h3", "c1", 1, 110, now), now)
+	require.NoError(t, err)
+	assert.EqualValues(t, "h1", replaced)
+
+	_, err = mi.put(newPendingTxn("h4", "c1", 2, 100, now), now)
+	require.NoError(t, err)
+
+	// client cap
+	_, err = mi.put(newPendingTxn("h5", "c1", 3, 100, now), now)
+	assert.Equal(t, ErrClientCapReached, err)
+
+	// other client is not affected
+	_, err = mi.put(newPendingTxn("h6", "c2", 1, 100, now), now)
+	require.NoError(t, err)
+
+	// finalized transaction frees a slot
+	mi.remove("c1", "h3")
+	_, err = mi.put(newPendingTxn("h5", "c1", 3, 100, now), now)
+	require.NoError(t, err)
+
+	// expired transactions don't count
+	var later = now + 60
+	_, err = mi.put(newPendingTxn("h7", "c1", 4, 100, later), later)
+	require.NoError(t, err)
+	_, err = mi.put(newPendingTxn("h8", "c1", 5, 100, later), later)
+	require.NoError(t, err)
+}
+
+func Test_mempoolIndex_prune(t *testing.T) {
+	SetTxnTimeout(30)
+
+	var (
+		mi  = newMempoolIndex()
+		now = common.Now()
+	)
+	_, err := mi.put(newPendingTxn("h1", "c1", 1, 100, now), now)
+	require.NoError(t, err)
+	_, err = mi.put(newPendingTxn("h2", "c2", 1, 100, now+60), now+60)
+	require.NoError(t, err)
+
+	mi.prune(now + 60)
+	assert.NotContains(t, mi.clients, "c1")
+	assert.Contains(t, mi.clients, "c2")
+}
+
+// newTestMempoolStore sets up the transactions store in a miniredis
+func newTestMempoolStore(t *testing.T) (s *miniredis.Miniredis) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	memorystore.AddPool("txndb", &redis.Pool{
+		Dial: func() (redis.Conn, error) { return redis.Dial("tcp", s.Addr()) },
+	})
+	common.SetupRootContext(node.GetNodeContext())
+	SetupEntity(memorystore.GetStorageProvider())
+	SetTxnTimeout(30)
+	return
+}
+
+func Test_evictUnderpriced(t *testing.T) {
+	var s = newTestMempoolStore(t)
+	defer s.Close()
+
+	config.DevConfiguration.IsFeeEnabled = true
+	defer func() { config.DevConfiguration.IsFeeEnabled = false }()
+	SetMempoolLimits(2, 0, 0)
+	defer SetMempoolLimits(0, 0, 0)
+
+	var ctx = memorystore.WithEntityConnection(common.GetRootContext(),
+		transactionEntityMetadata)
+	defer memorystore.Close(ctx)
+
+	var now = common.Now()
+	var write = func(hash string, fee int64, demoted bool) {
+		var txn = newPendingTxn(hash, "client_"+hash, 0, fee, now)
+		txn.ComputeProperties()
+		txn.SetCollectionScore(txn.GetScore())
+		if demoted {
+			txn.SetCollectionScore(txn.GetDemotedCollectionScore())
+		}
+		require.NoError(t, txn.Write(ctx))
+	}
+	var has = func(hash string) bool {
+		var txn = Provider().(*Transaction)
+		return txn.Read(ctx, hash) == nil
+	}
+
+	// the cheapest pending transaction is in a generated block
+	write("in_block", 10, true)
+	write("cheap", 100, false)
+
+	var txn = newPendingTxn("rich", "client_rich", 0, 1000, now)
+	txn.ComputeProperties()
+	require.NoError(t, evictUnderpriced(ctx, txn))
+	assert.True(t, has("in_block"))
+	assert.False(t, has("cheap"))
+
+	// only transactions in blocks left, nothing to evict
+	write("in_block_2", 10, true)
+	assert.Equal(t, ErrMempoolFull, evictUnderpriced(ctx, txn))
+
+	// the fees are disabled, a full pool rejects new transactions
+	config.DevConfiguration.IsFeeEnabled = false
+	assert.Equal(t, ErrMempoolFull, evictUnderpriced(ctx, txn))
+	assert.True(t, has("in_block_2"))
+}
+
+func Test_rebuildMempool(t *testing.T) {
+	var s = newTestMempoolStore(t)
+	defer s.Close()
+	SetMempoolLimits(0, 1, 0)
+	defer SetMempoolLimits(0, 0, 0)
+
+	var ctx = memorystore.WithEntityConnection(common.GetRootContext(),
+		transactionEntityMetadata)
+	defer memorystore.Close(ctx)
+
+	var now = common.Now()
+	for _, txn := range []*Transaction{
+		newPendingTxn("h1", "c1", 1, 100, now),
+		newPendingTxn("h2", "c2", 1, 100, now),
+		newPendingTxn("expired", "c3", 1, 100, now-60),
+	} {
+		txn.ComputeProperties()
+		require.NoError(t, txn.Write(ctx))
+	}
+
+	mempool = newMempoolIndex()
+	defer func() { mempool = newMempoolIndex() }()
+	var mstore = transactionEntityMetadata.GetStore().(*memorystore.Store)
+	require.NoError(t, rebuildMempool(ctx, mstore,
+		Provider().(*Transaction).GetCollectionName()))
+	assert.Contains(t, mempool.clients["c1"], "h1")
+	assert.Contains(t, mempool.clients["c2"], "h2")
+	assert.NotContains(t, mempool.clients, "c3")
+
+	// the client cap applies to the transactions accepted before
+	_, err := mempool.put(newPendingTxn("h3", "c1", 2, 100, now), now)
+	assert.Equal(t, ErrClientCapReached, err)
+}
+
+func TestTrimCollection(t *testing.T) {
+	var s = newTestMempoolStore(t)
+	defer s.Close()
+
+	config.DevConfiguration.IsFeeEnabled = true
+	defer func() { config.DevConfiguration.IsFeeEnabled = false }()
+
+	var ctx = memorystore.WithEntityConnection(common.GetRootContext(),
+		transactionEntityMetadata)
+	defer memorystore.Close(ctx)
+
+	var (
+		now        = common.Now()
+		collection = Provider().(*Transaction).GetCollectionName()
+		con        = memorystore.GetEntityCon(ctx, transactionEntityMetadata)
+	)
+	var write = func(hash string, fee int64, demoted bool) {
+		var txn = newPendingTxn(hash, "client_"+hash, 0, fee, now)
+		txn.ComputeProperties()
+		txn.SetCollectionScore(txn.GetScore())
+		if demoted {
+			txn.SetCollectionScore(txn.GetDemotedCollectionScore())
+		}
+		require.NoError(t, txn.Write(ctx))
+	}
+	var members = func() []string {
+		keys, err := redis.Strings(con.Do("ZRANGE", collection, 0, -1))
+		require.NoError(t, err)
+		return keys
+	}
+
+	write("in_block", 10, true)
+	write("cheap", 100, false)
+	write("rich", 1000, false)
+	write("free", 0, false)
+	require.NoError(t, memorystore.TrimCollection(con, collection, 4))
+	assert.Len(t, members(), 4)
+
+	// the cheapest transactions go first, the ones in blocks are kept
+	require.NoError(t, memorystore.TrimCollection(con, collection, 2))
+	assert.Equal(t, []string{"in_block", "rich"}, members())
+	require.NoError(t, memorystore.TrimCollection(con, collection, 0))
+	assert.Equal(t, []string{"in_block"}, members())
+}
@@ -0,0 +1,107 @@
+package transaction
+
+import (
+	"context"
+
+	"0chain.net/core/datastore"
+)
+
+/*EventRoundBucketSize - number of rounds of a partition of the persisted
+events of a type */
+const EventRoundBucketSize = 10000
+
+/*GetEventRoundBucket - the partition of the events of the given round */
+func GetEventRoundBucket(round int64) int64 {
+	return round / EventRoundBucketSize
+}
+
+/*TransactionEvent - an event emitted by a transaction of a finalized block,
+sharders persist the events to be queried by type, partitioned by buckets of
+rounds */
+type TransactionEvent struct {
+	Type        string            `json:"type"`
+	RoundBucket int64             `json:"round_bucket"`
+	Round       int64             `json:"round"`
+	TxnHash     string            `json:"txn_hash"`
+	Index       int               `json:"idx"` // index of the event of the transaction
+	Attributes  map[string]string `json:"attributes"`
+}
+
+var transactionEventEntityMetadata *datastore.EntityMetadataImpl
+
+//TransactionEventProvider - factory method
+func TransactionEventProvider() datastore.Entity {
+	return &TransactionEvent{}
+}
+
+/*GetEvents - get the events of the transaction of a block of the given
+round, to be persisted */
+func (t *Transaction) GetEvents(round int64) []*TransactionEvent {
+	var events = make([]*TransactionEvent, 0, len(t.Events))
+	for i, event := range t.Events {
+		te := datastore.GetEntityMetadata("txn_event").Instance().(*TransactionEvent)
+		te.Type = event.Type
+		te.RoundBucket = GetEventRoundBucket(round)
+		te.Round = round
+		te.TxnHash = t.Hash
+		te.Index = i
+		te.Attributes = event.Attributes
+		events = append(events, te)
+	}
+	return events
+}
+
+//GetEntityMetadata - implement interface
+func (te *TransactionEvent) GetEntityMetadata() datastore.EntityMetadata {
+	return transactionEventEntityMetadata
+}
+
+//GetKey - implement interface
+func (te *TransactionEvent) GetKey() datastore.Key {
+	return datastore.ToKey(te.Type)
+}
+
+//SetKey - implement interface
+func (te *TransactionEvent) SetKey(key datastore.Key) {
+	te.Type = datastore.ToString(key)
+}
+
+/*ComputeProperties - implement interface */
+func (te *TransactionEvent) ComputeProperties() {
+
+}
+
+//Validate - implement entity interface
+func (te *TransactionEvent) Validate(ctx context.Context) error {
+	return nil
+}
+
+/*Read - store read */
+func (te *TransactionEvent) Read(ctx context.Context, key datastore.Key) error {
+	return te.GetEntityMetadata().GetStore().Read(ctx, key, te)
+}
+
+/*GetScore - score for write*/
+func (te *TransactionEvent) GetScore() int64 {
+	return te.Round
+}
+
+/*Write - store read */
+func (te *TransactionEvent) Write(ctx context.Context) error {
+	return te.GetEntityMetadata().GetStore().Write(ctx, te)
+}
+
+/*Delete - store read */
+func (te *TransactionEvent) Delete(ctx context.Context) error {
+	return te.GetEntityMetadata().GetStore().Delete(ctx, te)
+}
+
+/*SetupTxnEventEntity - setup the txn event entity */
+func SetupTxnEventEntity(store datastore.Store) {
+	transactionEventEntityMetadata = datastore.MetadataProvider()
+	transactionEventEntityMetadata.Name = "txn_event"
+	transactionEventEntityMetadata.Provider = TransactionEventProvider
+	transactionEventEntityMetadata.Store = store
+	transactionEventEntityMetadata.IDColumnName = "type"
+	datastore.RegisterEntityMetadata("txn_event", transactionEventEntityMetadata)
+}
@@ -12,4 +12,6 @@ const (
 	TxnTypeData = 10 // A transaction to just store a piece of data on the block chain
 
 	TxnTypeSmartContract = 1000 // A smart contract transaction type
+
+	TxnTypeSmartContractBatch = 1002 // A list of smart contract calls executed atomically
 )
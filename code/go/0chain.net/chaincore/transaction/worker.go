@@ -31,6 +31,10 @@ func CleanupWorker(ctx context.Context) {
 	txn := transactionEntityMetadata.Instance().(*Transaction)
 	collectionName := txn.GetCollectionName()
 
+	if err := rebuildMempool(cctx, mstore, collectionName); err != nil {
+		Logger.Error("Error in rebuilding mempool index", zap.Error(err))
+	}
+
 	var handler = func(ctx context.Context, qe datastore.CollectionEntity) bool {
 		txn, ok := qe.(*Transaction)
 		if !ok {
@@ -65,6 +69,7 @@ func CleanupWorker(ctx context.Context) {
 				if err != nil {
 					Logger.Error("Error in MultiDelete", zap.Error(err))
 				} else {
+					RemoveFromMempool(invalidTxns)
 					invalidTxns = invalidTxns[:0]
 				}
 			}
@@ -77,6 +82,7 @@ func CleanupWorker(ctx context.Context) {
 					invalidHashes = invalidHashes[:0]
 				}
 			}
+			mempool.prune(common.Now())
 		}
 	}
 }
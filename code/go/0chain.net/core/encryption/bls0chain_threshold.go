@@ -1,6 +1,8 @@
 package encryption
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 
 	"github.com/herumi/bls/ffi/go/bls"
@@ -70,6 +72,77 @@ func BLS0GenerateThresholdKeyShares(t, n int, originalKey SignatureScheme) ([]Th
 	return shares, nil
 }
 
+//ErrInvalidThresholdKeyShares - the public keys are not shares of the group key
+var ErrInvalidThresholdKeyShares = errors.New("public keys are not threshold shares of the group key")
+
+//BLS0VerifyThresholdKeyShares - check the public keys of the ids are T-of-N shares of the group public key
+func BLS0VerifyThresholdKeyShares(t int, groupPublicKey string, ids, publicKeys []string) error {
+	if t < 1 || len(ids) != len(publicKeys) || len(ids) < t {
+		return ErrInvalidThresholdKeyShares
+	}
+
+	var group bls.PublicKey
+	b, err := hex.DecodeString(groupPublicKey)
+	if err != nil {
+		return err
+	}
+	if err = group.Deserialize(b); err != nil {
+		return err
+	}
+
+	var (
+		zero   bls.ID
+		blsIDs = make([]bls.ID, len(ids))
+		pks    = make([]bls.PublicKey, len(publicKeys))
+	)
+	for i := range ids {
+		if err = blsIDs[i].SetHexString(ids[i]); err != nil {
+			return err
+		}
+		// share of the zero id is the group key itself
+		if blsIDs[i].IsEqual(&zero) {
+			return ErrInvalidThresholdKeyShares
+		}
+		if b, err = hex.DecodeString(publicKeys[i]); err != nil {
+			return err
+		}
+		if err = pks[i].Deserialize(b); err != nil {
+			return err
+		}
+	}
+
+	recovers := func(idx []int) bool {
+		var (
+			subIDs = make([]bls.ID, 0, len(idx))
+			subPKs = make([]bls.PublicKey, 0, len(idx))
+			pk     bls.PublicKey
+		)
+		for _, i := range idx {
+			subIDs = append(subIDs, blsIDs[i])
+			subPKs = append(subPKs, pks[i])
+		}
+		return pk.Recover(subPKs, subIDs) == nil && pk.IsEqual(&group)
+	}
+
+	// The first t shares recover the group key, so they are on a polynomial
+	// of degree t-1 through it. Every other share, with t-1 of the first
+	// ones, recovers the group key only if it's on the same polynomial.
+	base := make([]int, t)
+	for i := range base {
+		base[i] = i
+	}
+	if !recovers(base) {
+		return ErrInvalidThresholdKeyShares
+	}
+	for i := t; i < len(ids); i++ {
+		if !recovers(append(base[1:t:t], i)) {
+			return ErrInvalidThresholdKeyShares
+		}
+	}
+
+	return nil
+}
+
 //NewBLS0ChainReconstruction - create a new instance
 func NewBLS0ChainReconstruction(t, n int) *BLS0ChainReconstruction {
 	return &BLS0ChainReconstruction{
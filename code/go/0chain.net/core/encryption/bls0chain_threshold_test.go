@@ -59,3 +59,61 @@ func TestThresholdSignatures(t *testing.T) {
 		t.Error("Reconstructed signature did not verify")
 	}
 }
+
+func TestVerifyThresholdKeyShares(t *testing.T) {
+	T := 3
+	N := 5
+	scheme := "bls0chain"
+
+	groupKey := GetSignatureScheme(scheme)
+	if err := groupKey.GenerateKeys(); err != nil {
+		t.Fatal(err)
+	}
+	otherKey := GetSignatureScheme(scheme)
+	if err := otherKey.GenerateKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	split := func(key SignatureScheme, th, n int) (ids, pks []string) {
+		shares, err := GenerateThresholdKeyShares(scheme, th, n, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, share := range shares {
+			ids = append(ids, share.GetID())
+			pks = append(pks, share.GetPublicKey())
+		}
+		return
+	}
+
+	group := groupKey.GetPublicKey()
+	ids, pks := split(groupKey, T, N)
+	if err := VerifyThresholdKeyShares(scheme, T, group, ids, pks); err != nil {
+		t.Errorf("valid shares: %v", err)
+	}
+	if err := VerifyThresholdKeyShares(scheme, T-1, group, ids, pks); err == nil {
+		t.Error("shares don't recover the group key with less than T of them")
+	}
+
+	// a share of another key
+	_, otherPKs := split(otherKey, T, N)
+	pks[N-1] = otherPKs[N-1]
+	if err := VerifyThresholdKeyShares(scheme, T, group, ids, pks); err == nil {
+		t.Error("share of another key accepted")
+	}
+	pks[0] = otherPKs[0]
+	if err := VerifyThresholdKeyShares(scheme, T, group, ids, pks); err == nil {
+		t.Error("share of another key accepted")
+	}
+
+	// the group key itself with the zero id
+	ids, pks = split(groupKey, T, N)
+	ids[0], pks[0] = "0", group
+	if err := VerifyThresholdKeyShares(scheme, T, group, ids, pks); err == nil {
+		t.Error("zero id accepted")
+	}
+
+	if err := VerifyThresholdKeyShares("ed25519", T, group, ids, pks); err == nil {
+		t.Error("ed25519 has no threshold shares")
+	}
+}
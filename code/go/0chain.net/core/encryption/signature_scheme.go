@@ -126,6 +126,16 @@ func GenerateThresholdKeyShares(sigScheme string, t, n int, originalKey Signatur
 	}
 }
 
+//VerifyThresholdKeyShares - check the public keys of the ids are T-of-N shares of the group public key
+func VerifyThresholdKeyShares(sigScheme string, t int, groupPublicKey string, ids, publicKeys []string) error {
+	switch sigScheme {
+	case "bls0chain":
+		return BLS0VerifyThresholdKeyShares(t, groupPublicKey, ids, publicKeys)
+	default:
+		return ErrInvalidSignatureScheme
+	}
+}
+
 //IsValidReconstructSignatureScheme - whether a signature reconstruction scheme exists
 func IsValidReconstructSignatureScheme(sigScheme string) bool {
 	switch sigScheme {
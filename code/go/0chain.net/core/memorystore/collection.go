@@ -11,6 +11,7 @@ import (
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	. "0chain.net/core/logging"
+	"github.com/gomodule/redigo/redis"
 	"go.uber.org/zap"
 )
 
@@ -152,22 +153,38 @@ func CollectionTrimmer(entityMetadata datastore.EntityMetadata, collection strin
 		case <-ctx.Done():
 			return
 		case t := <-ticker.C:
-			con.Send("ZCARD", collection)
-			con.Flush()
-			data, err := con.Receive()
-			if err != nil {
+			if err := TrimCollection(con, collection, trimSize); err != nil {
 				Logger.Error("collection trimmer", zap.String("collection", collection), zap.Time("time", t), zap.Error(err))
-				continue
-			}
-			size, ok := data.(int64)
-			if !ok {
-				Logger.Error("collection trimmer", zap.String("collection", collection), zap.Time("time", t), zap.Any("data", data))
-			}
-			if size < trimSize {
-				continue
 			}
-			score := datastore.GetCollectionScore(time.Now().Add(-trimBeyond))
-			con.Send("ZREMRANGEBYSCORE", collection, 0, score)
 		}
 	}
 }
+
+/*TrimCollection - trims the collection down to the given size removing the
+* entities with the lowest scores. The scores are not necessarily time based
+* (e.g. transactions are scored by fees), so the collection is trimmed by
+* rank. Entities with negative scores (e.g. transactions included in generated
+* blocks) are kept.
+ */
+func TrimCollection(con redis.Conn, collection string, trimSize int64) error {
+	size, err := redis.Int64(con.Do("ZCARD", collection))
+	if err != nil {
+		return err
+	}
+	if size <= trimSize {
+		return nil
+	}
+	kept, err := redis.Int64(con.Do("ZCOUNT", collection, "-inf", "(0"))
+	if err != nil {
+		return err
+	}
+	excess := size - trimSize
+	if excess > size-kept {
+		excess = size - kept
+	}
+	if excess <= 0 {
+		return nil
+	}
+	_, err = con.Do("ZREMRANGEBYRANK", collection, kept, kept+excess-1)
+	return err
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"sync"
 
 	"github.com/0chain/gorocksdb"
@@ -31,6 +32,22 @@ const (
 
 var sstType = SSTTypeBlockBasedTable
 
+// versionRootPrefix is the prefix of the keys of the roots of the versions,
+// of the prefix extractor length
+var versionRootPrefix = []byte("roots:")
+
+func versionRootKey(version Sequence) []byte {
+	key := make([]byte, len(versionRootPrefix)+8)
+	copy(key, versionRootPrefix)
+	binary.BigEndian.PutUint64(key[len(versionRootPrefix):], uint64(version))
+	return key
+}
+
+func isVersionRootKey(key []byte) bool {
+	return len(key) == len(versionRootPrefix)+8 &&
+		bytes.HasPrefix(key, versionRootPrefix)
+}
+
 /*NewPNodeDB - create a new PNodeDB */
 func NewPNodeDB(dataDir string, logDir string) (*PNodeDB, error) {
 	opts := gorocksdb.NewDefaultOptions()
@@ -126,6 +143,28 @@ func (pndb *PNodeDB) MultiDeleteNode(keys []Key) error {
 	return pndb.db.Write(pndb.wo, wb)
 }
 
+/*PutVersionRoot - record the root of the trie of the given version, the
+* roots are never iterated as nodes */
+func (pndb *PNodeDB) PutVersionRoot(version Sequence, root Key) error {
+	return pndb.db.Put(pndb.wo, versionRootKey(version), root)
+}
+
+/*GetVersionRoot - get the root of the trie of the given version */
+func (pndb *PNodeDB) GetVersionRoot(version Sequence) (Key, error) {
+	data, err := pndb.db.Get(pndb.ro, versionRootKey(version))
+	if err != nil {
+		return nil, err
+	}
+	defer data.Free()
+	buf := data.Data()
+	if len(buf) == 0 {
+		return nil, ErrNodeNotFound
+	}
+	root := make(Key, len(buf))
+	copy(root, buf)
+	return root, nil
+}
+
 /*Iterate - implement interface */
 func (pndb *PNodeDB) Iterate(ctx context.Context, handler NodeDBIteratorHandler) error {
 	ro := gorocksdb.NewDefaultReadOptions()
@@ -138,6 +177,11 @@ func (pndb *PNodeDB) Iterate(ctx context.Context, handler NodeDBIteratorHandler)
 		value := it.Value()
 		kdata := key.Data()
 		vdata := value.Data()
+		if isVersionRootKey(kdata) {
+			key.Free()
+			value.Free()
+			continue
+		}
 		node, err := CreateNode(bytes.NewReader(vdata))
 		if err != nil {
 			key.Free()
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+//ErrInvalidProof - the proof nodes don't link the root to the value
+var ErrInvalidProof = errors.New("invalid merkle patricia trie proof")
+
+/*MPTProof - serialized nodes on the path from the root of a merkle patricia
+* trie down to the leaf node holding the value of the path. The proof lets
+* verify the value having only the root hash of the trie (e.g. the state hash
+* of a block). */
+type MPTProof struct {
+	Root  Key      `json:"root"`
+	Path  string   `json:"path"`
+	Nodes []string `json:"nodes"` // hex encoded nodes, root first
+}
+
+/*GetMPTProof - get the proof of the value at the given path of the trie */
+func GetMPTProof(mpt MerklePatriciaTrieI, path Path) (*MPTProof, error) {
+	nodes, err := mpt.GetPathNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	proof := &MPTProof{
+		Root:  mpt.GetRoot(),
+		Path:  string(path),
+		Nodes: make([]string, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		proof.Nodes = append(proof.Nodes, hex.EncodeToString(node.Encode()))
+	}
+	return proof, nil
+}
+
+/*Verify - verify the proof against the given root and return the encoded
+* value of the path */
+func (p *MPTProof) Verify(root Key) ([]byte, error) {
+	nodes := make([][]byte, 0, len(p.Nodes))
+	for _, node := range p.Nodes {
+		buf, err := hex.DecodeString(node)
+		if err != nil {
+			return nil, ErrInvalidProof
+		}
+		nodes = append(nodes, buf)
+	}
+	return VerifyMPTProof(root, Path(p.Path), nodes)
+}
+
+// decodeProofNode decodes a node of a proof, which is never trusted.
+func decodeProofNode(buf []byte) (node Node, err error) {
+	// the nodes decoding assumes well formed input
+	defer func() {
+		if r := recover(); r != nil {
+			node, err = nil, ErrInvalidProof
+		}
+	}()
+	if len(buf) == 0 {
+		return nil, ErrInvalidProof
+	}
+	switch buf[0] & NodeTypesAll {
+	case NodeTypeLeafNode, NodeTypeFullNode, NodeTypeExtensionNode:
+	default:
+		return nil, ErrInvalidProof
+	}
+	if node, err = CreateNode(bytes.NewReader(buf)); err != nil {
+		return nil, ErrInvalidProof
+	}
+	return
+}
+
+/*VerifyMPTProof - verify that the serialized nodes, starting from the root,
+* are linked by their hashes down to the leaf node of the path and return the
+* encoded value of the leaf. It doesn't need any access to the trie and so is
+* suitable for light clients. */
+func VerifyMPTProof(root Key, path Path, nodes [][]byte) ([]byte, error) {
+	expected := root
+	for i, buf := range nodes {
+		node, err := decodeProofNode(buf)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(node.GetHashBytes(), expected) {
+			return nil, fmt.Errorf("%w: hash mismatch at node %d",
+				ErrInvalidProof, i)
+		}
+		switch nodeImpl := node.(type) {
+		case *LeafNode:
+			if i != len(nodes)-1 || !bytes.Equal(nodeImpl.Path, path) ||
+				!nodeImpl.HasValue() {
+				return nil, fmt.Errorf("%w: path mismatch at leaf node",
+					ErrInvalidProof)
+			}
+			return nodeImpl.GetValue().Encode(), nil
+		case *FullNode:
+			if len(path) == 0 || bytes.IndexByte(PathElements, path[0]) < 0 {
+				return nil, fmt.Errorf("%w: path mismatch at node %d",
+					ErrInvalidProof, i)
+			}
+			expected = nodeImpl.GetChild(path[0])
+			path = path[1:]
+		case *ExtensionNode:
+			if len(nodeImpl.Path) == 0 ||
+				!bytes.HasPrefix(path, nodeImpl.Path) {
+				return nil, fmt.Errorf("%w: path mismatch at node %d",
+					ErrInvalidProof, i)
+			}
+			expected = nodeImpl.NodeKey
+			path = path[len(nodeImpl.Path):]
+		}
+		if len(expected) == 0 {
+			return nil, fmt.Errorf("%w: missing child of node %d",
+				ErrInvalidProof, i)
+		}
+	}
+	return nil, fmt.Errorf("%w: no leaf node", ErrInvalidProof)
+}
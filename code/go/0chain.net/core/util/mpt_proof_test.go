@@ -0,0 +1,88 @@
+package util
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+
+	"0chain.net/core/encryption"
+)
+
+func TestMPTProof(t *testing.T) {
+	mpt := NewMerklePatriciaTrie(NewMemoryNodeDB(), Sequence(0))
+	var keys []string
+	for i := 0; i < 100; i++ {
+		key := encryption.Hash(strconv.Itoa(i))
+		keys = append(keys, key)
+		doStrValInsert(t, "insert", mpt, key, "value "+strconv.Itoa(i))
+	}
+	root := mpt.GetRoot()
+
+	for i, key := range keys {
+		proof, err := GetMPTProof(mpt, Path(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := proof.Verify(root)
+		if err != nil {
+			t.Fatalf("verifying proof of %s: %v", key, err)
+		}
+		if string(value) != "value "+strconv.Itoa(i) {
+			t.Fatalf("unexpected value: %q", value)
+		}
+	}
+
+	proof, err := GetMPTProof(mpt, Path(keys[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong root
+	if _, err = proof.Verify(Key(encryption.RawHash("root"))); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected invalid proof, got %v", err)
+	}
+
+	// other path
+	other := *proof
+	other.Path = keys[1]
+	if _, err = other.Verify(root); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected invalid proof, got %v", err)
+	}
+
+	// tampered value
+	leaf := NewLeafNode(nil, Sequence(0), &Txn{"forged"})
+	last, _ := hex.DecodeString(proof.Nodes[len(proof.Nodes)-1])
+	orig, err := CreateNode(bytes.NewReader(last))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf.Path = orig.(*LeafNode).Path
+	tampered := *proof
+	tampered.Nodes = append([]string{}, proof.Nodes...)
+	tampered.Nodes[len(proof.Nodes)-1] = hex.EncodeToString(leaf.Encode())
+	if _, err = tampered.Verify(root); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected invalid proof, got %v", err)
+	}
+
+	// truncated and garbage
+	truncated := *proof
+	truncated.Nodes = proof.Nodes[:len(proof.Nodes)-1]
+	if _, err = truncated.Verify(root); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected invalid proof, got %v", err)
+	}
+	if _, err = VerifyMPTProof(root, Path(keys[0]), [][]byte{{0xff}, {}}); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected invalid proof, got %v", err)
+	}
+
+	full := append([]byte{NodeTypeFullNode}, make([]byte, 16)...)
+	full = append(full, []byte("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef00:")...)
+	if _, err = VerifyMPTProof(root, Path(keys[0]), [][]byte{full}); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected invalid proof, got %v", err)
+	}
+
+	if _, err = GetMPTProof(mpt, Path(encryption.Hash("missing"))); err != ErrValueNotPresent {
+		t.Fatalf("expected value not present, got %v", err)
+	}
+}
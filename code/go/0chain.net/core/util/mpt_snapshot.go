@@ -0,0 +1,86 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+)
+
+//ErrInvalidSnapshotChunk - the chunk of a snapshot can't be decoded
+var ErrInvalidSnapshotChunk = errors.New("invalid state snapshot chunk")
+
+//MaxSnapshotNodeSize - max size of an encoded node of a snapshot chunk
+const MaxSnapshotNodeSize = 1 << 24
+
+/*SnapshotMPT - export all the nodes of the trie reachable from its root as
+* chunks of about chunkSize bytes. A chunk is a sequence of encoded nodes, each
+* one prefixed with its length (uvarint). Returns the number of nodes. */
+func SnapshotMPT(ctx context.Context, mpt MerklePatriciaTrieI, chunkSize int,
+	handler func(chunk []byte) error) (count int64, err error) {
+
+	var (
+		chunk  bytes.Buffer
+		prefix = make([]byte, binary.MaxVarintLen64)
+	)
+	visit := func(ctx context.Context, path Path, key Key, node Node) error {
+		if node == nil {
+			return ErrNodeNotFound
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		buf := node.Encode()
+		n := binary.PutUvarint(prefix, uint64(len(buf)))
+		chunk.Write(prefix[:n])
+		chunk.Write(buf)
+		count++
+		if chunk.Len() < chunkSize {
+			return nil
+		}
+		data := make([]byte, chunk.Len())
+		copy(data, chunk.Bytes())
+		chunk.Reset()
+		return handler(data)
+	}
+	err = mpt.Iterate(ctx, visit,
+		NodeTypeLeafNode|NodeTypeFullNode|NodeTypeExtensionNode)
+	if err != nil {
+		return 0, err
+	}
+	if chunk.Len() > 0 {
+		if err = handler(chunk.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+/*DecodeSnapshotChunk - decode the nodes of a chunk of a snapshot, the chunk is
+* never trusted */
+func DecodeSnapshotChunk(chunk []byte) (nodes []Node, err error) {
+	// the nodes decoding assumes well formed input
+	defer func() {
+		if r := recover(); r != nil {
+			nodes, err = nil, ErrInvalidSnapshotChunk
+		}
+	}()
+	r := bytes.NewReader(chunk)
+	for r.Len() > 0 {
+		size, err := binary.ReadUvarint(r)
+		if err != nil || size == 0 || size > MaxSnapshotNodeSize ||
+			size > uint64(r.Len()) {
+			return nil, ErrInvalidSnapshotChunk
+		}
+		buf := make([]byte, size)
+		if _, err = r.Read(buf); err != nil {
+			return nil, ErrInvalidSnapshotChunk
+		}
+		node, err := CreateNode(bytes.NewReader(buf))
+		if err != nil {
+			return nil, ErrInvalidSnapshotChunk
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
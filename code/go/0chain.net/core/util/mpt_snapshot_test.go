@@ -0,0 +1,90 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"0chain.net/core/encryption"
+)
+
+func TestSnapshotMPT(t *testing.T) {
+	mpt := NewMerklePatriciaTrie(NewMemoryNodeDB(), Sequence(0))
+	var keys []string
+	for i := 0; i < 200; i++ {
+		key := encryption.Hash(strconv.Itoa(i))
+		keys = append(keys, key)
+		doStrValInsert(t, "insert", mpt, key, "value "+strconv.Itoa(i))
+	}
+
+	var chunks [][]byte
+	count, err := SnapshotMPT(context.Background(), mpt, 1024,
+		func(chunk []byte) error {
+			chunks = append(chunks, chunk)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+
+	// restore
+	var (
+		mndb  = NewMemoryNodeDB()
+		total int64
+	)
+	for _, chunk := range chunks {
+		nodes, err := DecodeSnapshotChunk(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var nkeys []Key
+		for _, node := range nodes {
+			nkeys = append(nkeys, node.GetHashBytes())
+		}
+		if err = mndb.MultiPutNode(nkeys, nodes); err != nil {
+			t.Fatal(err)
+		}
+		total += int64(len(nodes))
+	}
+	if total != count {
+		t.Fatalf("expected %d nodes, got %d", count, total)
+	}
+	restored := NewMerklePatriciaTrie(mndb, Sequence(0))
+	restored.SetRoot(mpt.GetRoot())
+	if err = IsMPTValid(restored); err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		doGetStrValue(t, restored, key, "value "+strconv.Itoa(i))
+	}
+
+	// missing chunk
+	mndb = NewMemoryNodeDB()
+	nodes, err := DecodeSnapshotChunk(chunks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range nodes {
+		mndb.PutNode(node.GetHashBytes(), node)
+	}
+	partial := NewMerklePatriciaTrie(mndb, Sequence(0))
+	partial.SetRoot(mpt.GetRoot())
+	if err = IsMPTValid(partial); err == nil {
+		t.Fatal("expected invalid trie")
+	}
+
+	// malformed chunks
+	for _, chunk := range [][]byte{
+		{0x05, 0xff},
+		append([]byte{0x02}, 0xee, 0x00),
+		chunks[0][:len(chunks[0])-1],
+	} {
+		if _, err = DecodeSnapshotChunk(chunk); !errors.Is(err, ErrInvalidSnapshotChunk) {
+			t.Fatalf("expected invalid chunk, got %v", err)
+		}
+	}
+}
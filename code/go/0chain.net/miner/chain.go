@@ -16,6 +16,7 @@ import (
 	"0chain.net/chaincore/node"
 	"0chain.net/chaincore/round"
 	"0chain.net/chaincore/threshold/bls"
+	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/memorystore"
@@ -213,6 +214,7 @@ func (mc *Chain) deleteTxns(txns []datastore.Entity) error {
 	transactionMetadataProvider := datastore.GetEntityMetadata("txn")
 	ctx := memorystore.WithEntityConnection(common.GetRootContext(), transactionMetadataProvider)
 	defer memorystore.Close(ctx)
+	transaction.RemoveFromMempool(txns)
 	return transactionMetadataProvider.GetStore().MultiDelete(ctx, transactionMetadataProvider, txns)
 }
 
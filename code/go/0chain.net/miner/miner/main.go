@@ -59,6 +59,10 @@ func main() {
 	config.Configuration.ChainID = viper.GetString("server_chain.id")
 	transaction.SetTxnTimeout(int64(viper.GetInt("server_chain.transaction.timeout")))
 	transaction.SetTxnFee(viper.GetInt64("server_chain.transaction.min_fee"))
+	transaction.SetMempoolLimits(
+		viper.GetInt64("server_chain.transaction.mempool.max_size"),
+		viper.GetInt("server_chain.transaction.mempool.client_cap"),
+		viper.GetInt64("server_chain.transaction.mempool.replace_fee_bump"))
 
 	config.SetServerChainID(config.Configuration.ChainID)
 
@@ -392,6 +396,7 @@ func initEntities() {
 	block.SetupStateChange(memoryStorage)
 	state.SetupPartialState(memoryStorage)
 	state.SetupStateNodes(memoryStorage)
+	state.SetupStateSnapshot(memoryStorage)
 	client.SetupEntity(memoryStorage)
 
 	transaction.SetupTransactionDB()
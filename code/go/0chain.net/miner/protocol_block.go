@@ -3,6 +3,7 @@ package miner
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"0chain.net/chaincore/client"
 	"0chain.net/chaincore/config"
 	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/smartcontractinterface"
 	"0chain.net/chaincore/transaction"
 
 	"0chain.net/core/common"
@@ -20,6 +22,7 @@ import (
 	"0chain.net/core/util"
 
 	"0chain.net/smartcontract/minersc"
+	"0chain.net/smartcontract/storagesc"
 
 	. "0chain.net/core/logging"
 	"go.uber.org/zap"
@@ -64,6 +67,9 @@ func (mc *Chain) processFeeTxn(ctx context.Context, b *block.Block, clients map[
 	return nil
 }
 
+// createFeeTxn creates the protocol transaction paying fees of the block;
+// like other protocol transactions it has no nonce, thus it doesn't take
+// nonces of the miner's own transactions, and it's deduplicated by hash
 func (mc *Chain) createFeeTxn(b *block.Block) *transaction.Transaction {
 	feeTxn := transaction.Provider().(*transaction.Transaction)
 	feeTxn.ClientID = b.MinerID
@@ -76,6 +82,92 @@ func (mc *Chain) createFeeTxn(b *block.Block) *transaction.Transaction {
 	return feeTxn
 }
 
+// isRoundChallengesEnabled is true if storage SC challenges generated every
+// round by the block generator; it's checked out against storage SC
+// configurations in state of the previous block, the same for the generator
+// and verifiers of the block
+func (mc *Chain) isRoundChallengesEnabled(b *block.Block) (bool, error) {
+	if b.PrevBlock == nil {
+		return false, common.NewErrorf("round_challenges_enabled",
+			"missing previous block of %d", b.Round)
+	}
+	var seri, err = mc.GetBlockStateNode(b.PrevBlock, storagesc.ConfigKey())
+	if err == util.ErrValueNotPresent {
+		return false, nil // not configured yet
+	}
+	if err != nil {
+		return false, common.NewErrorf("round_challenges_enabled",
+			"can't get storage SC configurations, round %d: %v", b.Round, err)
+	}
+	var enabled bool
+	if enabled, err = storagesc.RoundChallengesEnabled(seri.Encode()); err != nil {
+		return false, common.NewErrorf("round_challenges_enabled",
+			"can't decode storage SC configurations, round %d: %v", b.Round, err)
+	}
+	return enabled, nil
+}
+
+// isRoundChallengesTxn is true if the transaction is the
+// generate_round_challenges transaction of the block generator.
+func isRoundChallengesTxn(b *block.Block, txn *transaction.Transaction) bool {
+	if txn.TransactionType != transaction.TxnTypeSmartContract ||
+		txn.ToClientID != storagesc.ADDRESS || txn.ClientID != b.MinerID {
+		return false
+	}
+	var data smartcontractinterface.SmartContractTransactionData
+	if err := json.Unmarshal([]byte(txn.TransactionData), &data); err != nil {
+		return false
+	}
+	return data.FunctionName == "generate_round_challenges"
+}
+
+// verifyRoundChallengesTxn checks out the block has the
+// generate_round_challenges transaction if the round challenges are enabled.
+func (mc *Chain) verifyRoundChallengesTxn(b *block.Block) error {
+	var enabled, err = mc.isRoundChallengesEnabled(b)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+	for _, txn := range b.Txns {
+		if isRoundChallengesTxn(b, txn) {
+			return nil
+		}
+	}
+	return common.NewError("verify_round_challenges",
+		"missing generate_round_challenges transaction")
+}
+
+func (mc *Chain) processRoundChallengesTxn(ctx context.Context, b *block.Block,
+	clients map[string]*client.Client) error {
+
+	challTxn := mc.createRoundChallengesTxn(b)
+	clients[challTxn.ClientID] = nil
+	if err := mc.UpdateState(b, challTxn); err != nil {
+		Logger.Error("processRoundChallengesTxn", zap.String("txn", challTxn.Hash),
+			zap.String("txn_object", datastore.ToJSON(challTxn).String()),
+			zap.Error(err))
+		return err
+	}
+	b.Txns = append(b.Txns, challTxn)
+	b.AddTransaction(challTxn)
+	return nil
+}
+
+func (mc *Chain) createRoundChallengesTxn(b *block.Block) *transaction.Transaction {
+	challTxn := transaction.Provider().(*transaction.Transaction)
+	challTxn.ClientID = b.MinerID
+	challTxn.ToClientID = storagesc.ADDRESS
+	challTxn.CreationDate = b.CreationDate
+	challTxn.TransactionType = transaction.TxnTypeSmartContract
+	challTxn.TransactionData = fmt.Sprintf(`{"name":"generate_round_challenges","input":{"round":%v}}`, b.Round)
+	challTxn.Fee = 0
+	challTxn.Sign(node.Self.GetSignatureScheme())
+	return challTxn
+}
+
 func (mc *Chain) txnToReuse(txn *transaction.Transaction) *transaction.Transaction {
 	ctxn := *txn
 	ctxn.OutputHash = ""
@@ -86,6 +178,67 @@ func (mc *Chain) validateTransaction(b *block.Block, txn *transaction.Transactio
 	return common.WithinTime(int64(b.CreationDate), int64(txn.CreationDate), transaction.TXN_TIME_TOLERANCE)
 }
 
+// nonce status of a transaction w.r.t. a block being generated
+const (
+	nonceValid  = iota // the transaction is the next one of its client
+	nonceStale         // the nonce is already used, the transaction is a replay
+	nonceFuture        // previous transactions of the client are missing
+)
+
+// checkTxnNonce checks out nonce of a nonce based transaction against the
+// next expected nonce of its client w.r.t. the given block's state.
+func (mc *Chain) checkTxnNonce(b *block.Block, txn *transaction.Transaction) (
+	int, error) {
+
+	next, err := mc.GetNextNonce(b, txn.ClientID)
+	if err != nil {
+		return nonceValid, err
+	}
+	switch {
+	case txn.Nonce < next:
+		return nonceStale, nil
+	case txn.Nonce > next:
+		return nonceFuture, nil
+	}
+	return nonceValid, nil
+}
+
+// futureTxns holds nonce based transactions met during a block generation
+// whose nonces are ahead of the next expected nonces of their clients. They
+// are included once the missing transactions of the clients are included.
+type futureTxns map[datastore.Key]map[int64]*transaction.Transaction
+
+func (ft futureTxns) add(txn *transaction.Transaction) {
+	var ctxns, ok = ft[txn.ClientID]
+	if !ok {
+		ctxns = make(map[int64]*transaction.Transaction)
+		ft[txn.ClientID] = ctxns
+	}
+	ctxns[txn.Nonce] = txn
+}
+
+// next removes and returns transaction of the same client following the
+// given one, if any.
+func (ft futureTxns) next(txn *transaction.Transaction) (
+	ntxn *transaction.Transaction) {
+
+	if txn.Nonce == 0 {
+		return
+	}
+	var ctxns, ok = ft[txn.ClientID]
+	if !ok {
+		return
+	}
+	if ntxn, ok = ctxns[txn.Nonce+1]; !ok {
+		return
+	}
+	delete(ctxns, ntxn.Nonce)
+	if len(ctxns) == 0 {
+		delete(ft, txn.ClientID)
+	}
+	return
+}
+
 // UpdatePendingBlock - updates the block that is generated and pending
 // rest of the process.
 func (mc *Chain) UpdatePendingBlock(ctx context.Context, b *block.Block, txns []datastore.Entity) {
@@ -102,7 +255,8 @@ func (mc *Chain) UpdatePendingBlock(ctx context.Context, b *block.Block, txns []
 
 func (mc *Chain) verifySmartContracts(ctx context.Context, b *block.Block) error {
 	for _, txn := range b.Txns {
-		if txn.TransactionType == transaction.TxnTypeSmartContract {
+		if txn.TransactionType == transaction.TxnTypeSmartContract ||
+			txn.TransactionType == transaction.TxnTypeSmartContractBatch {
 			err := txn.VerifyOutputHash(ctx)
 			if err != nil {
 				Logger.Error("Smart contract output verification failed", zap.Any("error", err), zap.Any("output", txn.TransactionOutput))
@@ -250,6 +404,10 @@ func (mc *Chain) VerifyBlock(ctx context.Context, b *block.Block) (
 		return
 	}
 
+	if err = mc.verifyRoundChallengesTxn(b); err != nil {
+		return
+	}
+
 	if err = mc.VerifyBlockMagicBlock(ctx, b); err != nil {
 		return
 	}
@@ -318,6 +476,9 @@ func (mc *Chain) ValidateTransactions(ctx context.Context, b *block.Block) error
 				}
 				aggregateSignatureScheme.Aggregate(sigScheme, start+idx, txn.Signature, txn.Hash)
 			}
+			if txn.Nonce != 0 {
+				continue // replays are rejected by the state computation
+			}
 			ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn)
 			if ok || err != nil {
 				if err != nil {
@@ -108,6 +108,7 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		selfKey       = node.Self.GetKey()
 		isDoubleSpend bool
 		dstxn         *transaction.Transaction
+		future        = make(futureTxns)
 	)
 
 	isDoubleSpend = state.DoubleSpendTransaction.IsBy(state, selfKey) &&
@@ -118,7 +119,8 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		dstxn = pb.Txns[rand.Intn(len(pb.Txns))] // a random one
 	}
 
-	var txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
+	var txnProcessor func(ctx context.Context, txn *transaction.Transaction) bool
+	txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
 		if _, ok := txnMap[txn.GetKey()]; ok {
 			return false
 		}
@@ -133,7 +135,21 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		if debugTxn {
 			Logger.Info("generate block (debug transaction)", zap.String("txn", txn.Hash), zap.Int32("idx", idx), zap.String("txn_object", datastore.ToJSON(txn).String()))
 		}
-		if dstxn == nil || (dstxn != nil && txn.Hash != dstxn.Hash) {
+		if txn.Nonce != 0 {
+			ns, err := mc.checkTxnNonce(b, txn)
+			if err != nil {
+				ierr = err
+				return false
+			}
+			switch ns {
+			case nonceStale:
+				invalidTxns = append(invalidTxns, txn)
+				return false
+			case nonceFuture:
+				future.add(txn)
+				return false
+			}
+		} else if dstxn == nil || (dstxn != nil && txn.Hash != dstxn.Hash) {
 			if ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn); ok || err != nil {
 				if err != nil {
 					ierr = err
@@ -151,7 +167,7 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 
 		// Setting the score lower so the next time blocks are generated
 		// these transactions don't show up at the top
-		txn.SetCollectionScore(txn.GetCollectionScore() - 10*60)
+		txn.SetCollectionScore(txn.GetDemotedCollectionScore())
 		txnMap[txn.GetKey()] = true
 		b.Txns[idx] = txn
 		if debugTxn {
@@ -164,6 +180,11 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 			clients[txn.ClientID] = nil
 		}
 		idx++
+		// include the transaction of the client that waits for this nonce
+		if ftxn := future.next(txn); ftxn != nil &&
+			idx < mc.BlockSize && byteSize < mc.MaxByteSize {
+			txnProcessor(ctx, ftxn)
+		}
 		return true
 	}
 	var roundTimeoutCount = mc.GetRoundTimeoutCount()
@@ -270,6 +291,19 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 			return err
 		}
 	}
+	// verifiers reject a block without the transaction if it's enabled, thus
+	// the block can't be generated without it
+	var roundChallenges bool
+	if roundChallenges, err = mc.isRoundChallengesEnabled(b); err != nil {
+		Logger.Error("generate block (round challenges)",
+			zap.Int64("round", b.Round), zap.Error(err))
+		return err
+	}
+	if roundChallenges {
+		if err = mc.processRoundChallengesTxn(ctx, b, clients); err != nil {
+			return err
+		}
+	}
 	b.RunningTxnCount = b.PrevBlock.RunningTxnCount + int64(len(b.Txns))
 	if count > 10*mc.BlockSize {
 		Logger.Info("generate block (too much iteration)", zap.Int64("round", b.Round), zap.Int32("iteration_count", count))
@@ -60,9 +60,11 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		failedStateCount int32
 		byteSize         int64
 		txnMap           = make(map[datastore.Key]bool, mc.BlockSize)
+		future           = make(futureTxns)
 	)
 
-	var txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
+	var txnProcessor func(ctx context.Context, txn *transaction.Transaction) bool
+	txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
 		if _, ok := txnMap[txn.GetKey()]; ok {
 			return false
 		}
@@ -82,11 +84,27 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 				zap.String("txn", txn.Hash), zap.Int32("idx", idx),
 				zap.String("txn_object", datastore.ToJSON(txn).String()))
 		}
-		if ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn); ok || err != nil {
+		if txn.Nonce == 0 {
+			if ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn); ok || err != nil {
+				if err != nil {
+					ierr = err
+				}
+				return false
+			}
+		} else {
+			ns, err := mc.checkTxnNonce(b, txn)
 			if err != nil {
 				ierr = err
+				return false
+			}
+			switch ns {
+			case nonceStale:
+				invalidTxns = append(invalidTxns, txn)
+				return false
+			case nonceFuture:
+				future.add(txn)
+				return false
 			}
-			return false
 		}
 		if err := mc.UpdateState(b, txn); err != nil {
 			if debugTxn {
@@ -101,7 +119,7 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 
 		// Setting the score lower so the next time blocks are generated
 		// these transactions don't show up at the top.
-		txn.SetCollectionScore(txn.GetCollectionScore() - 10*60)
+		txn.SetCollectionScore(txn.GetDemotedCollectionScore())
 		txnMap[txn.GetKey()] = true
 		b.Txns = append(b.Txns, txn)
 		if debugTxn {
@@ -114,6 +132,11 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 			clients[txn.ClientID] = nil
 		}
 		idx++
+		// include the transaction of the client that waits for this nonce
+		if ftxn := future.next(txn); ftxn != nil &&
+			idx < mc.BlockSize && byteSize < mc.MaxByteSize {
+			txnProcessor(ctx, ftxn)
+		}
 		return true
 	}
 	var roundTimeoutCount = mc.GetRoundTimeoutCount()
@@ -219,6 +242,19 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 			return err
 		}
 	}
+	// verifiers reject a block without the transaction if it's enabled, thus
+	// the block can't be generated without it
+	var roundChallenges bool
+	if roundChallenges, err = mc.isRoundChallengesEnabled(b); err != nil {
+		Logger.Error("generate block (round challenges)",
+			zap.Int64("round", b.Round), zap.Error(err))
+		return err
+	}
+	if roundChallenges {
+		if err = mc.processRoundChallengesTxn(ctx, b, clients); err != nil {
+			return err
+		}
+	}
 	b.RunningTxnCount = b.PrevBlock.RunningTxnCount + int64(len(b.Txns))
 	if count > 10*mc.BlockSize {
 		Logger.Info("generate block (too much iteration)", zap.Int64("round", b.Round), zap.Int32("iteration_count", count))
@@ -0,0 +1,47 @@
+package miner
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFutureTxns(t *testing.T) {
+	var newTxn = func(client string, nonce int64) *transaction.Transaction {
+		return &transaction.Transaction{
+			ClientID: encryption.Hash(client),
+			Nonce:    nonce,
+		}
+	}
+
+	var (
+		ft      = make(futureTxns)
+		first   = newTxn("client", 1)
+		third   = newTxn("client", 3)
+		second  = newTxn("client", 2)
+		another = newTxn("another", 2)
+	)
+
+	// met before the transactions they wait for
+	ft.add(third)
+	ft.add(second)
+	ft.add(another)
+
+	// not nonce based transaction doesn't include anything
+	assert.Nil(t, ft.next(newTxn("client", 0)))
+
+	// the included transactions pull the following ones in order
+	require.Equal(t, second, ft.next(first))
+	require.Equal(t, third, ft.next(second))
+	assert.Nil(t, ft.next(third))
+
+	// the gap of the other client is still not filled
+	assert.Nil(t, ft.next(newTxn("another", 2)))
+	require.Len(t, ft, 1)
+	assert.Equal(t, another, ft.next(newTxn("another", 1)))
+	assert.Empty(t, ft)
+}
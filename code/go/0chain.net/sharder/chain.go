@@ -416,6 +416,37 @@ func (sc *Chain) LoadLatestBlocksFromStore(ctx context.Context) (err error) {
 	return sc.setupLatestBlocks(ctx, bl)
 }
 
+// BootstrapFromStateSnapshot syncs the state from the latest snapshot of the
+// other sharders, if newer than the LFB, and makes its block the LFB. The
+// blocks are synced starting from the block.
+func (sc *Chain) BootstrapFromStateSnapshot(ctx context.Context) (err error) {
+	var fb *block.Block
+	fb, err = sc.SyncStateFromSnapshot(ctx, sc.GetLatestFinalizedBlock().Round)
+	if err != nil {
+		return
+	}
+	if err = sc.storeBlock(ctx, fb); err != nil {
+		return
+	}
+	if err = sc.storeBlockTransactions(ctx, fb); err != nil {
+		return
+	}
+	sc.storeBlockSummary(ctx, fb.GetSummary())
+
+	var r = round.NewRound(fb.Round)
+	sc.SetRandomSeed(r, fb.GetRoundRandomSeed())
+	fb.SetBlockState(block.StateNotarized)
+	r.AddNotarizedBlock(fb)
+	r.Finalize(fb)
+	sc.AddRound(r)
+	sc.storeRoundSummary(ctx, r)
+
+	sc.AddLoadedFinalizedBlocks(fb, sc.GetLatestFinalizedMagicBlock())
+	Logger.Info("bootstrap from state snapshot", zap.Int64("round", fb.Round),
+		zap.String("block", fb.Hash))
+	return
+}
+
 // SaveMagicBlockHandler used on sharder startup to save received
 // magic blocks. It's required to be able to load previous state.
 func (sc *Chain) SaveMagicBlockHandler(ctx context.Context,
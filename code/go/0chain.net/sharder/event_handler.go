@@ -0,0 +1,382 @@
+package sharder
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	. "0chain.net/core/logging"
+	"0chain.net/core/util"
+)
+
+const (
+	// EventsBufferSize is the number of finalized blocks buffered for a
+	// subscriber, a subscriber falling behind more is disconnected.
+	EventsBufferSize = 64
+	// EventsKeepAlive is the interval of the keep alive comments sent
+	// when there are no events.
+	EventsKeepAlive = 15 * time.Second
+	// EventsMaxBacklog is the max number of stored finalized blocks streamed
+	// to a subscriber catching up.
+	EventsMaxBacklog = 1000
+)
+
+// types of the events streamed
+const (
+	EventTypeBlock       = "block"
+	EventTypeTransaction = "transaction"
+	EventTypeError       = "error"
+)
+
+// EventFilter - filter of the transaction confirmations streamed. A
+// transaction matches if it matches all the non-empty lists of the filter,
+// that is any of the clients, any of the smart contracts and any of the
+// functions of the list. The finalized blocks are always streamed.
+type EventFilter struct {
+	ClientIDs   map[string]struct{} // from or to client
+	SCAddresses map[string]struct{} // smart contract called
+	Functions   map[string]struct{} // smart contract function called
+}
+
+func getListParam(r *http.Request, name string) (list map[string]struct{}) {
+	for _, value := range r.Form[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if list == nil {
+				list = make(map[string]struct{})
+			}
+			list[item] = struct{}{}
+		}
+	}
+	return
+}
+
+func listMatch(list map[string]struct{}, keys ...string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, key := range keys {
+		if _, ok := list[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// smartContractCalls returns addresses of the smart contracts and the
+// functions called by the transaction.
+func smartContractCalls(txn *transaction.Transaction) (addresses,
+	functions []string) {
+
+	switch txn.TransactionType {
+	case transaction.TxnTypeSmartContract:
+		var data smartcontractinterface.SmartContractTransactionData
+		if err := json.Unmarshal([]byte(txn.TransactionData), &data); err != nil {
+			return
+		}
+		return []string{txn.ToClientID}, []string{data.FunctionName}
+	case transaction.TxnTypeSmartContractBatch:
+		var calls, err = txn.GetBatchCalls()
+		if err != nil {
+			return
+		}
+		for _, call := range calls {
+			addresses = append(addresses, call.Address)
+			functions = append(functions, call.FunctionName)
+		}
+	}
+	return
+}
+
+// Match - whether the transaction matches the filter.
+func (ef *EventFilter) Match(txn *transaction.Transaction) bool {
+	if !listMatch(ef.ClientIDs, txn.ClientID, txn.ToClientID) {
+		return false
+	}
+	if len(ef.SCAddresses) == 0 && len(ef.Functions) == 0 {
+		return true
+	}
+	var addresses, functions = smartContractCalls(txn)
+	return listMatch(ef.SCAddresses, addresses...) &&
+		listMatch(ef.Functions, functions...)
+}
+
+// blockTxnConfirmation returns confirmation of the transaction of the given
+// finalized block.
+func blockTxnConfirmation(b *block.Block, mt, rmt *util.MerkleTree,
+	txn *transaction.Transaction) *transaction.Confirmation {
+
+	confirmation := datastore.GetEntityMetadata("txn_confirmation").Instance().(*transaction.Confirmation)
+	confirmation.Hash = txn.Hash
+	confirmation.BlockHash = b.Hash
+	confirmation.PreviousBlockHash = b.PrevHash
+	confirmation.Round = b.Round
+	confirmation.MinerID = b.MinerID
+	confirmation.RoundRandomSeed = b.GetRoundRandomSeed()
+	confirmation.CreationDate = b.CreationDate
+	confirmation.Status = txn.Status
+	confirmation.Transaction = txn
+	confirmation.MerkleTreeRoot = mt.GetRoot()
+	confirmation.MerkleTreePath = mt.GetPath(confirmation)
+	confirmation.ReceiptMerkleTreeRoot = rmt.GetRoot()
+	confirmation.ReceiptMerkleTreePath = rmt.GetPath(transaction.NewTransactionReceipt(txn))
+	return confirmation
+}
+
+// eventStream writes server-sent events to a client.
+type eventStream struct {
+	w      io.Writer
+	flush  func() error
+	conn   net.Conn // hijacked connection, if any
+	filter *EventFilter
+	next   int64 // next round to stream
+}
+
+// open writes the response headers of the stream. The connection is
+// hijacked if possible to clear the write deadline set by the write timeout
+// of the server, otherwise the stream is closed by the timeout.
+func (es *eventStream) open(w http.ResponseWriter) (err error) {
+	if hj, ok := w.(http.Hijacker); ok {
+		var (
+			conn net.Conn
+			rw   *bufio.ReadWriter
+		)
+		if conn, rw, err = hj.Hijack(); err != nil {
+			return
+		}
+		es.conn, es.w, es.flush = conn, rw, rw.Flush
+		if err = conn.SetWriteDeadline(time.Time{}); err != nil {
+			return
+		}
+		_, err = fmt.Fprint(rw, "HTTP/1.1 200 OK\r\n"+
+			"Content-Type: text/event-stream\r\n"+
+			"Cache-Control: no-cache\r\n"+
+			"Connection: close\r\n\r\n")
+		if err != nil {
+			return
+		}
+		return es.flush()
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return errors.New("streaming not supported")
+	}
+	es.w, es.flush = w, func() error { flusher.Flush(); return nil }
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	return es.flush()
+}
+
+// close closes the hijacked connection.
+func (es *eventStream) close() {
+	if es.conn != nil {
+		es.conn.Close()
+	}
+}
+
+func (es *eventStream) write(id int64, event string, data interface{}) error {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id > 0 {
+		_, err = fmt.Fprintf(es.w, "id: %d\n", id)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err = fmt.Fprintf(es.w, "event: %s\ndata: %s\n\n", event, buf); err != nil {
+		return err
+	}
+	return es.flush()
+}
+
+func (es *eventStream) writeError(err error) {
+	var data = map[string]interface{}{
+		"error":      err.Error(),
+		"next_round": es.next,
+	}
+	if cerr, ok := err.(*common.Error); ok {
+		data["code"] = cerr.Code
+	}
+	es.write(0, EventTypeError, data)
+}
+
+// writeBlock streams the transactions of the block matching the filter and
+// then the block summary, only the latter has the round as its id, so a
+// client resuming with the Last-Event-ID never misses transactions.
+func (es *eventStream) writeBlock(b *block.Block) (err error) {
+	var mt, rmt *util.MerkleTree
+	for _, txn := range b.Txns {
+		if !es.filter.Match(txn) {
+			continue
+		}
+		if mt == nil {
+			mt, rmt = b.GetMerkleTree(), b.GetReceiptsMerkleTree()
+		}
+		err = es.write(0, EventTypeTransaction,
+			blockTxnConfirmation(b, mt, rmt, txn))
+		if err != nil {
+			return
+		}
+	}
+	if err = es.write(b.Round, EventTypeBlock, b.GetSummary()); err != nil {
+		return
+	}
+	es.next = b.Round + 1
+	return
+}
+
+// catchUp streams the stored finalized blocks from the next round up to the
+// given round, excluding it. A subscriber which falls behind more than
+// EventsMaxBacklog rounds is disconnected.
+func (es *eventStream) catchUp(ctx context.Context, sc *Chain, to int64) (
+	err error) {
+
+	if to-es.next > EventsMaxBacklog {
+		return common.NewError("slow_subscriber",
+			fmt.Sprintf("subscriber falls behind, resume from round %d",
+				es.next))
+	}
+	for es.next < to {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		var hash string
+		if hash, err = sc.GetBlockHash(ctx, es.next); err != nil {
+			return
+		}
+		var b *block.Block
+		if b, err = sc.GetBlockFromHash(ctx, hash, es.next); err != nil {
+			return
+		}
+		if err = es.writeBlock(b); err != nil {
+			return
+		}
+	}
+	return
+}
+
+/*EventsHandler - stream of server-sent events of the finalized blocks and
+the confirmations of their transactions, filtered by the client_id,
+sc_address and function parameters (comma separated lists). The stream starts
+from the from_round parameter or, on reconnect, from the round following the
+Last-Event-ID header; from the next finalized block by default. No more than
+EventsMaxBacklog finalized blocks are caught up, an older start round is
+moved forward and the older blocks should be requested with the block API.
+The block event follows the transaction events of the block and has the round
+as its id. A client resumes with the Last-Event-ID once the connection is closed. */
+func EventsHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var (
+		sc  = GetSharderChain()
+		lfb = sc.GetLatestFinalizedBlock()
+		es  = &eventStream{
+			filter: &EventFilter{
+				ClientIDs:   getListParam(r, "client_id"),
+				SCAddresses: getListParam(r, "sc_address"),
+				Functions:   getListParam(r, "function"),
+			},
+		}
+		err error
+	)
+	if lfb == nil {
+		http.Error(w, "no finalized block yet", http.StatusServiceUnavailable)
+		return
+	}
+	if es.next, err = getIntParam(r, "from_round", lfb.Round+1); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		last, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || last < 0 {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		es.next = last + 1
+	}
+	if es.next == 0 {
+		es.next = 1
+	}
+	if backlog := lfb.Round + 1 - es.next; backlog > EventsMaxBacklog {
+		es.next = lfb.Round + 1 - EventsMaxBacklog
+	}
+
+	var sub = sc.SubscribeFinalizedBlocks(EventsBufferSize)
+	defer sub.Unsubscribe()
+
+	defer es.close()
+	if err = es.open(w); err != nil {
+		Logger.Debug("events stream", zap.Error(err))
+		return
+	}
+
+	var (
+		ctx       = r.Context()
+		keepAlive = time.NewTicker(EventsKeepAlive)
+	)
+	defer keepAlive.Stop()
+
+	if err = es.catchUp(ctx, sc, lfb.Round+1); err != nil {
+		es.writeError(err)
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			if _, err = fmt.Fprint(es.w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err = es.flush(); err != nil {
+				return
+			}
+		case b, ok := <-sub.C:
+			if !ok {
+				es.writeError(common.NewError("slow_subscriber",
+					fmt.Sprintf("subscriber falls behind, resume from round %d",
+						es.next)))
+				return
+			}
+			if b.Round < es.next {
+				continue
+			}
+			// blocks finalized while catching up or missed on a resync
+			if err = es.catchUp(ctx, sc, b.Round); err != nil {
+				es.writeError(err)
+				return
+			}
+			if err = es.writeBlock(b); err != nil {
+				Logger.Debug("events stream", zap.Int64("round", b.Round),
+					zap.Error(err))
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package sharder_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"0chain.net/chaincore/transaction"
+	"0chain.net/sharder"
+)
+
+func TestEventFilter_Match(t *testing.T) {
+	t.Parallel()
+
+	const (
+		client   = "client"
+		other    = "other"
+		scA, scB = "sc_a", "sc_b"
+	)
+
+	set := func(keys ...string) map[string]struct{} {
+		var m = make(map[string]struct{})
+		for _, key := range keys {
+			m[key] = struct{}{}
+		}
+		return m
+	}
+
+	scTxn := func(to, name string) *transaction.Transaction {
+		data, _ := json.Marshal(map[string]string{"name": name})
+		return &transaction.Transaction{
+			ClientID:        client,
+			ToClientID:      to,
+			TransactionType: transaction.TxnTypeSmartContract,
+			TransactionData: string(data),
+		}
+	}
+
+	batchTxn := func(calls ...*transaction.SmartContractBatchCall) *transaction.Transaction {
+		data, _ := json.Marshal(calls)
+		return &transaction.Transaction{
+			ClientID:        client,
+			ToClientID:      scA,
+			TransactionType: transaction.TxnTypeSmartContractBatch,
+			TransactionData: string(data),
+		}
+	}
+
+	send := &transaction.Transaction{
+		ClientID:        other,
+		ToClientID:      client,
+		TransactionType: transaction.TxnTypeSend,
+	}
+
+	tests := []struct {
+		name   string
+		filter sharder.EventFilter
+		txn    *transaction.Transaction
+		want   bool
+	}{
+		{"empty", sharder.EventFilter{}, send, true},
+		{"to_client", sharder.EventFilter{ClientIDs: set(client)}, send, true},
+		{"other_client", sharder.EventFilter{ClientIDs: set("x")}, send, false},
+		{"not_sc", sharder.EventFilter{SCAddresses: set(scA)}, send, false},
+		{"sc_address", sharder.EventFilter{SCAddresses: set(scA)}, scTxn(scA, "f"), true},
+		{"sc_other_address", sharder.EventFilter{SCAddresses: set(scB)}, scTxn(scA, "f"), false},
+		{"function", sharder.EventFilter{Functions: set("f", "g")}, scTxn(scA, "g"), true},
+		{"other_function", sharder.EventFilter{Functions: set("f")}, scTxn(scA, "g"), false},
+		{
+			"all",
+			sharder.EventFilter{ClientIDs: set(client), SCAddresses: set(scA), Functions: set("f")},
+			scTxn(scA, "f"),
+			true,
+		},
+		{
+			"batch",
+			sharder.EventFilter{SCAddresses: set(scB), Functions: set("g")},
+			batchTxn(
+				&transaction.SmartContractBatchCall{FunctionName: "f"},
+				&transaction.SmartContractBatchCall{Address: scB, FunctionName: "g"},
+			),
+			true,
+		},
+		{
+			"batch_default_address",
+			sharder.EventFilter{SCAddresses: set(scA)},
+			batchTxn(&transaction.SmartContractBatchCall{FunctionName: "f"}),
+			true,
+		},
+		{
+			"invalid_batch",
+			sharder.EventFilter{Functions: set("f")},
+			batchTxn(),
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.filter.Match(tt.txn))
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"0chain.net/chaincore/block"
 	"0chain.net/chaincore/chain"
 	"0chain.net/chaincore/diagnostics"
+	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
 )
 
@@ -21,7 +22,11 @@ import (
 func SetupHandlers() {
 	http.HandleFunc("/v1/block/get", common.UserRateLimit(common.ToJSONResponse(BlockHandler)))
 	http.HandleFunc("/v1/block/magic/get", common.UserRateLimit(common.ToJSONResponse(MagicBlockHandler)))
+	http.HandleFunc("/v1/block/headers", common.UserRateLimit(common.ToJSONResponse(BlockHeadersHandler)))
+	http.HandleFunc("/v1/block/magic/headers", common.UserRateLimit(common.ToJSONResponse(MagicBlockHeadersHandler)))
+	http.HandleFunc("/v1/events/subscribe", common.UserRateLimit(EventsHandler))
 	http.HandleFunc("/v1/transaction/get/confirmation", common.UserRateLimit(common.ToJSONResponse(TransactionConfirmationHandler)))
+	http.HandleFunc("/v1/transaction/get/events", common.UserRateLimit(common.ToJSONResponse(TransactionEventsHandler)))
 	http.HandleFunc("/v1/chain/get/stats", common.UserRateLimit(common.ToJSONResponse(ChainStatsHandler)))
 	http.HandleFunc("/_chain_stats", common.UserRateLimit(ChainStatsWriter))
 	http.HandleFunc("/_health_check", common.UserRateLimit(HealthCheckWriter))
@@ -104,6 +109,51 @@ func MagicBlockHandler(ctx context.Context, r *http.Request) (interface{}, error
 	return b, nil
 }
 
+// MaxEventsPerRequest is the max number of transaction events returned.
+const MaxEventsPerRequest = 100
+
+// MaxEventRoundsPerRequest is the max range of rounds of transaction events
+// queried by a request.
+const MaxEventRoundsPerRequest = 100 * transaction.EventRoundBucketSize
+
+/*TransactionEventsHandler - a handler to respond to transaction events
+queries, the events of the given type emitted in the given range of rounds,
+the latest MaxEventRoundsPerRequest rounds by default */
+func TransactionEventsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	eventType := r.FormValue("type")
+	if eventType == "" {
+		return nil, common.InvalidRequest("missing event type")
+	}
+	sc := GetSharderChain()
+	lfb := sc.GetLatestFinalizedBlock()
+	to, err := getIntParam(r, "to_round", lfb.Round)
+	if err != nil {
+		return nil, err
+	}
+	var defFrom int64
+	if to >= MaxEventRoundsPerRequest {
+		defFrom = to - MaxEventRoundsPerRequest + 1
+	}
+	from, err := getIntParam(r, "from_round", defFrom)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, common.InvalidRequest("invalid range of rounds")
+	}
+	if to-from >= MaxEventRoundsPerRequest {
+		return nil, common.InvalidRequest(fmt.Sprintf("range of rounds exceeds %v", MaxEventRoundsPerRequest))
+	}
+	limit, err := getIntParam(r, "limit", MaxEventsPerRequest)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > MaxEventsPerRequest {
+		limit = MaxEventsPerRequest
+	}
+	return sc.GetTransactionEvents(ctx, eventType, from, to, int(limit))
+}
+
 /*ChainStatsHandler - a handler to provide block statistics */
 func ChainStatsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
 	c := GetSharderChain().Chain
@@ -0,0 +1,151 @@
+package sharder
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+)
+
+// MaxHeadersPerRequest is the max number of block headers (or magic block
+// headers) returned by the light client endpoints.
+const MaxHeadersPerRequest = 100
+
+// BlockHeaders - headers of finalized blocks with the headers of the blocks
+// with the magic blocks they refer to. A light client, trusting a magic block,
+// verifies the headers of the next magic blocks using
+// block.VerifyMagicBlockHeaders and then the headers of the blocks using
+// block.Header.VerifyNotarization with the trusted magic blocks only. The
+// notarization threshold is a client side constant, a sharder doesn't give it.
+type BlockHeaders struct {
+	Headers     []*block.Header `json:"headers"`
+	MagicBlocks []*block.Header `json:"magic_blocks"`
+}
+
+func getIntParam(r *http.Request, name string, def int64) (int64, error) {
+	var value = r.FormValue(name)
+	if value == "" {
+		return def, nil
+	}
+	var i, err = strconv.ParseInt(value, 10, 64)
+	if err != nil || i < 0 {
+		return 0, common.InvalidRequest("invalid " + name + " parameter")
+	}
+	return i, nil
+}
+
+// getHeader returns header of a finalized block of the given round, with the
+// hash given or not.
+func (sc *Chain) getHeader(ctx context.Context, hash string, round int64) (
+	*block.Header, error) {
+
+	var err error
+	if hash == "" {
+		if hash, err = sc.GetBlockHash(ctx, round); err != nil {
+			return nil, err
+		}
+	}
+	b, err := sc.GetBlockFromHash(ctx, hash, round)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetHeader(), nil
+}
+
+/*BlockHeadersHandler - headers of the finalized blocks of the given range
+of rounds and the headers of the blocks with the magic blocks they refer to */
+func BlockHeadersHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	sc := GetSharderChain()
+	lfb := sc.GetLatestFinalizedBlock()
+	if lfb == nil {
+		return nil, common.NewError("block_headers",
+			"no latest finalized block")
+	}
+	from, err := getIntParam(r, "from", lfb.Round)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getIntParam(r, "to", from+MaxHeadersPerRequest-1)
+	if err != nil {
+		return nil, err
+	}
+	if to > lfb.Round {
+		to = lfb.Round
+	}
+	if from > to {
+		return nil, common.InvalidRequest("invalid range of rounds")
+	}
+	if to-from >= MaxHeadersPerRequest {
+		to = from + MaxHeadersPerRequest - 1
+	}
+
+	bh := &BlockHeaders{
+		Headers:     make([]*block.Header, 0, to-from+1),
+		MagicBlocks: make([]*block.Header, 0, 1),
+	}
+	var mbs = make(map[string]bool)
+	for round := from; round <= to; round++ {
+		h, err := sc.getHeader(ctx, "", round)
+		if err != nil {
+			return nil, err
+		}
+		bh.Headers = append(bh.Headers, h)
+		if h.LatestFinalizedMagicBlockHash == "" ||
+			mbs[h.LatestFinalizedMagicBlockHash] {
+			continue
+		}
+		mbs[h.LatestFinalizedMagicBlockHash] = true
+		mbh, err := sc.getHeader(ctx, h.LatestFinalizedMagicBlockHash,
+			h.LatestFinalizedMagicBlockRound)
+		if err != nil {
+			return nil, err
+		}
+		bh.MagicBlocks = append(bh.MagicBlocks, mbh)
+	}
+	return bh, nil
+}
+
+/*MagicBlockHeadersHandler - headers of the blocks with the magic blocks
+starting from the given magic block number, each one refers to the previous
+one and is notarized by its miners */
+func MagicBlockHeadersHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	sc := GetSharderChain()
+	from, err := getIntParam(r, "from", 1)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := getIntParam(r, "limit", MaxHeadersPerRequest)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > MaxHeadersPerRequest {
+		limit = MaxHeadersPerRequest
+	}
+
+	bh := &BlockHeaders{
+		Headers:     make([]*block.Header, 0),
+		MagicBlocks: make([]*block.Header, 0, limit),
+	}
+	var lfmb = sc.GetLatestFinalizedMagicBlock()
+	for number := from; number < from+limit; number++ {
+		if lfmb != nil && lfmb.MagicBlock != nil &&
+			number > lfmb.MagicBlockNumber {
+			break
+		}
+		mbm, err := sc.GetMagicBlockMap(ctx, strconv.FormatInt(number, 10))
+		if err != nil {
+			if len(bh.MagicBlocks) > 0 {
+				break // the latest one
+			}
+			return nil, err
+		}
+		mbh, err := sc.getHeader(ctx, mbm.Hash, mbm.BlockRound)
+		if err != nil {
+			return nil, err
+		}
+		bh.MagicBlocks = append(bh.MagicBlocks, mbh)
+	}
+	return bh, nil
+}
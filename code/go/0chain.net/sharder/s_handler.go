@@ -69,6 +69,11 @@ func SetupX2SResponders() {
 	// ticket from sharder sent the ticket.
 	http.HandleFunc("/v1/_x2s/block/get",
 		node.ToN2NSendEntityHandler(RoundBlockRequestHandler))
+	// state snapshots used by the sharders syncing the state from a snapshot
+	http.HandleFunc("/v1/_x2s/state/snapshot/get",
+		node.ToN2NSendEntityHandler(StateSnapshotRequestHandler))
+	http.HandleFunc("/v1/_x2s/state/snapshot/chunk/get",
+		node.ToN2NSendEntityHandler(StateSnapshotChunkRequestHandler))
 }
 
 // RoundSummariesHandler -
@@ -230,6 +235,21 @@ func RoundBlockRequestHandler(ctx context.Context, r *http.Request) (interface{}
 	return nil, err
 }
 
+// StateSnapshotRequestHandler - the manifest of the latest state snapshot
+func StateSnapshotRequestHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	return GetSharderChain().GetStateSnapshot(0)
+}
+
+// StateSnapshotChunkRequestHandler - a chunk of a state snapshot
+func StateSnapshotChunkRequestHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	roundNumber, err := strconv.ParseInt(r.FormValue("round"), 10, 64)
+	if err != nil || roundNumber <= 0 {
+		return nil, common.InvalidRequest("invalid round")
+	}
+	return GetSharderChain().GetStateSnapshotChunk(roundNumber,
+		r.FormValue("hash"))
+}
+
 func (sc *Chain) getRoundSummaries(ctx context.Context, bounds RangeBounds) []*round.Round {
 	roundS := make([]*round.Round, bounds.roundRange+1)
 	loop := 0
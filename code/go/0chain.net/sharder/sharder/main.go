@@ -248,6 +248,12 @@ func main() {
 		Logger.Fatal("update LFMB from sharders", zap.Error(err))
 	}
 
+	if viper.GetBool("server_chain.state.snapshot.bootstrap") {
+		if err := sc.BootstrapFromStateSnapshot(ctx); err != nil {
+			Logger.Error("bootstrap from state snapshot", zap.Error(err))
+		}
+	}
+
 	if serverChain.GetCurrentMagicBlock().MagicBlockNumber <
 		serverChain.GetLatestMagicBlock().MagicBlockNumber {
 
@@ -375,6 +381,7 @@ func initEntities() {
 	block.SetupStateChange(memoryStorage)
 	state.SetupPartialState(memoryStorage)
 	state.SetupStateNodes(memoryStorage)
+	state.SetupStateSnapshot(memoryStorage)
 	round.SetupEntity(ememoryStorage)
 	client.SetupEntity(memoryStorage)
 	transaction.SetupEntity(memoryStorage)
@@ -382,6 +389,7 @@ func initEntities() {
 	persistencestore.InitSession()
 	persistenceStorage := persistencestore.GetStorageProvider()
 	transaction.SetupTxnSummaryEntity(persistenceStorage)
+	transaction.SetupTxnEventEntity(persistenceStorage)
 	transaction.SetupTxnConfirmationEntity(persistenceStorage)
 	block.SetupMagicBlockMapEntity(persistenceStorage)
 
@@ -114,6 +114,7 @@ func initEntities() {
 	block.SetupStateChange(memoryStorage)
 	state.SetupPartialState(memoryStorage)
 	state.SetupStateNodes(memoryStorage)
+	state.SetupStateSnapshot(memoryStorage)
 	round.SetupEntity(ememoryStorage)
 	client.SetupEntity(memoryStorage)
 	transaction.SetupEntity(memoryStorage)
@@ -121,6 +122,7 @@ func initEntities() {
 	//persistencestore.InitSession()
 	persistenceStorage := persistencestore.GetStorageProvider()
 	transaction.SetupTxnSummaryEntity(persistenceStorage)
+	transaction.SetupTxnEventEntity(persistenceStorage)
 	transaction.SetupTxnConfirmationEntity(persistenceStorage)
 	block.SetupMagicBlockMapEntity(persistenceStorage)
 
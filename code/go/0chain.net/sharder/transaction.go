@@ -97,18 +97,24 @@ func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string) (*
 
 /*StoreTransactions - persists given list of transactions*/
 func (sc *Chain) StoreTransactions(ctx context.Context, b *block.Block) error {
-	var sTxns = make([]datastore.Entity, len(b.Txns))
+	var (
+		sTxns   = make([]datastore.Entity, len(b.Txns))
+		sEvents []datastore.Entity
+	)
 	for idx, txn := range b.Txns {
 		txnSummary := txn.GetSummary()
 		txnSummary.Round = b.Round
 		sTxns[idx] = txnSummary
 		sc.BlockTxnCache.Add(txn.Hash, txnSummary)
+		for _, event := range txn.GetEvents(b.Round) {
+			sEvents = append(sEvents, event)
+		}
 	}
 
 	delay := time.Millisecond
 	ts := time.Now()
 	for tries := 1; tries <= 9; tries++ {
-		err := sc.storeTransactions(ctx, sTxns)
+		err := sc.storeTransactions(ctx, sTxns, sEvents)
 		if err != nil {
 			delay = 2 * delay
 			Logger.Error("save transactions error", zap.Any("round", b.Round), zap.String("block", b.Hash), zap.Int("retry", tries), zap.Duration("delay", delay), zap.Error(err))
@@ -128,11 +134,51 @@ func (sc *Chain) StoreTransactions(ctx context.Context, b *block.Block) error {
 	return nil
 }
 
-func (sc *Chain) storeTransactions(ctx context.Context, sTxns []datastore.Entity) error {
+func (sc *Chain) storeTransactions(ctx context.Context, sTxns, sEvents []datastore.Entity) error {
 	txnSummaryMetadata := datastore.GetEntityMetadata("txn_summary")
 	tctx := persistencestore.WithEntityConnection(ctx, txnSummaryMetadata)
 	defer persistencestore.Close(tctx)
-	return txnSummaryMetadata.GetStore().MultiWrite(tctx, txnSummaryMetadata, sTxns)
+	err := txnSummaryMetadata.GetStore().MultiWrite(tctx, txnSummaryMetadata, sTxns)
+	if err != nil || len(sEvents) == 0 {
+		return err
+	}
+	txnEventMetadata := datastore.GetEntityMetadata("txn_event")
+	return txnEventMetadata.GetStore().MultiWrite(tctx, txnEventMetadata, sEvents)
+}
+
+/*GetTransactionEvents - get the events of the given type emitted by the
+transactions of the finalized blocks of the given range of rounds, the events
+are queried bucket by bucket of rounds */
+func (sc *Chain) GetTransactionEvents(ctx context.Context, eventType string,
+	fromRound, toRound int64, limit int) ([]*transaction.TransactionEvent, error) {
+
+	txnEventMetadata := datastore.GetEntityMetadata("txn_event")
+	tctx := persistencestore.WithEntityConnection(ctx, txnEventMetadata)
+	defer persistencestore.Close(tctx)
+	c := persistencestore.GetCon(tctx)
+	var (
+		events     = make([]*transaction.TransactionEvent, 0, limit)
+		data       string
+		fromBucket = transaction.GetEventRoundBucket(fromRound)
+		toBucket   = transaction.GetEventRoundBucket(toRound)
+	)
+	for bucket := fromBucket; bucket <= toBucket && len(events) < limit; bucket++ {
+		q := c.Query(fmt.Sprintf("SELECT JSON * FROM %v WHERE type=? AND round_bucket=? AND round>=? AND round<=? LIMIT ?",
+			txnEventMetadata.GetName()), eventType, bucket, fromRound, toRound, limit-len(events))
+		iter := q.Iter()
+		for iter.Scan(&data) {
+			event := txnEventMetadata.Instance().(*transaction.TransactionEvent)
+			if err := datastore.FromJSON(data, event); err != nil {
+				iter.Close()
+				return nil, err
+			}
+			events = append(events, event)
+		}
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
 }
 
 var txnTableIndexed = false
@@ -42,6 +42,7 @@ func SetupWorkers(ctx context.Context) {
 		sc.MagicBlockStorage)
 	go sc.UpdateMagicBlockWorker(ctx)
 	go sc.RegisterSharderKeepWorker(ctx)
+	go sc.StateSnapshotWorker(ctx)
 	// Move old blocks to cloud
 	if viper.GetBool("minio.enabled") {
 		go sc.MinioWorker(ctx)
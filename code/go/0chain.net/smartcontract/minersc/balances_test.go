@@ -2,6 +2,7 @@ package minersc
 
 import (
 	"0chain.net/chaincore/block"
+	cstate "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
@@ -59,6 +60,8 @@ func (tb *testBalances) GetTransaction() *transaction.Transaction { return nil }
 func (tb *testBalances) Validate() error                          { return nil }
 func (tb *testBalances) GetMints() []*state.Mint                  { return nil }
 func (tb *testBalances) SetStateContext(*state.State) error       { return nil }
+func (tb *testBalances) EmitEvent(string, map[string]string)      {}
+func (tb *testBalances) GetEvents() []*transaction.Event          { return nil }
 func (tb *testBalances) GetTransfers() []*state.Transfer          { return nil }
 func (tb *testBalances) AddSignedTransfer(st *state.SignedTransfer) {
 }
@@ -76,6 +79,15 @@ func (tb *testBalances) GetSignatureScheme() encryption.SignatureScheme {
 	return encryption.NewBLS0ChainScheme()
 }
 
+// NewCallContext returns a copy sharing the balances and the state
+func (tb *testBalances) NewCallContext(
+	txn *transaction.Transaction) cstate.StateContextI {
+
+	var cc = *tb
+	cc.txn = txn
+	return &cc
+}
+
 func (tb *testBalances) GetClientBalance(clientID datastore.Key) (
 	b state.Balance, err error) {
 
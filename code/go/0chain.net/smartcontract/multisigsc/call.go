@@ -0,0 +1,61 @@
+package multisigsc
+
+import (
+	"encoding/json"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+)
+
+// Call of another smart contract made on behalf of a multi-sig wallet. It is
+// proposed and voted on like a transfer. The transfer of the vote must be from
+// the wallet to the called smart contract, its amount is the value of the
+// call. Once enough votes are collected the call is executed with the wallet
+// as the client of the transaction.
+type SmartContractCall struct {
+	Address      string          `json:"address"`
+	FunctionName string          `json:"name"`
+	InputData    json.RawMessage `json:"input"`
+}
+
+func (sc *SmartContractCall) Encode() []byte {
+	buff, _ := json.Marshal(sc)
+	return buff
+}
+
+func (sc *SmartContractCall) Decode(input []byte) error {
+	err := json.Unmarshal(input, sc)
+	return err
+}
+
+func (sc *SmartContractCall) notTooBig() bool {
+	return len(sc.Address) <= MaxFieldSize &&
+		len(sc.FunctionName) <= MaxFieldSize &&
+		len(sc.InputData) <= MaxCallInputSize
+}
+
+// Hash signed by the signers voting for the call.
+func (sc *SmartContractCall) hash(t state.Transfer) string {
+	return signedHash(t, sc.Encode())
+}
+
+// Transaction of the call made by the vote transaction which has collected
+// enough votes.
+func (sc *SmartContractCall) txn(t *transaction.Transaction, w Wallet, value state.Balance) *transaction.Transaction {
+	ct := t.BatchCallTxn(0, &transaction.SmartContractBatchCall{
+		Address:      sc.Address,
+		Value:        int64(value),
+		FunctionName: sc.FunctionName,
+		InputData:    sc.InputData,
+	})
+	ct.ClientID = w.ClientID
+	ct.PublicKey = w.PublicKey
+	return ct
+}
+
+func sameCall(a, b *SmartContractCall) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return string(a.Encode()) == string(b.Encode())
+}
@@ -0,0 +1,250 @@
+package multisigsc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	c_state "0chain.net/chaincore/chain/state"
+	"0chain.net/core/common"
+)
+
+// Proposal waiting for votes.
+type pendingProposal struct {
+	proposal
+	VotesNeeded int `json:"votes_needed"`
+}
+
+// Proposal in the expiration queue.
+type queuedProposal struct {
+	proposalRef
+	Bucket         int64            `json:"bucket"`
+	ExpirationDate common.Timestamp `json:"expiration_date"`
+	Expired        bool             `json:"expired"`
+	Executed       bool             `json:"executed"`
+}
+
+type expirationQueueInfo struct {
+	BucketSize   int64            `json:"bucket_size"`
+	OldestBucket int64            `json:"oldest_bucket"`
+	NewestBucket int64            `json:"newest_bucket"`
+	Proposals    []queuedProposal `json:"proposals"`
+	Next         *queuePosition   `json:"next,omitempty"`
+}
+
+type pendingProposalsInfo struct {
+	Proposals []pendingProposal `json:"proposals"`
+	Next      *proposalRef      `json:"next,omitempty"`
+}
+
+// Default and max number of proposals of a page, and max number of proposals
+// and buckets loaded to fill a page.
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+	maxPageScan      = 10 * maxPageLimit
+)
+
+// Position in the expiration queues to walk them from: the bucket and the
+// proposal of its queue, the head of the queue if empty.
+type queuePosition struct {
+	Bucket int64       `json:"bucket"`
+	Start  proposalRef `json:"start"`
+}
+
+func getPageParams(params url.Values) (from queuePosition, limit int, err error) {
+	if v := params.Get("bucket"); v != "" {
+		from.Bucket, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || from.Bucket < 0 {
+			return queuePosition{}, 0, errors.New("invalid 'bucket' URL query parameter")
+		}
+	}
+	from.Start.ClientID = params.Get("start_client_id")
+	from.Start.ProposalID = params.Get("start_proposal_id")
+
+	limit = defaultPageLimit
+	if v := params.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return queuePosition{}, 0, fmt.Errorf("'limit' should be in [1; %d] range", maxPageLimit)
+		}
+	}
+	return from, limit, nil
+}
+
+// Walk the expiration queues from the given position: the queue of bucket 0
+// first, then the queues of the buckets from the oldest one to the newest one.
+// The walk stops once f returns true or maxPageScan proposals and buckets are
+// loaded, returning the position to continue from, nil at the end.
+func (ms MultiSigSmartContract) walkExpirationQueues(from queuePosition, balances c_state.StateContextI, f func(bucket int64, p proposal) (full bool)) (*queuePosition, error) {
+	eb, err := ms.getExpirationBuckets(balances)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := from
+	if pos.Bucket != 0 && pos.Bucket < eb.Oldest {
+		// Pruned bucket.
+		pos = queuePosition{Bucket: eb.Oldest}
+	}
+	if pos.Bucket != 0 && (eb.Oldest == 0 || pos.Bucket > eb.Newest) {
+		return nil, nil
+	}
+
+	scanned, full := 0, false
+
+	for {
+		ref := pos.Start
+		if ref == (proposalRef{}) {
+			q, err := ms.getOrCreateExpirationQueue(pos.Bucket, balances)
+			if err != nil {
+				return nil, err
+			}
+			scanned++
+			ref = q.Head
+		}
+
+		for ref != (proposalRef{}) {
+			if full || scanned >= maxPageScan {
+				return &queuePosition{Bucket: pos.Bucket, Start: ref}, nil
+			}
+			p, err := ms.getProposal(ref, balances)
+			if err != nil {
+				return nil, err
+			}
+			if p.isEmpty() {
+				// Broken link, or the proposal to start from was pruned.
+				return nil, common.NewError("broken_expiration_queue", "missing proposal in expiration queue")
+			}
+			scanned++
+			full = f(pos.Bucket, p)
+			ref = p.Next
+		}
+
+		// Next bucket.
+		if pos.Bucket == 0 {
+			pos.Bucket = eb.Oldest
+		} else {
+			pos.Bucket++
+		}
+		pos.Start = proposalRef{}
+
+		if pos.Bucket == 0 || pos.Bucket > eb.Newest {
+			return nil, nil
+		}
+		if full || scanned >= maxPageScan {
+			return &pos, nil
+		}
+	}
+}
+
+// Configuration of a multi-sig wallet.
+func (ms MultiSigSmartContract) getWalletHandler(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
+	w, err := ms.getWallet(params.Get("client_id"), balances)
+	if err != nil || w.isEmpty() {
+		return nil, common.NewError("failed to get wallet", "wallet not registered")
+	}
+	w.ProposalExpiration = w.expirationTime()
+	return w, nil
+}
+
+// Proposals of a multi-sig wallet waiting for votes, with the signatures
+// collected so far, from the pending proposals list of the wallet starting
+// from the start_proposal_id. Expired proposals not pruned yet are skipped, so
+// a page can be short, or even empty, while the position of the next one is
+// set. Proposals created before the lists were introduced aren't listed.
+func (ms MultiSigSmartContract) getPendingProposalsHandler(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
+	w, err := ms.getWallet(params.Get("client_id"), balances)
+	if err != nil || w.isEmpty() {
+		return nil, common.NewError("failed to get proposals", "wallet not registered")
+	}
+
+	from, limit, err := getPageParams(params)
+	if err != nil {
+		return nil, common.NewError("failed to get proposals", err.Error())
+	}
+
+	ref := proposalRef{ClientID: w.ClientID, ProposalID: from.Start.ProposalID}
+	if ref.ProposalID == "" {
+		wp, err := ms.getWalletProposals(w.ClientID, balances)
+		if err != nil {
+			return nil, common.NewError("failed to get proposals", err.Error())
+		}
+		ref = wp.Head
+	}
+
+	now := common.Now()
+	info := pendingProposalsInfo{Proposals: []pendingProposal{}}
+
+	for scanned := 0; ref != (proposalRef{}); scanned++ {
+		if len(info.Proposals) >= limit || scanned >= maxPageScan {
+			next := ref
+			info.Next = &next
+			break
+		}
+		p, err := ms.getProposal(ref, balances)
+		if err != nil {
+			return nil, common.NewError("failed to get proposals", err.Error())
+		}
+		if p.isEmpty() || p.ExecutedInTxnHash != "" {
+			// The proposal to start from was executed or pruned.
+			return nil, common.NewError("failed to get proposals", "no pending proposal to start from")
+		}
+		ref = p.WalletNext
+
+		if p.isExpired(now) {
+			continue
+		}
+		if p.WalletVersion != w.Version {
+			// These signatures will be dropped by the next vote.
+			p.SignerThresholdIDs = []string{}
+			p.SignerSignatures = []string{}
+		}
+		info.Proposals = append(info.Proposals, pendingProposal{
+			proposal:    p,
+			VotesNeeded: w.NumRequired - len(p.SignerSignatures),
+		})
+	}
+
+	return info, nil
+}
+
+// Proposals in the expiration queues, from the oldest bucket. Expired proposals
+// are pruned by votes, MaxPrunedPerVote at a time.
+func (ms MultiSigSmartContract) getExpirationQueueHandler(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
+	from, limit, err := getPageParams(params)
+	if err != nil {
+		return nil, common.NewError("failed to get expiration queue", err.Error())
+	}
+
+	eb, err := ms.getExpirationBuckets(balances)
+	if err != nil {
+		return nil, common.NewError("failed to get expiration queue", err.Error())
+	}
+
+	now := common.Now()
+	info := expirationQueueInfo{
+		BucketSize:   ExpirationBucketSize,
+		OldestBucket: eb.Oldest,
+		NewestBucket: eb.Newest,
+		Proposals:    []queuedProposal{},
+	}
+
+	info.Next, err = ms.walkExpirationQueues(from, balances, func(bucket int64, p proposal) bool {
+		info.Proposals = append(info.Proposals, queuedProposal{
+			proposalRef:    p.ref(),
+			Bucket:         bucket,
+			ExpirationDate: p.ExpirationDate,
+			Expired:        p.isExpired(now),
+			Executed:       p.ExecutedInTxnHash != "",
+		})
+		return len(info.Proposals) >= limit
+	})
+	if err != nil {
+		return nil, common.NewError("failed to get expiration queue", err.Error())
+	}
+
+	return info, nil
+}
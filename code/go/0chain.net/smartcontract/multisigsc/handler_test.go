@@ -0,0 +1,141 @@
+package multisigsc
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"0chain.net/chaincore/smartcontract"
+	"0chain.net/core/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiSigSmartContract_getPendingProposalsHandler(t *testing.T) {
+	smartcontract.ContractMap[testCallAddress] = &testCallSC{}
+	defer delete(smartcontract.ContractMap, testCallAddress)
+
+	// The handler checks out expiration by wall clock.
+	env := newTestEnv(t, 2, 3)
+	env.now = common.Now()
+	call := &SmartContractCall{Address: testCallAddress, FunctionName: "echo"}
+
+	ids := []string{"a", "b", "c", "d"}
+	for _, id := range ids {
+		_, _, err := env.vote(0, env.callVote(id, 0, call, 1))
+		require.NoError(t, err)
+	}
+
+	list := func(query string) (pendingProposalsInfo, error) {
+		params, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		if params.Get("client_id") == "" {
+			params.Set("client_id", env.groupClientID)
+		}
+		resp, err := env.ms.getPendingProposalsHandler(context.Background(), params, env.balances())
+		if err != nil {
+			return pendingProposalsInfo{}, err
+		}
+		return resp.(pendingProposalsInfo), nil
+	}
+	pending := func(query string) (ids []string, next *proposalRef) {
+		info, err := list(query)
+		require.NoError(t, err)
+		for _, p := range info.Proposals {
+			assert.Equal(t, 1, p.VotesNeeded)
+			ids = append(ids, p.ProposalID)
+		}
+		return ids, info.Next
+	}
+
+	got, next := pending("")
+	assert.Equal(t, ids, got)
+	assert.Nil(t, next)
+
+	// Pages.
+	got, next = pending("limit=3")
+	assert.Equal(t, ids[:3], got)
+	require.NotNil(t, next)
+	assert.Equal(t, proposalRef{env.groupClientID, "d"}, *next)
+	got, next = pending("limit=3&start_proposal_id=d")
+	assert.Equal(t, ids[3:], got)
+	assert.Nil(t, next)
+
+	// Executed proposal isn't pending.
+	_, _, err := env.vote(1, env.callVote("b", 1, call, 1))
+	require.NoError(t, err)
+	got, _ = pending("")
+	assert.Equal(t, []string{"a", "c", "d"}, got)
+	_, err = list("start_proposal_id=b")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no pending proposal to start from")
+
+	// Expired proposal not pruned yet is skipped.
+	balances := env.balances()
+	p, err := env.ms.getProposal(proposalRef{env.groupClientID, "c"}, balances)
+	require.NoError(t, err)
+	p.ExpirationDate = 1
+	require.NoError(t, env.ms.putProposal(&p, balances))
+	got, _ = pending("")
+	assert.Equal(t, []string{"a", "d"}, got)
+
+	_, err = list("client_id=unknown")
+	require.Error(t, err)
+	_, err = list("limit=1000")
+	require.Error(t, err)
+}
+
+func TestMultiSigSmartContract_getExpirationQueueHandler(t *testing.T) {
+	smartcontract.ContractMap[testCallAddress] = &testCallSC{}
+	defer delete(smartcontract.ContractMap, testCallAddress)
+
+	env := newTestEnv(t, 2, 3)
+	call := &SmartContractCall{Address: testCallAddress, FunctionName: "echo"}
+
+	// Proposals of two buckets, the first one executed.
+	for _, id := range []string{"a", "b", "c"} {
+		if id == "c" {
+			env.now += ExpirationBucketSize
+		}
+		_, _, err := env.vote(0, env.callVote(id, 0, call, 1))
+		require.NoError(t, err)
+	}
+	_, _, err := env.vote(1, env.callVote("a", 1, call, 1))
+	require.NoError(t, err)
+
+	queue := func(query string) expirationQueueInfo {
+		params, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		resp, err := env.ms.getExpirationQueueHandler(context.Background(), params, env.balances())
+		require.NoError(t, err)
+		return resp.(expirationQueueInfo)
+	}
+
+	first := getExpirationBucket(env.now - ExpirationBucketSize + ExpirationTime)
+	info := queue("")
+	assert.Equal(t, first, info.OldestBucket)
+	assert.Equal(t, first+1, info.NewestBucket)
+	require.Len(t, info.Proposals, 3)
+	for i, id := range []string{"a", "b", "c"} {
+		qp := info.Proposals[i]
+		assert.Equal(t, id, qp.ProposalID)
+		assert.Equal(t, first+int64(i/2), qp.Bucket)
+		assert.Equal(t, id == "a", qp.Executed)
+		// Expired by wall clock.
+		assert.True(t, qp.Expired)
+	}
+	assert.Nil(t, info.Next)
+
+	// Pages.
+	info = queue("limit=2")
+	require.Len(t, info.Proposals, 2)
+	require.NotNil(t, info.Next)
+	next := info.Next
+	info = queue("limit=2&bucket=" + strconv.FormatInt(next.Bucket, 10) +
+		"&start_client_id=" + next.Start.ClientID +
+		"&start_proposal_id=" + next.Start.ProposalID)
+	require.Len(t, info.Proposals, 1)
+	assert.Equal(t, "c", info.Proposals[0].ProposalID)
+	assert.Nil(t, info.Next)
+}
@@ -3,6 +3,7 @@ package multisigsc
 import (
 	"encoding/hex"
 	"encoding/json"
+	"strconv"
 
 	"0chain.net/chaincore/state"
 	"0chain.net/core/common"
@@ -11,11 +12,22 @@ import (
 )
 
 const (
-	ExpirationTime = 60 * 60 * 24 * 7 // Proposals expire after one week.
-	//ExpirationTime = 30 // Value in seconds that is more appropriate for testing.
-	MaxSigners   = 20
-	MinSigners   = 2
-	MaxFieldSize = 256
+	ExpirationTime = 60 * 60 * 24 * 7 // Proposals expire after one week by default.
+	MaxSigners     = 20
+	MinSigners     = 2
+	MaxFieldSize   = 256
+
+	MaxCallInputSize = 64 * 1024
+
+	// Bounds of expiration time of proposals configured by a wallet.
+	MinExpirationTime = 60
+	MaxExpirationTime = 60 * 60 * 24 * 30
+
+	// Proposals are queued for garbage collection in buckets of expiration
+	// dates. A vote prunes up to MaxPrunedPerVote expired proposals and empty
+	// buckets.
+	ExpirationBucketSize = 60 * 60
+	MaxPrunedPerVote     = 10
 )
 
 type Wallet struct {
@@ -27,6 +39,12 @@ type Wallet struct {
 	SignerPublicKeys   []string `json:"signer_public_keys"`
 
 	NumRequired int `json:"num_required"`
+
+	// Seconds proposals of the wallet live for, ExpirationTime if zero.
+	ProposalExpiration common.Timestamp `json:"proposal_expiration,omitempty"`
+
+	// Incremented by every wallet update.
+	Version int `json:"version"`
 }
 
 func (w Wallet) Encode() []byte {
@@ -43,6 +61,13 @@ func (w Wallet) isEmpty() bool {
 	return w.ClientID == ""
 }
 
+func (w Wallet) expirationTime() common.Timestamp {
+	if w.ProposalExpiration == 0 {
+		return ExpirationTime
+	}
+	return w.ProposalExpiration
+}
+
 func (w Wallet) getKey() datastore.Key {
 	return getWalletKey(w.ClientID)
 }
@@ -77,6 +102,11 @@ func (w Wallet) valid(forClientID string) (bool, error) {
 		return false, common.NewError("too_many_signers_required", "number of signers required is less than 2")
 	}
 
+	if w.ProposalExpiration != 0 &&
+		(w.ProposalExpiration < MinExpirationTime || w.ProposalExpiration > MaxExpirationTime) {
+		return false, common.NewError("invalid_proposal_expiration", "proposal expiration time is out of allowed range")
+	}
+
 	if hasDuplicates(w.SignerThresholdIDs) {
 		return false, common.NewError("duplicate_signer_ids", "duplicate threshold ids present")
 	}
@@ -148,6 +178,13 @@ func (w Wallet) isVoteAuthorized(signingClientID string, v Vote) bool {
 		return false
 	}
 
+	switch {
+	case v.Update != nil:
+		return w.verify(publicKey, v.Signature, v.Update.hash(v.ProposalID, w.Version, v.Transfer)) == nil
+	case v.Call != nil:
+		return w.verify(publicKey, v.Signature, v.Call.hash(v.Transfer)) == nil
+	}
+
 	err := w.makeSignedTransferForVote(publicKey, v).VerifySignature(false)
 	if err != nil {
 		return false
@@ -156,6 +193,32 @@ func (w Wallet) isVoteAuthorized(signingClientID string, v Vote) bool {
 	return true
 }
 
+// Verify a signature on the hash of a wallet update or a smart contract call.
+func (w Wallet) verify(publicKey, signature, hash string) error {
+	scheme := encryption.GetSignatureScheme(w.SignatureScheme)
+
+	err := scheme.SetPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	ok, err := scheme.Verify(signature, hash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return common.NewError("invalid_signature", "invalid signature")
+	}
+
+	return nil
+}
+
+// Hash of a transfer and data voted on with it. Votes on plain transfers
+// sign the transfer only.
+func signedHash(t state.Transfer, data []byte) string {
+	return encryption.Hash(append(t.Encode(), data...))
+}
+
 func (w Wallet) makeSignedTransferForVote(signingPublicKey string, v Vote) state.SignedTransfer {
 	return state.SignedTransfer{
 		Transfer:   v.Transfer,
@@ -259,6 +322,16 @@ type Vote struct {
 	// Client ID in transfer is that of the multi-sig wallet, not the signer.
 	Transfer state.Transfer `json:"transfer"`
 
+	// Set for votes on a wallet update instead of a transfer. The transfer
+	// must then be from the wallet to this smart contract with zero amount,
+	// and the signature is on the update.
+	Update *WalletUpdate `json:"update,omitempty"`
+
+	// Set for votes on a smart contract call instead of a transfer. The
+	// transfer must then be from the wallet to the called smart contract
+	// with the value of the call, and the signature is on the call.
+	Call *SmartContractCall `json:"call,omitempty"`
+
 	Signature string `json:"signature"`
 }
 
@@ -270,6 +343,12 @@ func (v Vote) notTooBig() bool {
 }
 
 func (v Vote) hasValidAmount() bool {
+	switch {
+	case v.Update != nil:
+		return v.Transfer.Amount == 0 && v.Transfer.ToClientID == Address
+	case v.Call != nil:
+		return v.Transfer.Amount >= 0 && v.Transfer.ToClientID == v.Call.Address
+	}
 	return v.Transfer.Amount > 0
 }
 
@@ -285,7 +364,8 @@ func (v Vote) getProposalRef() proposalRef {
 }
 
 func (v Vote) isCompatibleWithProposal(p proposal) bool {
-	return v.Transfer == p.Transfer
+	return v.Transfer == p.Transfer && sameUpdate(v.Update, p.Update) &&
+		sameCall(v.Call, p.Call)
 }
 
 // Uniquely identifies a proposal. Can be used to refer to one.
@@ -304,19 +384,28 @@ func (pr *proposalRef) Decode(input []byte) error {
 	return err
 }
 
-// Proposal to transfer tokens out of the multi-sig wallet. Built up from T
-// different votes.
+// Proposal to transfer tokens out of the multi-sig wallet, to update it or to
+// call a smart contract on its behalf. Built up from T different votes.
 type proposal struct {
 	// Proposal ID is unique only within a single multi-sig wallet. Globally, a
 	// proposal may be referred to by a wallet ID / proposal ID pair.
 	ProposalID     string           `json:"proposal_id"`
 	ExpirationDate common.Timestamp `json:"expiration_date"`
 
-	// Intrusive queue sorted by expiration date for garbage collection.
+	// Intrusive queue of the expiration bucket for garbage collection.
 	Next proposalRef `json:"next"`
 	Prev proposalRef `json:"prev"`
 
-	Transfer state.Transfer `json:"transfer"`
+	// Intrusive list of pending proposals of the wallet.
+	WalletNext proposalRef `json:"wallet_next"`
+	WalletPrev proposalRef `json:"wallet_prev"`
+
+	Transfer state.Transfer     `json:"transfer"`
+	Update   *WalletUpdate      `json:"update,omitempty"`
+	Call     *SmartContractCall `json:"call,omitempty"`
+
+	// Version of the wallet the votes were collected for.
+	WalletVersion int `json:"wallet_version"`
 
 	// Pertinent data from votes.
 	SignerThresholdIDs []string `json:"signer_threshold_ids"`
@@ -360,7 +449,9 @@ func getProposalKey(clientID, proposalID string) datastore.Key {
 	return datastore.Key(Address + clientID + encryption.Hash(proposalID))
 }
 
-// Queue of all proposals across all wallets sorted by expiration date.
+// Queue of proposals of an expiration bucket in the order they were created.
+// Bucket 0 is the queue of all proposals sorted by expiration date created
+// before the buckets were introduced.
 type expirationQueue struct {
 	Head proposalRef `json:"head"`
 	Tail proposalRef `json:"tail"`
@@ -376,6 +467,71 @@ func (q *expirationQueue) Decode(input []byte) error {
 	return err
 }
 
-func getExpirationQueueKey() datastore.Key {
-	return datastore.Key(Address + encryption.Hash("queue"))
+func (q *expirationQueue) isEmpty() bool {
+	return q.Head == (proposalRef{})
+}
+
+// Bucket of proposals expiring at the given date. A bucket is pruned once all
+// its proposals have expired.
+func getExpirationBucket(date common.Timestamp) int64 {
+	return int64(date) / ExpirationBucketSize
+}
+
+func getExpirationQueueKey(bucket int64) datastore.Key {
+	if bucket == 0 {
+		return datastore.Key(Address + encryption.Hash("queue"))
+	}
+	return datastore.Key(Address + encryption.Hash("queue:"+strconv.FormatInt(bucket, 10)))
+}
+
+// Range of expiration buckets which may have proposals. Buckets before Oldest
+// have been pruned.
+type expirationBuckets struct {
+	Oldest int64 `json:"oldest"`
+	Newest int64 `json:"newest"`
+}
+
+func (eb *expirationBuckets) Encode() []byte {
+	buff, _ := json.Marshal(eb)
+	return buff
+}
+
+func (eb *expirationBuckets) Decode(input []byte) error {
+	err := json.Unmarshal(input, eb)
+	return err
+}
+
+func getExpirationBucketsKey() datastore.Key {
+	return datastore.Key(Address + encryption.Hash("queue_buckets"))
+}
+
+// List of pending proposals of a wallet in the order they were created. A
+// proposal is removed from the list once executed or pruned. Proposals created
+// before the lists were introduced aren't listed.
+type walletProposals struct {
+	Head proposalRef `json:"head"`
+	Tail proposalRef `json:"tail"`
+}
+
+func (wp *walletProposals) Encode() []byte {
+	buff, _ := json.Marshal(wp)
+	return buff
+}
+
+func (wp *walletProposals) Decode(input []byte) error {
+	err := json.Unmarshal(input, wp)
+	return err
+}
+
+func (wp *walletProposals) isEmpty() bool {
+	return wp.Head == (proposalRef{})
+}
+
+// Whether the proposal is in the list.
+func (wp *walletProposals) has(p proposal) bool {
+	return wp.Head == p.ref() || p.WalletPrev != (proposalRef{})
+}
+
+func getWalletProposalsKey(clientID string) datastore.Key {
+	return datastore.Key(Address + encryption.Hash("proposals:"+clientID))
 }
@@ -7,6 +7,7 @@ import (
 
 	"0chain.net/chaincore/chain/state"
 	c_state "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/smartcontract"
 	"0chain.net/chaincore/smartcontractinterface"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
@@ -43,6 +44,9 @@ func (ms *MultiSigSmartContract) GetRestPoints() map[string]smartcontractinterfa
 
 func (ms *MultiSigSmartContract) SetSC(sc *smartcontractinterface.SmartContract, bc smartcontractinterface.BCContextI) {
 	ms.SmartContract = sc
+	ms.SmartContract.RestHandlers["/getWallet"] = ms.getWalletHandler
+	ms.SmartContract.RestHandlers["/getPendingProposals"] = ms.getPendingProposalsHandler
+	ms.SmartContract.RestHandlers["/getExpirationQueue"] = ms.getExpirationQueueHandler
 }
 
 func (ms MultiSigSmartContract) Execute(t *transaction.Transaction, funcName string, inputData []byte, balances state.StateContextI) (string, error) {
@@ -138,10 +142,42 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 	if !v.hasSignature() {
 		return "", common.NewError("err_vote_no_signature", " must sign vote")
 	}
+	if v.Update != nil {
+		if v.Update.isEmpty() {
+			return "", common.NewError("err_vote_empty_update", "wallet update changes nothing")
+		}
+		if !v.Update.notTooBig() {
+			return "", common.NewError("err_vote_too_big", "an update field exceeded allowable length")
+		}
+	}
+	if v.Call != nil {
+		if v.Update != nil {
+			return "", common.NewError("err_vote_call_and_update", "can't vote on a call and an update at once")
+		}
+		if v.Call.FunctionName == "" {
+			return "", common.NewError("err_vote_call_no_function", "missing function name of the call")
+		}
+		if v.Call.Address == Address {
+			return "", common.NewError("err_vote_call_multisig", "can't call the multi-sig smart contract")
+		}
+		if !v.Call.notTooBig() {
+			return "", common.NewError("err_vote_too_big", "a call field exceeded allowable length")
+		}
+	}
+
+	// Check that the multi-sig wallet is registered.
+	w, err := ms.getWallet(v.Transfer.ClientID, balances)
+	if err != nil {
+		// I/O error.
+		return "", err
+	}
+	if w.isEmpty() {
+		return "", common.NewError("err_vote_wallet_not_registered", " wallet not registered")
+	}
 
 	// Every vote is associated with a proposal. If an appropriate proposal does
 	// not exist yet, create one.
-	p, err := ms.findOrCreateProposal(now, v, balances)
+	p, err := ms.findOrCreateProposal(now, v, w, balances)
 	if err != nil {
 		// I/O error.
 		return "", err
@@ -157,16 +193,6 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 		return "success 0: proposal previously executed in transaction hash " + p.ExecutedInTxnHash, nil
 	}
 
-	// Check that the multi-sig wallet is registered.
-	w, err := ms.getWallet(v.Transfer.ClientID, balances)
-	if err != nil {
-		// I/O error.
-		return "", err
-	}
-	if w.isEmpty() {
-		return "", common.NewError("err_vote_wallet_not_registered", " wallet not registered")
-	}
-
 	// Check that the voter is registered on the wallet and that the signature
 	// is valid.
 	signerThresholdID := w.thresholdIdForSigner(signingClientID)
@@ -177,6 +203,14 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 		return "", common.NewError("err_vote_auth", " authorization failure")
 	}
 
+	// Signature shares collected before the wallet was updated can't be
+	// combined with shares of the updated signers. Collect them again.
+	if p.WalletVersion != w.Version {
+		p.WalletVersion = w.Version
+		p.SignerThresholdIDs = []string{}
+		p.SignerSignatures = []string{}
+	}
+
 	remaining := w.NumRequired - len(p.SignerSignatures)
 
 	// Check if this is a duplicate vote.
@@ -214,6 +248,13 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 
 	p.ClientSignature = thresholdSignature
 
+	switch {
+	case p.Update != nil:
+		return ms.executeUpdate(currentTxnHash, w, p, balances)
+	case p.Call != nil:
+		return ms.executeCall(w, p, balances)
+	}
+
 	// Request the transfer. The blockchain will validate the signature and
 	// execute the transfer soon. If the signature is found to be invalid,
 	// this vote transaction will fail.
@@ -221,9 +262,7 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 	balances.AddSignedTransfer(&signedTransfer)
 
 	// Save the proposal again.
-	p.ExecutedInTxnHash = currentTxnHash
-
-	err = ms.putProposal(&p, balances)
+	err = ms.markExecuted(&p, currentTxnHash, balances)
 	if err != nil {
 		// I/O error.
 		return "", err
@@ -233,62 +272,179 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 	return msg, nil
 }
 
-// Prune the oldest proposal if it has expired.
-func (ms MultiSigSmartContract) pruneExpirationQueue(now common.Timestamp, balances state.StateContextI) error {
-	q, err := ms.getOrCreateExpirationQueue(balances)
+// Update the wallet by the proposal which has collected enough votes.
+func (ms MultiSigSmartContract) executeUpdate(currentTxnHash string, w Wallet, p proposal, balances state.StateContextI) (string, error) {
+	// Unlike transfers, nobody else checks the group signature of an update.
+	err := w.verify(w.PublicKey, p.ClientSignature, p.Update.hash(p.ProposalID, w.Version, p.Transfer))
 	if err != nil {
-		return err
+		return "", common.NewError("err_vote_recover", " in signature recovery: "+err.Error())
 	}
 
-	// Reference to oldest proposal.
-	ref := q.Head
+	updated, err := p.Update.apply(w)
+	if err != nil {
+		return "", err
+	}
 
-	if ref == (proposalRef{}) {
-		// No proposals currently exist.
-		return nil
+	err = ms.putWallet(updated, balances)
+	if err != nil {
+		// I/O error.
+		return "", err
 	}
 
-	p, err := ms.getProposal(ref, balances)
+	err = ms.markExecuted(&p, currentTxnHash, balances)
 	if err != nil {
-		return err
+		// I/O error.
+		return "", err
 	}
 
-	if p.isExpired(now) {
-		return ms.prune(ref, balances)
+	msg := "success 0: wallet updated with signature " + p.ClientSignature
+	return msg, nil
+}
+
+// Execute the smart contract call of the proposal which has collected enough
+// votes. The wallet is the client of the call, the value of the call is
+// transferred from the wallet.
+func (ms MultiSigSmartContract) executeCall(w Wallet, p proposal, balances state.StateContextI) (string, error) {
+	// Nobody else checks the group signature of a call.
+	err := w.verify(w.PublicKey, p.ClientSignature, p.Call.hash(p.Transfer))
+	if err != nil {
+		return "", common.NewError("err_vote_recover", " in signature recovery: "+err.Error())
 	}
 
-	return nil
+	t := balances.GetTransaction()
+	ct := p.Call.txn(t, w, p.Transfer.Amount)
+
+	output, err := smartcontract.ExecuteSmartContract(common.GetRootContext(), ct, balances.NewCallContext(ct))
+	if err != nil {
+		return "", common.NewError("err_vote_call", " smart contract call failed: "+err.Error())
+	}
+
+	err = ms.markExecuted(&p, t.Hash, balances)
+	if err != nil {
+		// I/O error.
+		return "", err
+	}
+
+	msg := "success 0: smart contract call executed with output " + output
+	return msg, nil
 }
 
-func (ms MultiSigSmartContract) prune(ref proposalRef, balances c_state.StateContextI) error {
-	// Before we prune this proposal, fetch it one last time.
-	p, err := ms.getProposal(ref, balances)
+// Mark the proposal executed in the transaction and save it. An executed
+// proposal isn't pending anymore.
+func (ms MultiSigSmartContract) markExecuted(p *proposal, txnHash string, balances c_state.StateContextI) error {
+	p.ExecutedInTxnHash = txnHash
+
+	err := ms.removeWalletProposal(p, balances)
 	if err != nil {
 		return err
 	}
 
-	// Update expiry queue.
-	q, err := ms.getOrCreateExpirationQueue(balances)
+	return ms.putProposal(p, balances)
+}
+
+// Prune up to MaxPrunedPerVote expired proposals, the oldest ones first.
+func (ms MultiSigSmartContract) pruneExpirationQueue(now common.Timestamp, balances state.StateContextI) error {
+	pruned := 0
+
+	// Proposals queued before the expiration buckets, sorted by expiration
+	// date.
+	q, err := ms.getOrCreateExpirationQueue(0, balances)
 	if err != nil {
 		return err
 	}
 
-	qChanged := false
-
-	if q.Head == ref {
+	for ; pruned < MaxPrunedPerVote && !q.isEmpty(); pruned++ {
+		p, err := ms.getProposal(q.Head, balances)
+		if err != nil {
+			return err
+		}
+		if !p.isExpired(now) {
+			break
+		}
+		err = ms.prune(q.Head, balances)
+		if err != nil {
+			return err
+		}
 		q.Head = p.Next
-		qChanged = true
 	}
-	if q.Tail == ref {
-		q.Tail = p.Prev
-		qChanged = true
+
+	eb, err := ms.getExpirationBuckets(balances)
+	if err != nil {
+		return err
+	}
+
+	if eb.Oldest == 0 {
+		// No proposals have been bucketed yet.
+		return nil
+	}
+
+	ebChanged := false
+
+	for ; pruned < MaxPrunedPerVote && eb.Oldest <= eb.Newest; pruned++ {
+		// Proposals of a bucket have all expired once the bucket ends.
+		if common.Timestamp((eb.Oldest+1)*ExpirationBucketSize) > now {
+			break
+		}
+
+		q, err := ms.getOrCreateExpirationQueue(eb.Oldest, balances)
+		if err != nil {
+			return err
+		}
+
+		if q.isEmpty() {
+			// Move on to the next bucket. Skipping a bucket counts as a
+			// pruned proposal, as empty buckets can take long to skip.
+			eb.Oldest++
+			ebChanged = true
+			continue
+		}
+
+		err = ms.prune(q.Head, balances)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ebChanged {
+		return ms.putExpirationBuckets(&eb, balances)
 	}
 
-	if qChanged {
-		err = ms.putExpirationQueue(&q, balances)
+	return nil
+}
+
+func (ms MultiSigSmartContract) prune(ref proposalRef, balances c_state.StateContextI) error {
+	// Before we prune this proposal, fetch it one last time.
+	p, err := ms.getProposal(ref, balances)
+	if err != nil {
+		return err
+	}
+
+	// Update expiration queue. The proposal is either in the queue of its
+	// expiration bucket or, if it was created before the buckets, in the
+	// queue of bucket 0.
+	for _, bucket := range []int64{getExpirationBucket(p.ExpirationDate), 0} {
+		q, err := ms.getOrCreateExpirationQueue(bucket, balances)
 		if err != nil {
 			return err
 		}
+
+		qChanged := false
+
+		if q.Head == ref {
+			q.Head = p.Next
+			qChanged = true
+		}
+		if q.Tail == ref {
+			q.Tail = p.Prev
+			qChanged = true
+		}
+
+		if qChanged {
+			err = ms.putExpirationQueue(bucket, &q, balances)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Update links.
@@ -320,6 +476,12 @@ func (ms MultiSigSmartContract) prune(ref proposalRef, balances c_state.StateCon
 		}
 	}
 
+	// Pending proposal of the wallet.
+	err = ms.removeWalletProposal(&p, balances)
+	if err != nil {
+		return err
+	}
+
 	// Now we can delete the pruned proposal.
 	_, err = balances.DeleteTrieNode(p.getKey())
 	if err != nil {
@@ -329,7 +491,7 @@ func (ms MultiSigSmartContract) prune(ref proposalRef, balances c_state.StateCon
 	return nil
 }
 
-func (ms MultiSigSmartContract) findOrCreateProposal(now common.Timestamp, v Vote, balances state.StateContextI) (proposal, error) {
+func (ms MultiSigSmartContract) findOrCreateProposal(now common.Timestamp, v Vote, w Wallet, balances state.StateContextI) (proposal, error) {
 	// Start by trying to find an existing proposal.
 	p, err := ms.getProposal(v.getProposalRef(), balances)
 	if err != nil {
@@ -348,7 +510,7 @@ func (ms MultiSigSmartContract) findOrCreateProposal(now common.Timestamp, v Vot
 
 	// If it didn't exist or was expired, create it and update expiration queue.
 	if p.isEmpty() {
-		p, err = ms.createProposal(now, v, balances)
+		p, err = ms.createProposal(now, v, w, balances)
 		if err != nil {
 			return proposal{}, err
 		}
@@ -357,24 +519,22 @@ func (ms MultiSigSmartContract) findOrCreateProposal(now common.Timestamp, v Vot
 	return p, nil
 }
 
-// Create a proposal and add it to the expiration queue. Performs I/O.
-func (ms MultiSigSmartContract) createProposal(now common.Timestamp, v Vote, balances state.StateContextI) (proposal, error) {
-	q, err := ms.getOrCreateExpirationQueue(balances)
-	if err != nil {
-		if err != util.ErrValueNotPresent && err != util.ErrNodeNotFound {
-			return proposal{}, err
-		} //else we will create a proposal
-	}
-
+// Create a proposal and add it to the queue of its expiration bucket. Performs
+// I/O.
+func (ms MultiSigSmartContract) createProposal(now common.Timestamp, v Vote, w Wallet, balances state.StateContextI) (proposal, error) {
 	// Create proposal.
 	p := proposal{
 		ProposalID:     v.ProposalID,
-		ExpirationDate: now + ExpirationTime,
+		ExpirationDate: now + w.expirationTime(),
 
 		Next: proposalRef{},
-		Prev: q.Tail,
+		Prev: proposalRef{},
 
 		Transfer: v.Transfer,
+		Update:   v.Update,
+		Call:     v.Call,
+
+		WalletVersion: w.Version,
 
 		SignerThresholdIDs: []string{},
 		SignerSignatures:   []string{},
@@ -383,12 +543,18 @@ func (ms MultiSigSmartContract) createProposal(now common.Timestamp, v Vote, bal
 		ExecutedInTxnHash: "",
 	}
 
-	err = ms.putProposal(&p, balances)
+	// Wallets can have different expiration times. Proposals of a bucket are
+	// pruned together once it ends, so they are simply enqueued.
+	bucket := getExpirationBucket(p.ExpirationDate)
+
+	q, err := ms.getOrCreateExpirationQueue(bucket, balances)
 	if err != nil {
 		return proposal{}, err
 	}
 
 	// Update links.
+	p.Prev = q.Tail
+
 	if q.Tail != (proposalRef{}) {
 		prev, err := ms.getProposal(q.Tail, balances)
 		if err != nil {
@@ -412,14 +578,135 @@ func (ms MultiSigSmartContract) createProposal(now common.Timestamp, v Vote, bal
 	// Enqueue.
 	q.Tail = p.ref()
 
-	err = ms.putExpirationQueue(&q, balances)
+	err = ms.addWalletProposal(&p, balances)
+	if err != nil {
+		return proposal{}, err
+	}
+
+	err = ms.putProposal(&p, balances)
+	if err != nil {
+		return proposal{}, err
+	}
+
+	err = ms.putExpirationQueue(bucket, &q, balances)
 	if err != nil {
 		return proposal{}, err
 	}
 
+	// Update range of buckets to prune.
+	eb, err := ms.getExpirationBuckets(balances)
+	if err != nil {
+		return proposal{}, err
+	}
+
+	ebChanged := false
+
+	if eb.Oldest == 0 || eb.Oldest > eb.Newest || bucket < eb.Oldest {
+		eb.Oldest = bucket
+		ebChanged = true
+	}
+	if bucket > eb.Newest {
+		eb.Newest = bucket
+		ebChanged = true
+	}
+
+	if ebChanged {
+		err = ms.putExpirationBuckets(&eb, balances)
+		if err != nil {
+			return proposal{}, err
+		}
+	}
+
 	return p, nil
 }
 
+// Append a new proposal to the pending proposals of its wallet. The proposal
+// itself is saved by the caller.
+func (ms MultiSigSmartContract) addWalletProposal(p *proposal, balances c_state.StateContextI) error {
+	wp, err := ms.getWalletProposals(p.Transfer.ClientID, balances)
+	if err != nil {
+		return err
+	}
+
+	p.WalletPrev = wp.Tail
+	p.WalletNext = proposalRef{}
+
+	if wp.Tail != (proposalRef{}) {
+		prev, err := ms.getProposal(wp.Tail, balances)
+		if err != nil {
+			return err
+		}
+
+		prev.WalletNext = p.ref()
+
+		err = ms.putProposal(&prev, balances)
+		if err != nil {
+			return err
+		}
+	}
+
+	if wp.isEmpty() {
+		wp.Head = p.ref()
+	}
+	wp.Tail = p.ref()
+
+	return ms.putWalletProposals(p.Transfer.ClientID, &wp, balances)
+}
+
+// Remove a proposal from the pending proposals of its wallet, if it's there.
+// The proposal itself is saved, or deleted, by the caller.
+func (ms MultiSigSmartContract) removeWalletProposal(p *proposal, balances c_state.StateContextI) error {
+	wp, err := ms.getWalletProposals(p.Transfer.ClientID, balances)
+	if err != nil {
+		return err
+	}
+
+	if !wp.has(*p) {
+		return nil
+	}
+
+	ref := p.ref()
+	if wp.Head == ref {
+		wp.Head = p.WalletNext
+	}
+	if wp.Tail == ref {
+		wp.Tail = p.WalletPrev
+	}
+
+	// Update links.
+	if p.WalletNext != (proposalRef{}) {
+		next, err := ms.getProposal(p.WalletNext, balances)
+		if err != nil {
+			return err
+		}
+
+		next.WalletPrev = p.WalletPrev
+
+		err = ms.putProposal(&next, balances)
+		if err != nil {
+			return err
+		}
+	}
+
+	if p.WalletPrev != (proposalRef{}) {
+		prev, err := ms.getProposal(p.WalletPrev, balances)
+		if err != nil {
+			return err
+		}
+
+		prev.WalletNext = p.WalletNext
+
+		err = ms.putProposal(&prev, balances)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.WalletNext, p.WalletPrev = proposalRef{}, proposalRef{}
+
+	return ms.putWalletProposals(p.Transfer.ClientID, &wp, balances)
+}
+
 func (ms MultiSigSmartContract) walletExists(clientID string, balances c_state.StateContextI) (bool, error) {
 	walletBytes, err := balances.GetTrieNode(getWalletKey(clientID))
 	if err != nil {
@@ -493,8 +780,8 @@ func (ms MultiSigSmartContract) putProposal(p *proposal, balances c_state.StateC
 	return err
 }
 
-func (ms MultiSigSmartContract) getOrCreateExpirationQueue(balances c_state.StateContextI) (expirationQueue, error) {
-	qNode, err := balances.GetTrieNode(getExpirationQueueKey())
+func (ms MultiSigSmartContract) getOrCreateExpirationQueue(bucket int64, balances c_state.StateContextI) (expirationQueue, error) {
+	qNode, err := balances.GetTrieNode(getExpirationQueueKey(bucket))
 
 	if err != nil {
 		// I/O error.
@@ -518,12 +805,71 @@ func (ms MultiSigSmartContract) getOrCreateExpirationQueue(balances c_state.Stat
 	return q, nil
 }
 
-func (ms MultiSigSmartContract) putExpirationQueue(q *expirationQueue, balances c_state.StateContextI) error {
-	//proposalBytes, err := json.Marshal(p)
-	//if err != nil {
-	//	return err
-	//}
+func (ms MultiSigSmartContract) putExpirationQueue(bucket int64, q *expirationQueue, balances c_state.StateContextI) error {
+	if q.isEmpty() && bucket != 0 {
+		// Pruned bucket.
+		_, err := balances.DeleteTrieNode(getExpirationQueueKey(bucket))
+		return err
+	}
+
+	_, err := balances.InsertTrieNode(getExpirationQueueKey(bucket), q)
+	return err
+}
+
+func (ms MultiSigSmartContract) getExpirationBuckets(balances c_state.StateContextI) (expirationBuckets, error) {
+	ebNode, err := balances.GetTrieNode(getExpirationBucketsKey())
+	if err != nil {
+		// I/O error.
+		if err != util.ErrValueNotPresent && err != util.ErrNodeNotFound {
+			return expirationBuckets{}, err
+		} //else no proposals have been bucketed.
+		return expirationBuckets{}, nil
+	}
+
+	eb := expirationBuckets{}
+	err = json.Unmarshal(ebNode.Encode(), &eb)
+	if err != nil {
+		// Decoding error.
+		return expirationBuckets{}, err
+	}
+
+	return eb, nil
+}
+
+func (ms MultiSigSmartContract) putExpirationBuckets(eb *expirationBuckets, balances c_state.StateContextI) error {
+	_, err := balances.InsertTrieNode(getExpirationBucketsKey(), eb)
+	return err
+}
+
+func (ms MultiSigSmartContract) getWalletProposals(clientID string, balances c_state.StateContextI) (walletProposals, error) {
+	wpNode, err := balances.GetTrieNode(getWalletProposalsKey(clientID))
+	if err != nil {
+		// I/O error.
+		if err != util.ErrValueNotPresent && err != util.ErrNodeNotFound {
+			return walletProposals{}, err
+		} //else no pending proposals.
+		return walletProposals{}, nil
+	}
+
+	wp := walletProposals{}
+	err = json.Unmarshal(wpNode.Encode(), &wp)
+	if err != nil {
+		// Decoding error.
+		return walletProposals{}, err
+	}
+
+	return wp, nil
+}
+
+func (ms MultiSigSmartContract) putWalletProposals(clientID string, wp *walletProposals, balances c_state.StateContextI) error {
+	if wp.isEmpty() {
+		_, err := balances.DeleteTrieNode(getWalletProposalsKey(clientID))
+		if err == util.ErrValueNotPresent || err == util.ErrNodeNotFound {
+			return nil
+		}
+		return err
+	}
 
-	_, err := balances.InsertTrieNode(getExpirationQueueKey(), q)
+	_, err := balances.InsertTrieNode(getWalletProposalsKey(clientID), wp)
 	return err
 }
@@ -0,0 +1,444 @@
+package multisigsc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/smartcontract"
+	"0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testCallAddress = "cf8d0df9bd8cc637a4ff4e792ffe3686da6220c45f0e1103baa609f3f1751ef5"
+
+// Smart contract called by the multi-sig wallet in tests. It takes the value
+// of the call, or more if asked to overspend.
+type testCallSC struct{}
+
+func (*testCallSC) Execute(t *transaction.Transaction, funcName string, input []byte, balances cstate.StateContextI) (string, error) {
+	amount := state.Balance(t.Value)
+	switch funcName {
+	case "fail":
+		return "", errors.New("failed on purpose")
+	case "overspend":
+		amount++
+	}
+	err := balances.AddTransfer(state.NewTransfer(t.ClientID, t.ToClientID, amount))
+	if err != nil {
+		return "", err
+	}
+	balances.EmitEvent("test_call", map[string]string{"client_id": t.ClientID})
+	return "called " + funcName + " " + string(input), nil
+}
+
+func (*testCallSC) SetSC(*smartcontractinterface.SmartContract, smartcontractinterface.BCContextI) {
+}
+func (*testCallSC) GetRestPoints() map[string]smartcontractinterface.SmartContractRestHandler {
+	return nil
+}
+func (*testCallSC) GetName() string    { return "test_call" }
+func (*testCallSC) GetAddress() string { return testCallAddress }
+func (*testCallSC) InitSC()            {}
+
+// Multi-sig wallet with all the keys, and the state of the chain.
+type testEnv struct {
+	t   *testing.T
+	ms  MultiSigSmartContract
+	mpt util.MerklePatriciaTrieI
+	now common.Timestamp
+
+	groupKey        encryption.SignatureScheme
+	groupClientID   string
+	signerKeys      []encryption.ThresholdSignatureScheme
+	signerClientIDs []string
+
+	txns int
+}
+
+func clientIDForKey(key encryption.SignatureScheme) string {
+	b, _ := hex.DecodeString(key.GetPublicKey())
+	return encryption.Hash(b)
+}
+
+func newTestEnv(t *testing.T, tn, n int) *testEnv {
+	logging.Logger = zap.NewNop()
+
+	env := &testEnv{
+		t:        t,
+		mpt:      util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 0),
+		now:      common.Timestamp(60 * 60),
+		groupKey: encryption.NewBLS0ChainScheme(),
+	}
+	require.NoError(t, env.groupKey.GenerateKeys())
+	env.groupClientID = clientIDForKey(env.groupKey)
+
+	var err error
+	env.signerKeys, err = encryption.GenerateThresholdKeyShares("bls0chain", tn, n, env.groupKey)
+	require.NoError(t, err)
+	for _, key := range env.signerKeys {
+		env.signerClientIDs = append(env.signerClientIDs, clientIDForKey(key))
+	}
+
+	w := Wallet{
+		ClientID:        env.groupClientID,
+		SignatureScheme: "bls0chain",
+		PublicKey:       env.groupKey.GetPublicKey(),
+		NumRequired:     tn,
+	}
+	for _, key := range env.signerKeys {
+		w.SignerThresholdIDs = append(w.SignerThresholdIDs, key.GetID())
+		w.SignerPublicKeys = append(w.SignerPublicKeys, key.GetPublicKey())
+	}
+	input, err := json.Marshal(w)
+	require.NoError(t, err)
+	output, err := env.execute(env.groupClientID, RegisterFuncName, input)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(output, "success:"), output)
+	return env
+}
+
+// Execute a function of the multi-sig smart contract in a new transaction.
+func (env *testEnv) executeIn(clientID, funcName string, input []byte) (cstate.StateContextI, string, error) {
+	env.txns++
+	b := block.Provider().(*block.Block)
+	b.CreationDate = env.now
+	txn := &transaction.Transaction{
+		ClientID:   clientID,
+		ToClientID: Address,
+	}
+	txn.Hash = encryption.Hash("txn " + strconv.Itoa(env.txns))
+	txn.CreationDate = env.now
+	balances := cstate.NewStateContext(b, env.mpt, &state.Deserializer{}, txn, nil, nil, nil)
+	output, err := env.ms.Execute(txn, funcName, input, balances)
+	return balances, output, err
+}
+
+func (env *testEnv) execute(clientID, funcName string, input []byte) (string, error) {
+	_, output, err := env.executeIn(clientID, funcName, input)
+	return output, err
+}
+
+func (env *testEnv) callVote(proposalID string, signer int, call *SmartContractCall, value state.Balance) Vote {
+	transfer := state.Transfer{
+		ClientID:   env.groupClientID,
+		ToClientID: call.Address,
+		Amount:     value,
+	}
+	sig, err := env.signerKeys[signer].Sign(call.hash(transfer))
+	require.NoError(env.t, err)
+	return Vote{
+		ProposalID: proposalID,
+		Transfer:   transfer,
+		Call:       call,
+		Signature:  sig,
+	}
+}
+
+func (env *testEnv) updateVote(proposalID string, version int, signer encryption.ThresholdSignatureScheme, u *WalletUpdate) Vote {
+	transfer := state.Transfer{
+		ClientID:   env.groupClientID,
+		ToClientID: Address,
+	}
+	sig, err := signer.Sign(u.hash(proposalID, version, transfer))
+	require.NoError(env.t, err)
+	return Vote{
+		ProposalID: proposalID,
+		Transfer:   transfer,
+		Update:     u,
+		Signature:  sig,
+	}
+}
+
+// Re-split the group key for new signers. Returns the keys of the new signers
+// and the update replacing the current signers with them.
+func (env *testEnv) resplit(tn, n int) ([]encryption.ThresholdSignatureScheme, *WalletUpdate) {
+	keys, err := encryption.GenerateThresholdKeyShares("bls0chain", tn, n, env.groupKey)
+	require.NoError(env.t, err)
+	u := &WalletUpdate{NumRequired: tn}
+	for _, key := range env.signerKeys {
+		u.RemoveSignerThresholdIDs = append(u.RemoveSignerThresholdIDs, key.GetID())
+	}
+	for _, key := range keys {
+		u.AddSignerThresholdIDs = append(u.AddSignerThresholdIDs, key.GetID())
+		u.AddSignerPublicKeys = append(u.AddSignerPublicKeys, key.GetPublicKey())
+	}
+	return keys, u
+}
+
+func (env *testEnv) setSigners(keys []encryption.ThresholdSignatureScheme) {
+	env.signerKeys, env.signerClientIDs = keys, nil
+	for _, key := range keys {
+		env.signerClientIDs = append(env.signerClientIDs, clientIDForKey(key))
+	}
+}
+
+// State context to read the state, or to call REST handlers with.
+func (env *testEnv) balances() cstate.StateContextI {
+	return cstate.NewStateContext(nil, env.mpt, &state.Deserializer{}, &transaction.Transaction{}, nil, nil, nil)
+}
+
+func (env *testEnv) wallet() Wallet {
+	w, err := env.ms.getWallet(env.groupClientID, env.balances())
+	require.NoError(env.t, err)
+	return w
+}
+
+// IDs of the pending proposals of the wallet, walking the list.
+func (env *testEnv) pendingProposalIDs() []string {
+	balances := env.balances()
+	wp, err := env.ms.getWalletProposals(env.groupClientID, balances)
+	require.NoError(env.t, err)
+	ids := []string{}
+	for ref := wp.Head; ref != (proposalRef{}); {
+		p, err := env.ms.getProposal(ref, balances)
+		require.NoError(env.t, err)
+		require.False(env.t, p.isEmpty(), "broken list")
+		ids = append(ids, p.ProposalID)
+		ref = p.WalletNext
+	}
+	return ids
+}
+
+func (env *testEnv) vote(signer int, v Vote) (cstate.StateContextI, string, error) {
+	input, err := json.Marshal(v)
+	require.NoError(env.t, err)
+	return env.executeIn(env.signerClientIDs[signer], VoteFuncName, input)
+}
+
+func TestMultiSigSmartContract_executeCall(t *testing.T) {
+	smartcontract.ContractMap[testCallAddress] = &testCallSC{}
+	defer delete(smartcontract.ContractMap, testCallAddress)
+
+	env := newTestEnv(t, 2, 3)
+
+	call := &SmartContractCall{
+		Address:      testCallAddress,
+		FunctionName: "echo",
+		InputData:    json.RawMessage(`{"x":1}`),
+	}
+
+	// The first vote isn't enough.
+	_, output, err := env.vote(0, env.callVote("call", 0, call, 10))
+	require.NoError(t, err)
+	assert.Equal(t, "success 1: need 1 more votes", output)
+
+	// The second one executes the call on behalf of the wallet.
+	balances, output, err := env.vote(2, env.callVote("call", 2, call, 10))
+	require.NoError(t, err)
+	assert.Equal(t, `success 0: smart contract call executed with output called echo {"x":1}`, output)
+	require.NoError(t, balances.Validate())
+	assert.Equal(t, []*state.Transfer{
+		state.NewTransfer(env.groupClientID, testCallAddress, 10),
+	}, balances.GetTransfers())
+	events := balances.GetEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, "test_call", events[0].Type)
+	assert.Equal(t, env.groupClientID, events[0].Attributes["client_id"])
+
+	p, err := env.ms.getProposal(proposalRef{env.groupClientID, "call"}, balances)
+	require.NoError(t, err)
+	assert.Equal(t, balances.GetTransaction().Hash, p.ExecutedInTxnHash)
+
+	// Unnecessary votes don't call again.
+	balances, output, err = env.vote(1, env.callVote("call", 1, call, 10))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output, "success 0: proposal previously executed"), output)
+	assert.Len(t, balances.GetTransfers(), 0)
+
+	// The wallet can't spend more than the value of the call.
+	over := &SmartContractCall{Address: testCallAddress, FunctionName: "overspend"}
+	_, _, err = env.vote(0, env.callVote("over", 0, over, 10))
+	require.NoError(t, err)
+	balances, _, err = env.vote(1, env.callVote("over", 1, over, 10))
+	require.NoError(t, err)
+	assert.Equal(t, state.ErrInvalidTransfer, balances.Validate())
+
+	// Failed call fails the vote.
+	fail := &SmartContractCall{Address: testCallAddress, FunctionName: "fail"}
+	_, _, err = env.vote(0, env.callVote("fail", 0, fail, 0))
+	require.NoError(t, err)
+	_, _, err = env.vote(1, env.callVote("fail", 1, fail, 0))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_vote_call")
+
+	// Calls signed by a signer for another call aren't counted.
+	other := &SmartContractCall{Address: testCallAddress, FunctionName: "other"}
+	v := env.callVote("other", 0, other, 5)
+	v.Signature = env.callVote("other", 0, call, 5).Signature
+	_, _, err = env.vote(0, v)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_vote_auth")
+}
+
+func TestMultiSigSmartContract_executeUpdate(t *testing.T) {
+	env := newTestEnv(t, 2, 3)
+
+	// Raise the number of required signatures, the shares still recover the
+	// group key.
+	raise := &WalletUpdate{NumRequired: 3}
+	_, output, err := env.vote(0, env.updateVote("raise", 0, env.signerKeys[0], raise))
+	require.NoError(t, err)
+	assert.Equal(t, "success 1: need 1 more votes", output)
+
+	// The vote can't be replayed for another proposal.
+	replayed := env.updateVote("raise", 0, env.signerKeys[0], raise)
+	replayed.ProposalID = "replayed"
+	_, _, err = env.vote(0, replayed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_vote_auth")
+
+	_, output, err = env.vote(1, env.updateVote("raise", 0, env.signerKeys[1], raise))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output, "success 0: wallet updated"), output)
+	w := env.wallet()
+	assert.Equal(t, 1, w.Version)
+	assert.Equal(t, 3, w.NumRequired)
+
+	// Rotate the signers and change the threshold to 2 of 4.
+	keys, rotate := env.resplit(2, 4)
+
+	// Signed for the previous version of the wallet.
+	_, _, err = env.vote(0, env.updateVote("rotate", 0, env.signerKeys[0], rotate))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_vote_auth")
+
+	for i := 0; i < 3; i++ {
+		_, output, err = env.vote(i, env.updateVote("rotate", 1, env.signerKeys[i], rotate))
+		require.NoError(t, err)
+	}
+	assert.True(t, strings.HasPrefix(output, "success 0: wallet updated"), output)
+
+	oldKeys := env.signerKeys
+	env.setSigners(keys)
+	w = env.wallet()
+	assert.Equal(t, 2, w.Version)
+	assert.Equal(t, 2, w.NumRequired)
+	assert.Len(t, w.SignerPublicKeys, 4)
+	for _, key := range oldKeys {
+		assert.NotContains(t, w.SignerPublicKeys, key.GetPublicKey())
+	}
+
+	// The new signers, and only them, vote for the current version.
+	expire := &WalletUpdate{ProposalExpiration: MinExpirationTime}
+	_, _, err = env.vote(0, env.updateVote("expire", 1, env.signerKeys[0], expire))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_vote_auth")
+	input, err := json.Marshal(env.updateVote("expire", 2, oldKeys[0], expire))
+	require.NoError(t, err)
+	_, err = env.execute(clientIDForKey(oldKeys[0]), VoteFuncName, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_vote_auth")
+	for i := 2; i < 4; i++ {
+		_, output, err = env.vote(i, env.updateVote("expire", 2, env.signerKeys[i], expire))
+		require.NoError(t, err)
+	}
+	assert.True(t, strings.HasPrefix(output, "success 0: wallet updated"), output)
+	assert.EqualValues(t, MinExpirationTime, env.wallet().ProposalExpiration)
+}
+
+func TestMultiSigSmartContract_executeUpdate_invalidKeys(t *testing.T) {
+	env := newTestEnv(t, 2, 3)
+
+	// A share of another key can't be added.
+	other := encryption.NewBLS0ChainScheme()
+	require.NoError(t, other.GenerateKeys())
+	shares, err := encryption.GenerateThresholdKeyShares("bls0chain", 2, 4, other)
+	require.NoError(t, err)
+	add := &WalletUpdate{
+		AddSignerThresholdIDs: []string{shares[3].GetID()},
+		AddSignerPublicKeys:   []string{shares[3].GetPublicKey()},
+	}
+	_, _, err = env.vote(0, env.updateVote("add", 0, env.signerKeys[0], add))
+	require.NoError(t, err)
+	_, _, err = env.vote(1, env.updateVote("add", 0, env.signerKeys[1], add))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_update_invalid")
+	assert.Equal(t, 0, env.wallet().Version)
+
+	// Shares of 3 of 4 split can't be used by 2 signers.
+	_, rotate := env.resplit(3, 4)
+	rotate.NumRequired = 2
+	_, _, err = env.vote(0, env.updateVote("rotate", 0, env.signerKeys[0], rotate))
+	require.NoError(t, err)
+	_, _, err = env.vote(1, env.updateVote("rotate", 0, env.signerKeys[1], rotate))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "err_update_invalid")
+	assert.Equal(t, 0, env.wallet().Version)
+}
+
+func TestMultiSigSmartContract_pruneExpirationQueue(t *testing.T) {
+	smartcontract.ContractMap[testCallAddress] = &testCallSC{}
+	defer delete(smartcontract.ContractMap, testCallAddress)
+
+	env := newTestEnv(t, 2, 3)
+	call := &SmartContractCall{Address: testCallAddress, FunctionName: "echo"}
+
+	// More proposals than a vote prunes, the second one executed.
+	var ids []string
+	for i := 0; i < MaxPrunedPerVote+2; i++ {
+		id := "p" + strconv.Itoa(i)
+		_, _, err := env.vote(0, env.callVote(id, 0, call, 1))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	_, output, err := env.vote(1, env.callVote(ids[1], 1, call, 1))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(output, "success 0: smart contract call executed"), output)
+
+	pending := append([]string{ids[0]}, ids[2:]...)
+	assert.Equal(t, pending, env.pendingProposalIDs())
+
+	bucket := getExpirationBucket(env.now + ExpirationTime)
+	eb, err := env.ms.getExpirationBuckets(env.balances())
+	require.NoError(t, err)
+	assert.Equal(t, expirationBuckets{Oldest: bucket, Newest: bucket}, eb)
+
+	// Not expired yet, nothing pruned.
+	env.now += ExpirationTime - 1
+	_, _, err = env.vote(0, env.callVote("new", 0, call, 1))
+	require.NoError(t, err)
+	assert.Equal(t, append(pending, "new"), env.pendingProposalIDs())
+
+	// The bucket ended, a vote prunes MaxPrunedPerVote proposals, the oldest
+	// ones first, including the executed one.
+	env.now = common.Timestamp((bucket + 1) * ExpirationBucketSize)
+	_, _, err = env.vote(1, env.callVote("new", 1, call, 1))
+	require.NoError(t, err)
+	for i, id := range ids {
+		p, err := env.ms.getProposal(proposalRef{env.groupClientID, id}, env.balances())
+		require.NoError(t, err)
+		assert.Equal(t, i >= MaxPrunedPerVote, !p.isEmpty(), id)
+	}
+	assert.Equal(t, ids[MaxPrunedPerVote:], env.pendingProposalIDs())
+
+	// The rest is pruned by the next vote, the pruned bucket is skipped, and
+	// a pruned proposal ID is proposed again.
+	id := ids[MaxPrunedPerVote]
+	_, output, err = env.vote(0, env.callVote(id, 0, call, 1))
+	require.NoError(t, err)
+	assert.Equal(t, "success 1: need 1 more votes", output)
+	assert.Equal(t, []string{id}, env.pendingProposalIDs())
+	eb, err = env.ms.getExpirationBuckets(env.balances())
+	require.NoError(t, err)
+	assert.True(t, eb.Oldest > bucket, "bucket not pruned")
+	q, err := env.ms.getOrCreateExpirationQueue(bucket, env.balances())
+	require.NoError(t, err)
+	assert.True(t, q.isEmpty())
+	p, err := env.ms.getProposal(proposalRef{env.groupClientID, ids[len(ids)-1]}, env.balances())
+	require.NoError(t, err)
+	assert.True(t, p.isEmpty())
+}
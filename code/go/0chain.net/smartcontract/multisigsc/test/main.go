@@ -3,6 +3,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"math/rand"
@@ -13,6 +14,7 @@ import (
 	mptwallet "0chain.net/chaincore/wallet"
 	"0chain.net/core/encryption"
 	. "0chain.net/core/logging"
+	"0chain.net/smartcontract/faucetsc"
 	"0chain.net/smartcontract/multisigsc"
 	"go.uber.org/zap"
 )
@@ -76,23 +78,12 @@ func main() {
 	Logger.Info("")
 	time.Sleep(10 * time.Second)
 
-	if multisigsc.ExpirationTime <= 60 {
-		// For this to be true, you have to change the compile time constant.
-		// TODO: Put this value in the chain's config file so you don't have to
-		//       do a recompile.
-		testExpiration()
-
-		Logger.Info("")
-		Logger.Info("")
-		Logger.Info("")
-		time.Sleep(10 * time.Second)
-	} else {
-		Logger.Info("Multi-sig proposal expiration time is large, not testing expiration in this run")
-
-		Logger.Info("")
-		Logger.Info("")
-		Logger.Info("")
-	}
+	testExpiration()
+
+	Logger.Info("")
+	Logger.Info("")
+	Logger.Info("")
+	time.Sleep(10 * time.Second)
 
 	testFinishProposal()
 
@@ -101,6 +92,20 @@ func main() {
 	Logger.Info("")
 	time.Sleep(10 * time.Second)
 
+	testSignerRotation()
+
+	Logger.Info("")
+	Logger.Info("")
+	Logger.Info("")
+	time.Sleep(10 * time.Second)
+
+	testSmartContractCall()
+
+	Logger.Info("")
+	Logger.Info("")
+	Logger.Info("")
+	time.Sleep(10 * time.Second)
+
 	for i := 0; i < c.numWallets; i++ {
 		go testStress(i)
 	}
@@ -140,6 +145,9 @@ func testExpiration() {
 	// Generate a group key and associated sub-keys.
 	w := newTestWallet(0, c.signatureScheme, c.t, c.n)
 
+	// Use the shortest expiration time allowed.
+	w.expiration = multisigsc.MinExpirationTime
+
 	// Register MPT wallets for everyone in our group and give them some tokens
 	// to play with.
 	w.registerMPTWallets()
@@ -172,8 +180,8 @@ func doExpiredProposal(w testWallet) {
 	}
 
 	// Let the proposal expire.
-	Logger.Info("Waiting until proposal expires...", zap.Int("seconds", multisigsc.ExpirationTime))
-	time.Sleep(multisigsc.ExpirationTime * time.Second)
+	Logger.Info("Waiting until proposal expires...", zap.Int64("seconds", int64(w.expiration)))
+	time.Sleep(time.Duration(w.expiration) * time.Second)
 
 	// This should re-create it, which means we'll get the same output as above.
 	output2 := w.registerVote(p, signer)
@@ -234,6 +242,101 @@ func doProposalWithAllN(w testWallet) {
 	}
 }
 
+func testSignerRotation() {
+	Logger.Info("Testing multi-sig signer rotation...")
+
+	// Generate a group key and associated sub-keys.
+	w := newTestWallet(0, c.signatureScheme, c.t, c.n)
+
+	// Register MPT wallets for everyone in our group and give them some tokens
+	// to play with.
+	w.registerMPTWallets()
+
+	output := w.registerSCWallet()
+	if !strings.HasPrefix(output, "success:") {
+		Logger.Fatal("Register failed: TxnOutput should have prefix 'success:'")
+	}
+
+	// Start the real test...
+	rotated, u := w.resplit(c.t, c.n+1)
+	p := w.newUpdateProposal("rotation", 0, u)
+
+	for i, signer := range w.signerClientIDs[:w.t] {
+		output := w.registerVote(p, signer)
+
+		expectedOutput := fmt.Sprintf("success %d:", w.t-(i+1))
+		if i+1 == w.t {
+			expectedOutput = "success 0: wallet updated"
+		}
+
+		if !strings.HasPrefix(output, expectedOutput) {
+			Logger.Fatal("Update vote failed: TxnOutput should have prefix '" + expectedOutput + "'")
+		}
+	}
+
+	// The new signers should be able to transfer tokens now.
+	owner := getOwnerWallet(c.signatureScheme, c.ownerKeysFile)
+	for _, mptWallet := range rotated.getSignerMPTWallets() {
+		registerMPTWallet(mptWallet)
+		airdrop(owner, mptWallet.ClientID)
+	}
+
+	doProposalWithAllN(rotated)
+	printBalance(0, rotated)
+
+	Logger.Info("Finished test")
+}
+
+func testSmartContractCall() {
+	Logger.Info("Testing multi-sig smart contract call...")
+
+	// Generate a group key and associated sub-keys.
+	w := newTestWallet(0, c.signatureScheme, c.t, c.n)
+
+	// Register MPT wallets for everyone in our group and give them some tokens
+	// to play with.
+	w.registerMPTWallets()
+
+	output := w.registerSCWallet()
+	if !strings.HasPrefix(output, "success:") {
+		Logger.Fatal("Register failed: TxnOutput should have prefix 'success:'")
+	}
+
+	// Start the real test...
+	const value = 100
+
+	before := getBalance(w.groupClientID)
+
+	// The wallet refills the faucet, the value of the call is taken from the
+	// wallet.
+	call := multisigsc.SmartContractCall{
+		Address:      faucetsc.ADDRESS,
+		FunctionName: "refill",
+		InputData:    json.RawMessage("{}"),
+	}
+	p := w.newCallProposal("faucet refill", call, value)
+
+	for i, signer := range w.signerClientIDs[:w.t] {
+		output := w.registerVote(p, signer)
+
+		expectedOutput := fmt.Sprintf("success %d:", w.t-(i+1))
+		if i+1 == w.t {
+			expectedOutput = "success 0: smart contract call executed"
+		}
+
+		if !strings.HasPrefix(output, expectedOutput) {
+			Logger.Fatal("Call vote failed: TxnOutput should have prefix '" + expectedOutput + "'")
+		}
+	}
+
+	after := getBalance(w.groupClientID)
+	if before-after != value {
+		Logger.Fatal("Call failed: the value of the call should be taken from the wallet", zap.Int64("before", int64(before)), zap.Int64("after", int64(after)))
+	}
+
+	Logger.Info("Finished test")
+}
+
 func testStress(id int) {
 	Logger.Info("Stress testing multi-sig transfers...", zap.Int("worker#", id))
 
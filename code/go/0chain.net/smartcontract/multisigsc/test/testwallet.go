@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
+
 	"0chain.net/chaincore/httpclientutil"
 	"0chain.net/chaincore/state"
 	mptwallet "0chain.net/chaincore/wallet"
+	"0chain.net/core/common"
 	"0chain.net/core/encryption"
 	. "0chain.net/core/logging"
 	"0chain.net/smartcontract/multisigsc"
@@ -24,6 +27,9 @@ type testWallet struct {
 	signerKeys      []encryption.ThresholdSignatureScheme
 
 	t, n int
+
+	// Proposal expiration time, the default if zero.
+	expiration common.Timestamp
 }
 
 type testProposal struct {
@@ -105,6 +111,8 @@ func (t testWallet) registerSCWallet() string {
 			SignerPublicKeys:   signerPublicKeys,
 
 			NumRequired: t.t,
+
+			ProposalExpiration: t.expiration,
 		},
 	}
 
@@ -228,3 +236,102 @@ func (t testWallet) toWallet() multisigsc.Wallet {
 		SignatureScheme:    t.signatureScheme,
 	}
 }
+
+// Re-split the group key for a new set of signers. Returns the wallet with the
+// new signers and the update replacing the old signers with them.
+func (t testWallet) resplit(newT, newN int) (testWallet, multisigsc.WalletUpdate) {
+	signerKeys, err := encryption.GenerateThresholdKeyShares(t.signatureScheme, newT, newN, t.groupKey)
+	if err != nil {
+		Logger.Fatal("Failed to re-split group key", zap.Error(err))
+	}
+
+	var u multisigsc.WalletUpdate
+
+	for _, signer := range t.signerKeys {
+		u.RemoveSignerThresholdIDs = append(u.RemoveSignerThresholdIDs, signer.GetID())
+	}
+
+	var signerClientIDs []string
+	for _, key := range signerKeys {
+		signerClientIDs = append(signerClientIDs, clientIDForKey(key))
+
+		u.AddSignerThresholdIDs = append(u.AddSignerThresholdIDs, key.GetID())
+		u.AddSignerPublicKeys = append(u.AddSignerPublicKeys, key.GetPublicKey())
+	}
+
+	if newT != t.t {
+		u.NumRequired = newT
+	}
+
+	t.signerClientIDs = signerClientIDs
+	t.signerKeys = signerKeys
+	t.t, t.n = newT, newN
+
+	return t, u
+}
+
+// The update is signed along with the proposal ID and the current version of
+// the wallet.
+func (t testWallet) newUpdateProposal(proposalID string, version int, u multisigsc.WalletUpdate) testProposal {
+	transfer := state.Transfer{
+		ClientID:   t.groupClientID,
+		ToClientID: multisigsc.Address,
+	}
+
+	data, _ := json.Marshal(struct {
+		ProposalID    string                   `json:"proposal_id"`
+		WalletVersion int                      `json:"wallet_version"`
+		Update        *multisigsc.WalletUpdate `json:"update"`
+	}{proposalID, version, &u})
+	hash := encryption.Hash(append(transfer.Encode(), data...))
+
+	votes := make(map[string]multisigsc.Vote)
+
+	for i, signer := range t.signerKeys {
+		sig, err := signer.Sign(hash)
+		if err != nil {
+			Logger.Fatal("Failed to sign wallet update", zap.Error(err))
+		}
+
+		votes[t.signerClientIDs[i]] = multisigsc.Vote{
+			ProposalID: proposalID,
+			Transfer:   transfer,
+			Update:     &u,
+			Signature:  sig,
+		}
+	}
+
+	return testProposal{
+		votes: votes,
+	}
+}
+
+func (t testWallet) newCallProposal(proposalID string, call multisigsc.SmartContractCall, value int64) testProposal {
+	transfer := state.Transfer{
+		ClientID:   t.groupClientID,
+		ToClientID: call.Address,
+		Amount:     state.Balance(value),
+	}
+
+	hash := encryption.Hash(append(transfer.Encode(), call.Encode()...))
+
+	votes := make(map[string]multisigsc.Vote)
+
+	for i, signer := range t.signerKeys {
+		sig, err := signer.Sign(hash)
+		if err != nil {
+			Logger.Fatal("Failed to sign smart contract call", zap.Error(err))
+		}
+
+		votes[t.signerClientIDs[i]] = multisigsc.Vote{
+			ProposalID: proposalID,
+			Transfer:   transfer,
+			Call:       &call,
+			Signature:  sig,
+		}
+	}
+
+	return testProposal{
+		votes: votes,
+	}
+}
@@ -0,0 +1,153 @@
+package multisigsc
+
+import (
+	"encoding/json"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+// Governance update of a registered multi-sig wallet. It is proposed and voted
+// on like a transfer, but instead of moving tokens it changes the signers and
+// the number of required signatures, or the expiration time of proposals,
+// once enough votes are collected.
+//
+// The wallet's group key never changes (the wallet's client ID is derived from
+// it), so the new signer keys must be shares of the same group key re-split
+// off-chain for the resulting set of signers and number of required votes.
+// Signers are replaced by removing the old ones and adding the new ones in a
+// single update.
+type WalletUpdate struct {
+	AddSignerThresholdIDs []string `json:"add_signer_threshold_ids,omitempty"`
+	AddSignerPublicKeys   []string `json:"add_signer_public_keys,omitempty"`
+
+	RemoveSignerThresholdIDs []string `json:"remove_signer_threshold_ids,omitempty"`
+
+	// Zero keeps the current number of required signatures.
+	NumRequired int `json:"num_required,omitempty"`
+
+	// Zero keeps the current expiration time of proposals. It applies to
+	// proposals created after the update.
+	ProposalExpiration common.Timestamp `json:"proposal_expiration,omitempty"`
+}
+
+func (u *WalletUpdate) Encode() []byte {
+	buff, _ := json.Marshal(u)
+	return buff
+}
+
+func (u *WalletUpdate) Decode(input []byte) error {
+	err := json.Unmarshal(input, u)
+	return err
+}
+
+func (u *WalletUpdate) isEmpty() bool {
+	return len(u.AddSignerThresholdIDs) == 0 &&
+		len(u.AddSignerPublicKeys) == 0 &&
+		len(u.RemoveSignerThresholdIDs) == 0 &&
+		u.NumRequired == 0 &&
+		u.ProposalExpiration == 0
+}
+
+func (u *WalletUpdate) notTooBig() bool {
+	if len(u.AddSignerThresholdIDs) > MaxSigners ||
+		len(u.AddSignerPublicKeys) > MaxSigners ||
+		len(u.RemoveSignerThresholdIDs) > MaxSigners {
+		return false
+	}
+	for _, ss := range [][]string{
+		u.AddSignerThresholdIDs,
+		u.AddSignerPublicKeys,
+		u.RemoveSignerThresholdIDs,
+	} {
+		for _, s := range ss {
+			if len(s) > MaxFieldSize {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Data of the update signed by the signers. The proposal ID and the version
+// of the wallet bind the signatures to the proposal and to the signers of the
+// version, so they can't be replayed for another proposal or after the
+// wallet is updated.
+type walletUpdateSigningData struct {
+	ProposalID    string        `json:"proposal_id"`
+	WalletVersion int           `json:"wallet_version"`
+	Update        *WalletUpdate `json:"update"`
+}
+
+// Hash signed by the signers voting for the update. It covers the vote's
+// transfer too, which identifies the wallet.
+func (u *WalletUpdate) hash(proposalID string, version int, t state.Transfer) string {
+	buff, _ := json.Marshal(walletUpdateSigningData{
+		ProposalID:    proposalID,
+		WalletVersion: version,
+		Update:        u,
+	})
+	return signedHash(t, buff)
+}
+
+// Apply the update to a copy of the wallet and check the result is still a
+// valid multi-sig wallet.
+func (u *WalletUpdate) apply(w Wallet) (Wallet, error) {
+	if len(u.AddSignerThresholdIDs) != len(u.AddSignerPublicKeys) {
+		return Wallet{}, common.NewError("err_update_invalid", "number of added signer ids and signer public keys do not match")
+	}
+
+	ids := make([]string, 0, len(w.SignerThresholdIDs)+len(u.AddSignerThresholdIDs))
+	keys := make([]string, 0, len(w.SignerPublicKeys)+len(u.AddSignerPublicKeys))
+
+	removed := make(map[string]bool, len(u.RemoveSignerThresholdIDs))
+	for _, id := range u.RemoveSignerThresholdIDs {
+		if w.publicKeyForThresholdID(id) == "" {
+			return Wallet{}, common.NewError("err_update_invalid", "no signer with threshold id "+id)
+		}
+		removed[id] = true
+	}
+
+	for i, id := range w.SignerThresholdIDs {
+		if removed[id] {
+			continue
+		}
+		ids = append(ids, id)
+		keys = append(keys, w.SignerPublicKeys[i])
+	}
+
+	w.SignerThresholdIDs = append(ids, u.AddSignerThresholdIDs...)
+	w.SignerPublicKeys = append(keys, u.AddSignerPublicKeys...)
+
+	if u.NumRequired != 0 {
+		w.NumRequired = u.NumRequired
+	}
+	if u.ProposalExpiration != 0 {
+		w.ProposalExpiration = u.ProposalExpiration
+	}
+
+	w.Version++
+
+	// Checks the MinSigners and MaxSigners bounds, duplicates and keys.
+	_, err := w.valid(w.ClientID)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	// Signatures of the signers must recover signatures of the group key.
+	err = encryption.VerifyThresholdKeyShares(w.SignatureScheme, w.NumRequired,
+		w.PublicKey, w.SignerThresholdIDs, w.SignerPublicKeys)
+	if err != nil {
+		return Wallet{}, common.NewError("err_update_invalid", "signer keys are not shares of the group key: "+err.Error())
+	}
+
+	return w, nil
+}
+
+func sameUpdate(a, b *WalletUpdate) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return string(a.Encode()) == string(b.Encode())
+}
@@ -1,9 +1,11 @@
 package storagesc
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"strconv"
@@ -13,6 +15,7 @@ import (
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
+	"0chain.net/core/encryption"
 	"0chain.net/core/util"
 )
 
@@ -113,6 +116,18 @@ func (sc *StorageSmartContract) addAllocation(alloc *StorageAllocation,
 			"saving new allocation: %v", err)
 	}
 
+	err = sc.addToIndex(allocationsIndexName, alloc.ID, all.List, balances)
+	if err != nil {
+		return "", common.NewErrorf("add_allocation_failed",
+			"saving allocations index: %v", err)
+	}
+	err = sc.addToIndex(ownerAllocationsIndexName(alloc.Owner), alloc.ID,
+		clients.List, balances)
+	if err != nil {
+		return "", common.NewErrorf("add_allocation_failed",
+			"saving client allocations index: %v", err)
+	}
+
 	buff := alloc.Encode()
 	return string(buff), nil
 }
@@ -208,11 +223,15 @@ func sizeInGB(size int64) float64 {
 	return float64(size) / GB
 }
 
-// exclude blobbers with not enough token in stake pool to fit the size
+// exclude blobbers with not enough token in stake pool to fit the size and
+// blobbers don't accept new allocations
 func (sc *StorageSmartContract) filterBlobbersByFreeSpace(now common.Timestamp,
 	size int64, balances chainstate.StateContextI) (filter filterBlobberFunc) {
 
 	return filterBlobberFunc(func(b *StorageNode) (kick bool) {
+		if !b.isActive() {
+			return true // kick off draining blobbers
+		}
 		var sp, err = sc.getStakePool(b.ID, balances)
 		if err != nil {
 			return true // kick off
@@ -270,7 +289,8 @@ func (sc *StorageSmartContract) newAllocationRequest(t *transaction.Transaction,
 		// filtered list
 		list = sa.filterBlobbers(allBlobbersList.Nodes.copy(), t.CreationDate,
 			bsize, filterHealthyBlobbers(t.CreationDate),
-			sc.filterBlobbersByFreeSpace(t.CreationDate, bsize, balances))
+			sc.filterBlobbersByFreeSpace(t.CreationDate, bsize, balances),
+			sc.filterBlobbersByReputation(conf, t.CreationDate, balances))
 	)
 
 	if len(list) < size {
@@ -361,6 +381,7 @@ func (sc *StorageSmartContract) newAllocationRequest(t *transaction.Transaction,
 			"failed to store the allocation request")
 	}
 
+	emitAllocationCreated(sa, balances)
 	return // the resp
 }
 
@@ -396,6 +417,26 @@ func (uar *updateAllocationRequest) validate(conf *scConfig,
 	return
 }
 
+// validateChanges of an allocation not closing by the request
+func (uar *updateAllocationRequest) validateChanges(conf *scConfig,
+	alloc *StorageAllocation, now common.Timestamp) (err error) {
+
+	// an allocation can't be shorter then configured in SC
+	// (prevent allocation shortening for entire period)
+	var newExpiration = alloc.Expiration + uar.Expiration
+	if uar.Expiration < 0 &&
+		newExpiration-now < toSeconds(conf.MinAllocDuration) {
+
+		return errors.New("allocation duration becomes too short")
+	}
+
+	if uar.Size < 0 && alloc.Size+uar.Size < conf.MinAllocSize {
+		return errors.New("allocation size becomes too small")
+	}
+
+	return
+}
+
 // calculate size difference for every blobber of the allocations
 func (uar *updateAllocationRequest) getBlobbersSizeDiff(
 	alloc *StorageAllocation) (diff int64) {
@@ -544,29 +585,36 @@ func weightedAverage(prev, next *Terms, tx, pexp, expDiff common.Timestamp,
 	return
 }
 
-// The adjustChallengePool moves more or moves some tokens back from or to
-// challenge pool during allocation extending or reducing.
-func (sc *StorageSmartContract) adjustChallengePool(alloc *StorageAllocation,
-	wp *writePool, odr, ndr common.Timestamp, oterms []Terms,
-	now common.Timestamp, balances chainstate.StateContextI) (err error) {
-
-	var (
-		changes = alloc.challengePoolChanges(odr, ndr, oterms)
-		cp      *challengePool
-	)
+// The moveUpdateTokens checks the write pool has enough tokens for the rest
+// of min lock demand of an allocation which size increased, and moves more or
+// moves some tokens back from or to challenge pool during allocation extending
+// or reducing. It changes given pools without saving them and returns tokens
+// moved to (positive) or back from (negative) the challenge pool per blobber.
+// The update_allocation_request transaction and the allocation update quote
+// share it, the quote uses copies of the pools.
+func moveUpdateTokens(alloc *StorageAllocation, wp *writePool,
+	cp *challengePool, odr, ndr common.Timestamp, oterms []Terms,
+	sizeIncreased bool, now common.Timestamp) (changes []state.Balance,
+	err error) {
 
-	if cp, err = sc.getChallengePool(alloc.ID, balances); err != nil {
-		return fmt.Errorf("adjust_challenge_pool: %v", err)
+	// is it about size increasing? if so, we should make sure the write
+	// pool has enough tokens
+	if sizeIncreased {
+		if mldLeft := alloc.restMinLockDemand(); mldLeft > 0 {
+			if wp.allocUntil(alloc.ID, alloc.Until()) < mldLeft {
+				return nil, errors.New("not enough tokens in write pool" +
+					" to extend allocation")
+			}
+		}
 	}
 
-	var changed bool
+	changes = alloc.challengePoolChanges(odr, ndr, oterms)
 
 	for i, ch := range changes {
 		var blobID = alloc.BlobberDetails[i].BlobberID
 		switch {
 		case ch > 0:
 			err = wp.moveToChallenge(alloc.ID, blobID, cp, now, ch)
-			changed = true
 		case ch < 0:
 			// only if the challenge pool has the tokens; all the tokens
 			// can be moved back already, or moved to a blobber due to
@@ -574,42 +622,126 @@ func (sc *StorageSmartContract) adjustChallengePool(alloc *StorageAllocation,
 			if cp.Balance >= -ch {
 				err = cp.moveToWritePool(alloc.ID, blobID, alloc.Until(), wp,
 					-ch)
-				changed = true
+			} else {
+				changes[i] = 0 // not moved
 			}
 		default:
 			// no changes for the blobber
 		}
 		if err != nil {
-			return fmt.Errorf("adjust_challenge_pool: %v", err)
+			return nil, fmt.Errorf("adjust_challenge_pool: %v", err)
 		}
 	}
 
-	if changed {
-		err = cp.save(sc.ID, alloc.ID, balances)
+	return
+}
+
+// The updateAllocationTokens moves tokens between the write pool and the
+// challenge pool of an updated allocation, see moveUpdateTokens, and saves
+// the challenge pool; the write pool should be saved by caller.
+func (sc *StorageSmartContract) updateAllocationTokens(
+	alloc *StorageAllocation, wp *writePool, odr, ndr common.Timestamp,
+	oterms []Terms, sizeIncreased bool, now common.Timestamp,
+	balances chainstate.StateContextI) (err error) {
+
+	var cp *challengePool
+	if cp, err = sc.getChallengePool(alloc.ID, balances); err != nil {
+		return fmt.Errorf("adjust_challenge_pool: %v", err)
 	}
 
-	return
+	var changes []state.Balance
+	changes, err = moveUpdateTokens(alloc, wp, cp, odr, ndr, oterms,
+		sizeIncreased, now)
+	if err != nil {
+		return
+	}
+
+	for _, ch := range changes {
+		if ch != 0 {
+			return cp.save(sc.ID, alloc.ID, balances)
+		}
+	}
+
+	return // nothing changed
 }
 
-// extendAllocation extends size or/and expiration (one of them can be reduced);
-// here we use new terms of blobbers
-func (sc *StorageSmartContract) extendAllocation(t *transaction.Transaction,
-	all *StorageNodes, alloc *StorageAllocation, blobbers []*StorageNode,
-	uar *updateAllocationRequest, balances chainstate.StateContextI) (
-	resp string, err error) {
+// The quoteUpdateTokens returns minimal number of tokens an allocation
+// updating transaction should add to the write pool, trying the
+// moveUpdateTokens against copies of the pools, and changes of the challenge
+// pool the transaction makes.
+func quoteUpdateTokens(alloc *StorageAllocation, wp *writePool,
+	cp *challengePool, odr, ndr common.Timestamp, oterms []Terms,
+	sizeIncreased bool, now common.Timestamp) (required state.Balance,
+	changes []state.Balance, err error) {
+
+	var try = func(value state.Balance) ([]state.Balance, error) {
+		var (
+			twp = new(writePool)
+			tcp = newChallengePool()
+		)
+		if err := twp.Decode(wp.Encode()); err != nil {
+			return nil, err
+		}
+		if err := tcp.Decode(cp.Encode()); err != nil {
+			return nil, err
+		}
+		if value > 0 {
+			// see writePool.fill
+			var ap = new(allocationPool)
+			ap.Balance = value
+			ap.AllocationID = alloc.ID
+			ap.ExpireAt = alloc.Until()
+			ap.Blobbers = fillBlobbers(alloc, value)
+			twp.Pools.add(ap)
+		}
+		return moveUpdateTokens(alloc, twp, tcp, odr, ndr, oterms,
+			sizeIncreased, now)
+	}
+
+	if changes, err = try(0); err == nil {
+		return // the write pool has enough tokens
+	}
+
+	// the transaction succeeds with more tokens only, find the minimum
+	var lo, hi state.Balance = 0, 1
+	for {
+		if changes, err = try(hi); err == nil {
+			break
+		}
+		if hi > math.MaxInt64/2 {
+			return 0, nil, err
+		}
+		lo, hi = hi, hi*2
+	}
+	for hi-lo > 1 {
+		var mid = lo + (hi-lo)/2
+		if mc, merr := try(mid); merr == nil {
+			hi, changes = mid, mc
+		} else {
+			lo = mid
+		}
+	}
+
+	return hi, changes, nil
+}
+
+// extendTerms updates expiration, size, used capacity of the blobbers and
+// terms of the allocation; it uses weighted average of current terms and new
+// terms of the blobbers; it returns original terms of the blobbers
+func (uar *updateAllocationRequest) extendTerms(alloc *StorageAllocation,
+	blobbers []*StorageNode, now common.Timestamp) (oterms []Terms,
+	err error) {
 
 	var (
 		diff   = uar.getBlobbersSizeDiff(alloc) // size difference
 		size   = uar.getNewBlobbersSize(alloc)  // blobber size
 		gbSize = sizeInGB(size)                 // blobber size in GB
 		cct    time.Duration                    // new challenge_completion_time
-
-		// keep original terms to adjust challenge pool value
-		oterms = make([]Terms, 0, len(alloc.BlobberDetails))
-		// original allocation duration remains
-		odr = alloc.Expiration - t.CreationDate
 	)
 
+	// keep original terms to adjust challenge pool value
+	oterms = make([]Terms, 0, len(alloc.BlobberDetails))
+
 	// adjust the expiration if changed, boundaries has already checked
 	var prevExpiration = alloc.Expiration
 	alloc.Expiration += uar.Expiration // new expiration
@@ -621,13 +753,13 @@ func (sc *StorageSmartContract) extendAllocation(t *transaction.Transaction,
 
 		var b = blobbers[i]
 		if b.Capacity == 0 {
-			return "", common.NewErrorf("allocation_extending_failed",
-				"blobber %s no longer provides its service", b.ID)
+			return nil, fmt.Errorf("blobber %s no longer provides its service",
+				b.ID)
 		}
 		if uar.Size > 0 {
 			if b.Capacity-b.Used-diff < 0 {
-				return "", common.NewErrorf("allocation_extending_failed",
-					"blobber %s doesn't have enough free space", b.ID)
+				return nil, fmt.Errorf("blobber %s doesn't have enough free"+
+					" space", b.ID)
 			}
 		}
 
@@ -635,14 +767,13 @@ func (sc *StorageSmartContract) extendAllocation(t *transaction.Transaction,
 
 		// update terms using weighted average
 		details.Terms = weightedAverage(&details.Terms, &b.Terms,
-			t.CreationDate, prevExpiration, alloc.Expiration, details.Size,
-			diff)
+			now, prevExpiration, alloc.Expiration, details.Size, diff)
 
 		details.Size = size // new size
 
 		if uar.Expiration > toSeconds(b.Terms.MaxOfferDuration) {
-			return "", common.NewErrorf("allocation_extending_failed",
-				"blobber %s doesn't allow so long offers", b.ID)
+			return nil, fmt.Errorf("blobber %s doesn't allow so long offers",
+				b.ID)
 		}
 
 		if b.Terms.ChallengeCompletionTime > cct {
@@ -668,6 +799,28 @@ func (sc *StorageSmartContract) extendAllocation(t *transaction.Transaction,
 
 	// update max challenge_completion_time
 	alloc.ChallengeCompletionTime = cct
+	return
+}
+
+// extendAllocation extends size or/and expiration (one of them can be reduced);
+// here we use new terms of blobbers
+func (sc *StorageSmartContract) extendAllocation(t *transaction.Transaction,
+	all *StorageNodes, alloc *StorageAllocation, blobbers []*StorageNode,
+	uar *updateAllocationRequest, balances chainstate.StateContextI) (
+	resp string, err error) {
+
+	var (
+		diff = uar.getBlobbersSizeDiff(alloc) // size difference
+
+		// original allocation duration remains
+		odr = alloc.Expiration - t.CreationDate
+		// original terms
+		oterms []Terms
+	)
+
+	if oterms, err = uar.extendTerms(alloc, blobbers, t.CreationDate); err != nil {
+		return "", common.NewError("allocation_extending_failed", err.Error())
+	}
 
 	// extend offers after alloc.challenge_completion_time is known
 	for _, ba := range alloc.BlobberDetails {
@@ -698,21 +851,11 @@ func (sc *StorageSmartContract) extendAllocation(t *transaction.Transaction,
 		}
 	}
 
-	// is it about size increasing? if so, we should make sure the write
-	// pool has enough tokens
-	if diff > 0 {
-		if mldLeft := alloc.restMinLockDemand(); mldLeft > 0 {
-			if wp.allocUntil(alloc.ID, until) < mldLeft {
-				return "", common.NewError("allocation_extending_failed",
-					"not enough tokens in write pool to extend allocation")
-			}
-		}
-	}
-
-	// add more tokens to related challenge pool, or move some tokens back
+	// make sure the write pool has enough tokens if size increased, and add
+	// more tokens to related challenge pool, or move some tokens back
 	var ndr = alloc.Expiration - t.CreationDate
-	err = sc.adjustChallengePool(alloc, wp, odr, ndr, oterms, t.CreationDate,
-		balances)
+	err = sc.updateAllocationTokens(alloc, wp, odr, ndr, oterms, diff > 0,
+		t.CreationDate, balances)
 	if err != nil {
 		return "", common.NewErrorf("allocation_extending_failed", "%v", err)
 	}
@@ -732,31 +875,40 @@ func (sc *StorageSmartContract) extendAllocation(t *transaction.Transaction,
 	return string(alloc.Encode()), nil
 }
 
-// reduceAllocation reduces size or/and expiration (no one can be increased);
-// here we use the same terms of related blobbers
-func (sc *StorageSmartContract) reduceAllocation(t *transaction.Transaction,
-	all *StorageNodes, alloc *StorageAllocation, blobbers []*StorageNode,
-	uar *updateAllocationRequest, balances chainstate.StateContextI) (
-	resp string, err error) {
+// reduceTerms updates expiration, size and used capacity of the blobbers
+// keeping terms of the allocation
+func (uar *updateAllocationRequest) reduceTerms(alloc *StorageAllocation,
+	blobbers []*StorageNode) {
 
 	var (
 		diff = uar.getBlobbersSizeDiff(alloc) // size difference
 		size = uar.getNewBlobbersSize(alloc)  // blobber size
-
-		// original allocation duration remains
-		odr = alloc.Expiration - t.CreationDate
 	)
 
 	// adjust the expiration if changed, boundaries has already checked
 	alloc.Expiration += uar.Expiration
 	alloc.Size += uar.Size
 
-	// 1. update terms
 	for i, ba := range alloc.BlobberDetails {
-		var b = blobbers[i]
-		b.Used += diff // new capacity used
-		ba.Size = size // new size
-		// update stake pool
+		blobbers[i].Used += diff // new capacity used
+		ba.Size = size           // new size
+	}
+}
+
+// reduceAllocation reduces size or/and expiration (no one can be increased);
+// here we use the same terms of related blobbers
+func (sc *StorageSmartContract) reduceAllocation(t *transaction.Transaction,
+	all *StorageNodes, alloc *StorageAllocation, blobbers []*StorageNode,
+	uar *updateAllocationRequest, balances chainstate.StateContextI) (
+	resp string, err error) {
+
+	// original allocation duration remains
+	var odr = alloc.Expiration - t.CreationDate
+
+	uar.reduceTerms(alloc, blobbers)
+
+	// 1. update stake pools
+	for _, ba := range alloc.BlobberDetails {
 		if err = sc.updateSakePoolOffer(ba, alloc, balances); err != nil {
 			return "", common.NewErrorf("allocation_reducing_failed", "%v", err)
 		}
@@ -782,8 +934,8 @@ func (sc *StorageSmartContract) reduceAllocation(t *transaction.Transaction,
 
 	// new allocation duration remains
 	var ndr = alloc.Expiration - t.CreationDate
-	err = sc.adjustChallengePool(alloc, wp, odr, ndr, nil, t.CreationDate,
-		balances)
+	err = sc.updateAllocationTokens(alloc, wp, odr, ndr, nil, false,
+		t.CreationDate, balances)
 	if err != nil {
 		return "", common.NewErrorf("allocation_reducing_failed", "%v", err)
 	}
@@ -891,18 +1043,8 @@ func (sc *StorageSmartContract) updateAllocationRequest(
 		return sc.closeAllocation(t, alloc, balances)
 	}
 
-	// an allocation can't be shorter then configured in SC
-	// (prevent allocation shortening for entire period)
-	if request.Expiration < 0 &&
-		newExpiration-t.CreationDate < toSeconds(conf.MinAllocDuration) {
-
-		return "", common.NewError("allocation_updating_failed",
-			"allocation duration becomes too short")
-	}
-
-	if request.Size < 0 && alloc.Size+request.Size < conf.MinAllocSize {
-		return "", common.NewError("allocation_updating_failed",
-			"allocation size becomes too small")
+	if err = request.validateChanges(conf, alloc, t.CreationDate); err != nil {
+		return "", common.NewError("allocation_updating_failed", err.Error())
 	}
 
 	// if size or expiration increased, then we use new terms
@@ -914,6 +1056,161 @@ func (sc *StorageSmartContract) updateAllocationRequest(
 	return sc.reduceAllocation(t, all, alloc, blobbers, &request, balances)
 }
 
+// blobberUpdateQuote is changes of a blobber of an allocation updating
+type blobberUpdateQuote struct {
+	BlobberID string `json:"blobber_id"`
+	Size      int64  `json:"size"`  // new size
+	Terms     Terms  `json:"terms"` // new (weighted average) terms
+	// new min lock demand and its difference
+	MinLockDemand      state.Balance `json:"min_lock_demand"`
+	MinLockDemandDelta state.Balance `json:"min_lock_demand_delta"`
+	// tokens to move to challenge pool (positive) or back to write pool
+	// (negative)
+	ChallengePoolChange state.Balance `json:"challenge_pool_change"`
+}
+
+// allocationUpdateQuote is expected result of an update_allocation_request
+// transaction with given size and expiration
+type allocationUpdateQuote struct {
+	AllocationID string           `json:"allocation_id"`
+	Size         int64            `json:"size"`            // new size
+	Expiration   common.Timestamp `json:"expiration_date"` // new expiration
+	// closing makes the allocation expired, no tokens moved
+	Closing bool `json:"closing"`
+	// extending uses new terms of the blobbers, otherwise current terms
+	// of the allocation kept
+	Extending bool                  `json:"extending"`
+	Blobbers  []*blobberUpdateQuote `json:"blobbers"`
+	// min lock demand difference
+	MinLockDemandDelta state.Balance `json:"min_lock_demand_delta"`
+	// tokens of the allocation locked in owner's write pool, and tokens
+	// should be added to the write pool by the transaction
+	WritePoolLocked   state.Balance `json:"write_pool_locked"`
+	WritePoolRequired state.Balance `json:"write_pool_required"`
+	// tokens to move from write pool to challenge pool, and back
+	ToChallengePool   state.Balance `json:"to_challenge_pool"`
+	FromChallengePool state.Balance `json:"from_challenge_pool"`
+}
+
+// allocationUpdateQuote calculates changes of an allocation updating without
+// saving anything, using the same checks and calculations that the
+// update_allocation_request transaction uses
+func (sc *StorageSmartContract) allocationUpdateQuote(
+	uar *updateAllocationRequest, now common.Timestamp,
+	balances chainstate.StateContextI) (quote *allocationUpdateQuote,
+	err error) {
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, false); err != nil {
+		return nil, fmt.Errorf("can't get SC configurations: %v", err)
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(uar.ID, balances); err != nil {
+		return nil, fmt.Errorf("can't get existing allocation: %v", err)
+	}
+
+	if err = uar.validate(conf, alloc); err != nil {
+		return
+	}
+
+	if alloc.Expiration < now {
+		return nil, errors.New("can't update expired allocation")
+	}
+
+	var blobbers []*StorageNode
+	if blobbers, err = sc.getAllocationBlobbers(alloc, balances); err != nil {
+		return
+	}
+
+	quote = &allocationUpdateQuote{AllocationID: alloc.ID}
+
+	// closing, see closeAllocation
+	if alloc.Expiration+uar.Expiration <= now {
+		if alloc.Expiration-now < toSeconds(alloc.ChallengeCompletionTime) {
+			return nil, errors.New("doesn't need to close allocation is" +
+				" about to expire")
+		}
+		quote.Size, quote.Expiration, quote.Closing = alloc.Size, now, true
+		for _, d := range alloc.BlobberDetails {
+			quote.Blobbers = append(quote.Blobbers, &blobberUpdateQuote{
+				BlobberID:     d.BlobberID,
+				Size:          d.Size,
+				Terms:         d.Terms,
+				MinLockDemand: d.MinLockDemand,
+			})
+		}
+		return
+	}
+
+	if err = uar.validateChanges(conf, alloc, now); err != nil {
+		return nil, err
+	}
+
+	var (
+		odr    = alloc.Expiration - now
+		oterms []Terms
+		omld   = make([]state.Balance, 0, len(alloc.BlobberDetails))
+	)
+	for _, d := range alloc.BlobberDetails {
+		omld = append(omld, d.MinLockDemand)
+	}
+
+	quote.Extending = uar.Size > 0 || uar.Expiration > 0
+	if quote.Extending {
+		if oterms, err = uar.extendTerms(alloc, blobbers, now); err != nil {
+			return nil, err
+		}
+	} else {
+		uar.reduceTerms(alloc, blobbers)
+	}
+	quote.Size, quote.Expiration = alloc.Size, alloc.Expiration
+
+	// write pool and challenge pool, see extendAllocation
+	var wp *writePool
+	switch wp, err = sc.getWritePool(alloc.Owner, balances); err {
+	case nil:
+	case util.ErrValueNotPresent:
+		wp = new(writePool)
+	default:
+		return nil, fmt.Errorf("can't get write pool: %v", err)
+	}
+	quote.WritePoolLocked = wp.allocUntil(alloc.ID, alloc.Until())
+
+	var cp *challengePool
+	if cp, err = sc.getChallengePool(alloc.ID, balances); err != nil {
+		return nil, fmt.Errorf("can't get challenge pool: %v", err)
+	}
+
+	var changes []state.Balance
+	quote.WritePoolRequired, changes, err = quoteUpdateTokens(alloc, wp, cp,
+		odr, alloc.Expiration-now, oterms,
+		uar.getBlobbersSizeDiff(alloc) > 0, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, d := range alloc.BlobberDetails {
+		var bq = &blobberUpdateQuote{
+			BlobberID:           d.BlobberID,
+			Size:                d.Size,
+			Terms:               d.Terms,
+			MinLockDemand:       d.MinLockDemand,
+			MinLockDemandDelta:  d.MinLockDemand - omld[i],
+			ChallengePoolChange: changes[i],
+		}
+		if ch := changes[i]; ch > 0 {
+			quote.ToChallengePool += ch
+		} else {
+			quote.FromChallengePool += -ch
+		}
+		quote.MinLockDemandDelta += bq.MinLockDemandDelta
+		quote.Blobbers = append(quote.Blobbers, bq)
+	}
+
+	return
+}
+
 func getPreferredBlobbers(preferredBlobbers []string, allBlobbers []*StorageNode) (selectedBlobbers []*StorageNode, err error) {
 	blobberMap := make(map[string]*StorageNode)
 	for _, storageNode := range allBlobbers {
@@ -990,51 +1287,118 @@ func (sc *StorageSmartContract) adjustChallenges(alloc *StorageAllocation,
 	passRates = make([]float64, 0, len(alloc.BlobberDetails))
 	// range over all related blobbers
 	for _, d := range alloc.BlobberDetails {
-		// check out blobber challenges
-		var (
-			bc               *BlobberChallenge //
-			success, failure int               // for the ending
-		)
-		bc, err = sc.getBlobberChallenge(d.BlobberID, balances)
-		if err != nil && err != util.ErrValueNotPresent {
-			return nil, fmt.Errorf("getting blobber challenge: %v", err)
-		}
-		// no blobber challenges, no failures
-		if err == util.ErrValueNotPresent {
-			passRates, err = append(passRates, 1.0), nil
-			continue // no challenges for the blobber
+		var passRate float64
+		passRate, err = sc.adjustBlobberChallenges(alloc, d, now, balances)
+		if err != nil {
+			return nil, err
 		}
-		if d.Stats == nil {
-			d.Stats = new(StorageAllocationStats) // make sure
+		passRates = append(passRates, passRate)
+	}
+
+	return // ok
+}
+
+// adjustBlobberChallenges resolves open challenges given to the blobber
+// returning success rate of the resolved challenges
+func (sc *StorageSmartContract) adjustBlobberChallenges(
+	alloc *StorageAllocation, d *BlobberAllocation, now common.Timestamp,
+	balances chainstate.StateContextI) (passRate float64, err error) {
+
+	// check out blobber challenges
+	var (
+		bc               *BlobberChallenge //
+		success, failure int               // for the ending
+	)
+	bc, err = sc.getBlobberChallenge(d.BlobberID, balances)
+	if err != nil && err != util.ErrValueNotPresent {
+		return 0, fmt.Errorf("getting blobber challenge: %v", err)
+	}
+	// no blobber challenges, no failures
+	if err == util.ErrValueNotPresent {
+		return 1.0, nil // no challenges for the blobber
+	}
+	// all expired open challenges are failed, all other
+	// challenges we are treating as successful
+	for _, c := range bc.Challenges {
+		if c.Response != nil {
+			continue // already accepted, already rewarded/penalized
 		}
-		// all expired open challenges are failed, all other
-		// challenges we are treating as successful
-		for _, c := range bc.Challenges {
-			if c.Response != nil {
-				continue // already accepted, already rewarded/penalized
-			}
-			var expire = c.Created + toSeconds(d.Terms.ChallengeCompletionTime)
-			if expire < now {
-				alloc.Stats.FailedChallenges++
-				d.Stats.FailedChallenges++
-				failure++
-			} else {
-				alloc.Stats.SuccessChallenges++
-				d.Stats.SuccessChallenges++
-				success++
-			}
-			d.Stats.OpenChallenges--
-			alloc.Stats.OpenChallenges--
+		if resolveOpenChallenge(alloc, d, c, now) {
+			success++
+		} else {
+			failure++
 		}
-		if success == 0 && failure == 0 {
-			passRates = append(passRates, 1.0)
+	}
+	if success == 0 && failure == 0 {
+		return 1.0, nil
+	}
+	// success rate for the blobber allocation
+	return float64(success) / float64(success+failure), nil
+}
+
+// settleBlobberChallenges resolves open challenges of the allocation given
+// to the blobber removing them from the blobber challenges, challenges of
+// other allocations are kept; it returns success rate of the resolved
+// challenges
+func (sc *StorageSmartContract) settleBlobberChallenges(
+	alloc *StorageAllocation, d *BlobberAllocation, now common.Timestamp,
+	balances chainstate.StateContextI) (passRate float64, err error) {
+
+	var (
+		bc               *BlobberChallenge //
+		success, failure int               // for the ending
+	)
+	bc, err = sc.getBlobberChallenge(d.BlobberID, balances)
+	if err != nil && err != util.ErrValueNotPresent {
+		return 0, fmt.Errorf("getting blobber challenge: %v", err)
+	}
+	if err == util.ErrValueNotPresent {
+		return 1.0, nil // no challenges for the blobber
+	}
+	var kept = make([]*StorageChallenge, 0, len(bc.Challenges))
+	for _, c := range bc.Challenges {
+		if c.Response != nil || c.AllocationID != alloc.ID {
+			kept = append(kept, c)
 			continue
 		}
-		// success rate for the blobber allocation
-		passRates = append(passRates, float64(success)/float64(success+failure))
+		if resolveOpenChallenge(alloc, d, c, now) {
+			success++
+		} else {
+			failure++
+		}
+		delete(bc.ChallengeMap, c.ID)
 	}
+	if success == 0 && failure == 0 {
+		return 1.0, nil
+	}
+	bc.Challenges = kept
+	if _, err = balances.InsertTrieNode(bc.GetKey(sc.ID), bc); err != nil {
+		return 0, fmt.Errorf("saving blobber challenge: %v", err)
+	}
+	// success rate for the blobber allocation
+	return float64(success) / float64(success+failure), nil
+}
 
-	return // ok
+// resolveOpenChallenge counts an open challenge given to the blobber as
+// failed if it's expired, or as successful otherwise
+func resolveOpenChallenge(alloc *StorageAllocation, d *BlobberAllocation,
+	c *StorageChallenge, now common.Timestamp) (success bool) {
+
+	if d.Stats == nil {
+		d.Stats = new(StorageAllocationStats) // make sure
+	}
+	var expire = c.Created + toSeconds(d.Terms.ChallengeCompletionTime)
+	if expire < now {
+		alloc.Stats.FailedChallenges++
+		d.Stats.FailedChallenges++
+	} else {
+		alloc.Stats.SuccessChallenges++
+		d.Stats.SuccessChallenges++
+		success = true
+	}
+	d.Stats.OpenChallenges--
+	alloc.Stats.OpenChallenges--
+	return
 }
 
 // If blobbers doesn't provide their services, then user can use this
@@ -1458,3 +1822,537 @@ func (sc *StorageSmartContract) finalizeAllocation(
 
 	return "finalized", nil
 }
+
+//
+// transfer allocation ownership
+//
+
+type transferAllocationOwnershipRequest struct {
+	AllocationID      string `json:"allocation_id"`
+	NewOwnerID        string `json:"new_owner_id"`
+	NewOwnerPublicKey string `json:"new_owner_public_key"`
+}
+
+func (tr *transferAllocationOwnershipRequest) decode(b []byte) (err error) {
+	if err = json.Unmarshal(b, tr); err != nil {
+		return
+	}
+	if tr.AllocationID == "" {
+		return errors.New("missing allocation_id")
+	}
+	if tr.NewOwnerID == "" || tr.NewOwnerPublicKey == "" {
+		return errors.New("missing new owner id or public key")
+	}
+	var pk []byte
+	if pk, err = hex.DecodeString(tr.NewOwnerPublicKey); err != nil {
+		return errors.New("invalid new owner public key")
+	}
+	if encryption.Hash(pk) != tr.NewOwnerID {
+		return errors.New("new owner id doesn't match the public key")
+	}
+	return
+}
+
+// moveAllocationPools moves the write pools and the read pools of the
+// allocation locked by the owner to the new owner.
+func (sc *StorageSmartContract) moveAllocationPools(allocID, from, to string,
+	balances chainstate.StateContextI) (err error) {
+
+	var fwp, twp *writePool
+	if fwp, err = sc.getWritePool(from, balances); err != nil &&
+		err != util.ErrValueNotPresent {
+		return fmt.Errorf("getting owner's write pool: %v", err)
+	}
+	if err == nil {
+		if twp, err = sc.getWritePool(to, balances); err != nil {
+			if err != util.ErrValueNotPresent {
+				return fmt.Errorf("getting new owner's write pool: %v", err)
+			}
+			twp = new(writePool)
+		}
+		if fwp.Pools.moveAllocation(allocID, &twp.Pools) > 0 {
+			if err = fwp.save(sc.ID, from, balances); err != nil {
+				return fmt.Errorf("saving owner's write pool: %v", err)
+			}
+			if err = twp.save(sc.ID, to, balances); err != nil {
+				return fmt.Errorf("saving new owner's write pool: %v", err)
+			}
+		}
+	}
+
+	var frp, trp *readPool
+	if frp, err = sc.getReadPool(from, balances); err != nil {
+		if err == util.ErrValueNotPresent {
+			return nil // no read pools
+		}
+		return fmt.Errorf("getting owner's read pool: %v", err)
+	}
+	if trp, err = sc.getReadPool(to, balances); err != nil {
+		if err != util.ErrValueNotPresent {
+			return fmt.Errorf("getting new owner's read pool: %v", err)
+		}
+		trp = new(readPool)
+	}
+	if frp.Pools.moveAllocation(allocID, &trp.Pools) == 0 {
+		return nil
+	}
+	if err = frp.save(sc.ID, from, balances); err != nil {
+		return fmt.Errorf("saving owner's read pool: %v", err)
+	}
+	if err = trp.save(sc.ID, to, balances); err != nil {
+		return fmt.Errorf("saving new owner's read pool: %v", err)
+	}
+	return
+}
+
+// transferAllocationOwnership hands the allocation to another client. The
+// write pools and the read pools of the allocation locked by the owner are
+// moved to the new owner, the new owner finalizes or cancels the allocation
+// and signs its auth tickets then.
+func (sc *StorageSmartContract) transferAllocationOwnership(
+	t *transaction.Transaction, input []byte,
+	balances chainstate.StateContextI) (resp string, err error) {
+
+	var req transferAllocationOwnershipRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("transfer_allocation_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(req.AllocationID, balances); err != nil {
+		return "", common.NewError("transfer_allocation_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID {
+		return "", common.NewError("transfer_allocation_failed",
+			"only owner can transfer an allocation")
+	}
+	if req.NewOwnerID == alloc.Owner {
+		return "", common.NewError("transfer_allocation_failed",
+			"the client already owns the allocation")
+	}
+	if alloc.Finalized || alloc.Expiration < t.CreationDate {
+		return "", common.NewError("transfer_allocation_failed",
+			"trying to transfer expired or finalized allocation")
+	}
+
+	// client allocations lists
+	var from, to *Allocations
+	if from, err = sc.getAllocationsList(alloc.Owner, balances); err != nil {
+		return "", common.NewError("transfer_allocation_failed",
+			"can't get owner's allocations list: "+err.Error())
+	}
+	if !from.List.remove(alloc.ID) {
+		return "", common.NewError("transfer_allocation_failed",
+			"invalid state: allocation not found in owner's allocations list")
+	}
+	if to, err = sc.getAllocationsList(req.NewOwnerID, balances); err != nil {
+		return "", common.NewError("transfer_allocation_failed",
+			"can't get new owner's allocations list: "+err.Error())
+	}
+	to.List.add(alloc.ID)
+
+	for _, ca := range []*ClientAllocation{
+		{ClientID: alloc.Owner, Allocations: from},
+		{ClientID: req.NewOwnerID, Allocations: to},
+	} {
+		if _, err = balances.InsertTrieNode(ca.GetKey(sc.ID), ca); err != nil {
+			return "", common.NewError("transfer_allocation_failed",
+				"saving allocations list of "+ca.ClientID+": "+err.Error())
+		}
+	}
+
+	// the index of the previous owner keeps the allocation, it's filtered
+	// out by owner on listing; an allocation transferred back isn't added
+	// to the index twice
+	var idx *idIndex
+	idx, err = sc.getIDIndex(ownerAllocationsIndexName(req.NewOwnerID),
+		balances)
+	if err != nil {
+		return "", common.NewError("transfer_allocation_failed",
+			"can't get new owner's allocations index: "+err.Error())
+	}
+	var indexed bool
+	if indexed, err = idx.has(alloc.ID, balances); err != nil {
+		return "", common.NewError("transfer_allocation_failed",
+			"can't get new owner's allocations index: "+err.Error())
+	}
+	if !indexed {
+		err = sc.addToIndex(ownerAllocationsIndexName(req.NewOwnerID),
+			alloc.ID, to.List, balances)
+		if err != nil {
+			return "", common.NewError("transfer_allocation_failed",
+				"saving new owner's allocations index: "+err.Error())
+		}
+	}
+
+	err = sc.moveAllocationPools(alloc.ID, alloc.Owner, req.NewOwnerID,
+		balances)
+	if err != nil {
+		return "", common.NewError("transfer_allocation_failed", err.Error())
+	}
+
+	var prev = alloc.Owner
+	alloc.Owner, alloc.OwnerPublicKey = req.NewOwnerID, req.NewOwnerPublicKey
+	alloc.Tx = t.Hash
+
+	if _, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc); err != nil {
+		return "", common.NewError("transfer_allocation_failed",
+			"saving allocation: "+err.Error())
+	}
+
+	emitAllocationTransferred(alloc, prev, balances)
+	return string(alloc.Encode()), nil
+}
+
+// replace blobber request
+type replaceBlobberRequest struct {
+	AllocationID string `json:"allocation_id"`
+	BlobberID    string `json:"blobber_id"`     // blobber to remove
+	NewBlobberID string `json:"new_blobber_id"` // preferred, optional
+}
+
+func (rbr *replaceBlobberRequest) decode(b []byte) (err error) {
+	if err = json.Unmarshal(b, rbr); err != nil {
+		return
+	}
+	if rbr.AllocationID == "" {
+		return errors.New("missing allocation_id")
+	}
+	if rbr.BlobberID == "" {
+		return errors.New("missing blobber_id")
+	}
+	if rbr.NewBlobberID == rbr.BlobberID {
+		return errors.New("replacing blobber with itself")
+	}
+	return
+}
+
+// selectReplacementBlobber returns the preferred blobber or a random one
+// from the blobbers matching the allocation terms; blobbers of the
+// allocation excluded
+func (sc *StorageSmartContract) selectReplacementBlobber(conf *scConfig,
+	t *transaction.Transaction, alloc *StorageAllocation, preferred string,
+	size int64, all *StorageNodes, balances chainstate.StateContextI) (
+	b *StorageNode, err error) {
+
+	var list = alloc.filterBlobbers(all.Nodes.copy(), t.CreationDate, size,
+		filterHealthyBlobbers(t.CreationDate),
+		sc.filterBlobbersByFreeSpace(t.CreationDate, size, balances),
+		sc.filterBlobbersByReputation(conf, t.CreationDate, balances),
+		filterBlobberFunc(func(b *StorageNode) (kick bool) {
+			_, kick = alloc.BlobberMap[b.ID]
+			return // kick off blobbers of the allocation
+		}))
+
+	if preferred != "" {
+		for _, b = range list {
+			if b.ID == preferred {
+				return
+			}
+		}
+		return nil, errors.New("preferred blobber doesn't match the allocation")
+	}
+
+	if len(list) == 0 {
+		return nil, errors.New("no blobbers to replace with")
+	}
+
+	var seed int64
+	if seed, err = strconv.ParseInt(t.Hash[0:8], 16, 64); err != nil {
+		return nil, errors.New("failed to create seed for randomizeNodes")
+	}
+	return randomizeNodes(list, nil, 1, seed)[0], nil
+}
+
+// isFailingBlobber is true for a blobber without a recent health check, shut
+// down or removed one, or one failed challenges of the allocation; only such
+// blobbers can be replaced
+func isFailingBlobber(b *StorageNode, d *BlobberAllocation,
+	now common.Timestamp) bool {
+
+	return filterHealthyBlobbers(now)(b) ||
+		b.Status == blobberStatusShutdown || b.Capacity == 0 ||
+		(d.Stats != nil && d.Stats.FailedChallenges > 0)
+}
+
+// replaceBlobber removes a failing blobber from the allocation adding another
+// one instead. The removed blobber receives its share of the challenge pool by
+// success rate of its open challenges of the allocation, which are removed,
+// and the rest of its min lock demand, its offer is removed. The rest of its
+// challenge pool share and the write pool tokens locked for it are moved to
+// the new blobber, and they should cover min lock demand of the new blobber.
+// Data of the removed blobber should be repaired by the owner to the new
+// blobber then.
+func (sc *StorageSmartContract) replaceBlobber(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var req replaceBlobberRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var alloc *StorageAllocation
+	if alloc, err = sc.getAllocation(req.AllocationID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get allocation: "+err.Error())
+	}
+
+	if alloc.Owner != t.ClientID {
+		return "", common.NewError("replace_blobber_failed",
+			"only owner can replace a blobber of an allocation")
+	}
+	if alloc.Finalized || alloc.Expiration < t.CreationDate {
+		return "", common.NewError("replace_blobber_failed",
+			"trying to replace blobber of expired or finalized allocation")
+	}
+
+	var di = -1
+	for i, details := range alloc.BlobberDetails {
+		if details.BlobberID == req.BlobberID {
+			di = i
+			break
+		}
+	}
+	if di < 0 {
+		return "", common.NewError("replace_blobber_failed",
+			"blobber "+req.BlobberID+" is not in the allocation")
+	}
+	var d = alloc.BlobberDetails[di]
+
+	var b *StorageNode
+	if b, err = sc.getBlobber(d.BlobberID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get blobber "+d.BlobberID+": "+err.Error())
+	}
+	if !isFailingBlobber(b, d, t.CreationDate) {
+		return "", common.NewError("replace_blobber_failed",
+			"blobber "+d.BlobberID+" is healthy and doesn't fail challenges")
+	}
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, false); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	var allb *StorageNodes
+	if allb, err = sc.getBlobbersList(balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get all blobbers list: "+err.Error())
+	}
+
+	var nb *StorageNode
+	nb, err = sc.selectReplacementBlobber(conf, t, alloc, req.NewBlobberID,
+		d.Size, allb, balances)
+	if err != nil {
+		return "", common.NewError("replace_blobber_failed", err.Error())
+	}
+
+	// resolve open challenges of the removed blobber
+
+	var passRate = 1.0
+	if alloc.Stats != nil && alloc.Stats.OpenChallenges > 0 {
+		passRate, err = sc.settleBlobberChallenges(alloc, d, t.CreationDate,
+			balances)
+		if err != nil {
+			return "", common.NewError("replace_blobber_failed",
+				"calculating rest challenges success/fail rates: "+
+					err.Error())
+		}
+	}
+
+	// the new blobber and its details
+
+	var nd = &BlobberAllocation{
+		BlobberID:    nb.ID,
+		AllocationID: alloc.ID,
+		Size:         d.Size,
+		Terms:        nb.Terms,
+		Stats:        &StorageAllocationStats{},
+	}
+	nd.MinLockDemand = nb.Terms.minLockDemand(sizeInGB(d.Size),
+		alloc.restDurationInTimeUnits(t.CreationDate))
+
+	var extendOffers bool
+	if nb.Terms.ChallengeCompletionTime > alloc.ChallengeCompletionTime {
+		alloc.ChallengeCompletionTime = nb.Terms.ChallengeCompletionTime
+		extendOffers = true
+	}
+
+	var until = alloc.Until()
+
+	// write pool and challenge pool
+
+	var wp *writePool
+	if wp, err = sc.getWritePool(alloc.Owner, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get user's write pools: "+err.Error())
+	}
+
+	var cp *challengePool
+	if cp, err = sc.getChallengePool(alloc.ID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get related challenge pool: "+err.Error())
+	}
+
+	// settle the removed blobber
+
+	var sp *stakePool
+	if sp, err = sc.getStakePool(d.BlobberID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get stake pool of "+d.BlobberID+": "+err.Error())
+	}
+
+	var (
+		share  = minBalance(d.ChallengePoolIntegralValue, cp.Balance)
+		reward = state.Balance(float64(share) * passRate)
+	)
+	if reward > 0 {
+		if err = cp.moveToBlobber(sc.ID, sp, reward, balances); err != nil {
+			return "", common.NewError("replace_blobber_failed",
+				"moving tokens to stake pool of "+d.BlobberID+": "+
+					err.Error())
+		}
+		d.Spent += reward       // }
+		d.FinalReward += reward // } stat
+	}
+
+	// min lock demand rest
+	var fctrml = conf.FailedChallengesToRevokeMinLock
+	if d.Stats == nil || d.Stats.FailedChallenges < int64(fctrml) {
+		if lack := d.MinLockDemand - d.Spent; lack > 0 {
+			err = wp.moveToStake(sc.ID, alloc.ID, d.BlobberID, sp, until, lack,
+				balances)
+			if err != nil {
+				return "", common.NewError("replace_blobber_failed",
+					"paying min_lock for "+d.BlobberID+": "+err.Error())
+			}
+			d.Spent += lack
+			d.FinalReward += lack
+		}
+	}
+
+	// the rest of the blobber tokens are for the new blobber
+	wp.Pools.moveBlobber(alloc.ID, d.BlobberID, nb.ID)
+
+	var back = share - reward
+	if err = cp.moveToWritePool(alloc.ID, nb.ID, until, wp, back); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"moving challenge pool rest back to write pool: "+err.Error())
+	}
+	alloc.MovedBack += back
+	d.ChallengePoolIntegralValue = 0
+
+	// the new blobber should be paid its min lock demand
+	if wp.Pools.blobberUntil(alloc.ID, nb.ID, until) < nd.MinLockDemand {
+		return "", common.NewError("replace_blobber_failed",
+			"not enough tokens in write pool for min lock demand of "+
+				"the new blobber")
+	}
+
+	delete(sp.Offers, alloc.ID)
+
+	var info *stakePoolUpdateInfo
+	if info, err = sp.update(conf, sc.ID, t.CreationDate, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"updating stake pool of "+d.BlobberID+": "+err.Error())
+	}
+	if err = sp.save(sc.ID, d.BlobberID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving stake pool of "+d.BlobberID+": "+err.Error())
+	}
+	conf.Minted += info.minted
+
+	b.Used -= d.Size
+	if _, err = balances.InsertTrieNode(b.GetKey(sc.ID), b); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving blobber "+d.BlobberID+": "+err.Error())
+	}
+	allb.Nodes.update(b)
+
+	// replace the blobber in the allocation
+
+	if d.Stats != nil && alloc.Stats != nil {
+		alloc.Stats.UsedSize -= d.Stats.UsedSize // to be repaired
+		alloc.UsedSize -= d.Stats.UsedSize
+	}
+
+	alloc.BlobberDetails[di] = nd
+	delete(alloc.BlobberMap, d.BlobberID)
+	alloc.BlobberMap[nb.ID] = nd
+	for i, ab := range alloc.Blobbers {
+		if ab.ID == d.BlobberID {
+			alloc.Blobbers[i] = nb
+			break
+		}
+	}
+	sort.SliceStable(alloc.Blobbers, func(i, j int) bool {
+		return alloc.Blobbers[i].ID < alloc.Blobbers[j].ID
+	})
+
+	// the new blobber offer
+
+	var nsp *stakePool
+	if nsp, err = sc.getStakePool(nb.ID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"can't get stake pool of "+nb.ID+": "+err.Error())
+	}
+	nsp.addOffer(alloc, nd)
+	if err = nsp.save(sc.ID, nb.ID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving stake pool of "+nb.ID+": "+err.Error())
+	}
+	nb.Used += nd.Size
+	if _, err = balances.InsertTrieNode(nb.GetKey(sc.ID), nb); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving blobber "+nb.ID+": "+err.Error())
+	}
+	allb.Nodes.update(nb)
+
+	// challenge completion time of the allocation increased
+	if extendOffers {
+		for _, ba := range alloc.BlobberDetails {
+			if ba == nd {
+				continue
+			}
+			if err = sc.updateSakePoolOffer(ba, alloc, balances); err != nil {
+				return "", common.NewError("replace_blobber_failed",
+					err.Error())
+			}
+		}
+	}
+
+	// save all
+
+	_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, allb)
+	if err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving all blobbers list: "+err.Error())
+	}
+	if err = cp.save(sc.ID, alloc.ID, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving challenge pool: "+err.Error())
+	}
+	if err = wp.save(sc.ID, alloc.Owner, balances); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving write pool: "+err.Error())
+	}
+	_, err = balances.InsertTrieNode(scConfigKey(sc.ID), conf)
+	if err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving configurations: "+err.Error())
+	}
+
+	alloc.Tx = t.Hash
+	if _, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc); err != nil {
+		return "", common.NewError("replace_blobber_failed",
+			"saving allocation: "+err.Error())
+	}
+
+	emitBlobberReplaced(alloc, d.BlobberID, nb.ID, balances)
+	return string(alloc.Encode()), nil
+}
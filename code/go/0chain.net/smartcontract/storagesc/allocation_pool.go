@@ -27,6 +27,9 @@ type lockRequest struct {
 	Duration     time.Duration `json:"duration"`
 	AllocationID datastore.Key `json:"allocation_id"`
 	BlobberID    datastore.Key `json:"blobber_id,omitempty"`
+	// Readers allowed to spend tokens of a read pool lock (read pool only),
+	// no readers means any reader can spend the tokens.
+	Readers []*readerLockRequest `json:"readers,omitempty"`
 }
 
 func (lr *lockRequest) decode(input []byte) (err error) {
@@ -140,6 +143,9 @@ type allocationPool struct {
 	ExpireAt          common.Timestamp `json:"expire_at"`     // inclusive
 	AllocationID      datastore.Key    `json:"allocation_id"` //
 	Blobbers          blobberPools     `json:"blobbers"`      //
+	// Readers of a read pool lock with spending limits, if empty, then
+	// any reader can spend the tokens.
+	Readers readerLimits `json:"readers,omitempty"`
 }
 
 //
@@ -257,6 +263,21 @@ func (aps allocationPools) allocUntil(allocID string, until common.Timestamp) (
 	return
 }
 
+// blobberUntil returns tokens of the allocation locked for the blobber not
+// expired before the given time
+func (aps allocationPools) blobberUntil(allocID, blobberID string,
+	until common.Timestamp) (value state.Balance) {
+
+	var cut = aps.allocationCut(allocID)
+	cut = removeExpired(cut, until)
+	for _, ap := range cut {
+		if bp, ok := ap.Blobbers.get(blobberID); ok {
+			value += bp.Balance
+		}
+	}
+	return
+}
+
 func isInTOMRList(torm []*allocationPool, ax *allocationPool) bool {
 	for _, tr := range torm {
 		if tr == ax {
@@ -291,6 +312,45 @@ Outer:
 	(*aps) = (*aps)[:i]
 }
 
+// moveAllocation moves all pools of the allocation to the given list
+func (aps *allocationPools) moveAllocation(allocID string,
+	to *allocationPools) (moved int) {
+
+	var i int
+	for _, ap := range *aps {
+		if ap.AllocationID == allocID {
+			to.add(ap)
+			moved++
+			continue
+		}
+		(*aps)[i], i = ap, i+1
+	}
+	(*aps) = (*aps)[:i]
+	return
+}
+
+// moveBlobber moves tokens of the allocation locked for a blobber to another
+// blobber of the allocation
+func (aps allocationPools) moveBlobber(allocID, fromID, toID string) (
+	moved state.Balance) {
+
+	for _, ap := range aps.allocationCut(allocID) {
+		var bi, ok = ap.Blobbers.getIndex(fromID)
+		if !ok {
+			continue // no tokens for the blobber
+		}
+		var bp = ap.Blobbers[bi]
+		ap.Blobbers.removeByIndex(bi)
+		moved += bp.Balance
+		if to, ok := ap.Blobbers.get(toID); ok {
+			to.Balance += bp.Balance
+			continue
+		}
+		ap.Blobbers.add(&blobberPool{BlobberID: toID, Balance: bp.Balance})
+	}
+	return
+}
+
 func removeExpired(cut []*allocationPool, now common.Timestamp) (
 	clean []*allocationPool) {
 
@@ -357,6 +417,7 @@ type allocationPoolStat struct {
 	AllocationID datastore.Key     `json:"allocation_id"`
 	Blobbers     []blobberPoolStat `json:"blobbers"`
 	Locked       bool              `json:"locked"`
+	Readers      []readerLimitStat `json:"readers,omitempty"`
 }
 
 func (ap *allocationPool) stat(now common.Timestamp) (stat allocationPoolStat) {
@@ -372,6 +433,10 @@ func (ap *allocationPool) stat(now common.Timestamp) (stat allocationPoolStat) {
 		stat.Blobbers = append(stat.Blobbers, bp.stat())
 	}
 
+	for _, rl := range ap.Readers {
+		stat.Readers = append(stat.Readers, rl.stat(ap.ExpireAt, now))
+	}
+
 	return
 }
 
@@ -1,7 +1,10 @@
 package storagesc
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
+	"net/url"
 	"testing"
 	"time"
 
@@ -404,6 +407,7 @@ func TestStorageSmartContract_newAllocationRequest(t *testing.T) {
 	balances.balances[clientID] = 1100
 
 	tx.Value = 400
+	balances.events = nil
 	resp, err = ssc.newAllocationRequest(&tx, mustEncode(t, &nar), balances)
 	require.NoError(t, err)
 
@@ -411,6 +415,10 @@ func TestStorageSmartContract_newAllocationRequest(t *testing.T) {
 	var aresp StorageAllocation
 	require.NoError(t, aresp.Decode([]byte(resp)))
 
+	require.Len(t, balances.events, 1)
+	assert.Equal(t, EventAllocationCreated, balances.events[0].Type)
+	assert.Equal(t, txHash, balances.events[0].Attributes["allocation"])
+
 	assert.Equal(t, txHash, aresp.ID)
 	assert.Equal(t, 1, aresp.DataShards)
 	assert.Equal(t, 1, aresp.ParityShards)
@@ -877,6 +885,117 @@ func TestStorageSmartContract_updateAllocationRequest(t *testing.T) {
 
 }
 
+func TestStorageSmartContract_allocationUpdateQuote(t *testing.T) {
+
+	var (
+		ssc                  = newTestStorageSC()
+		balances             = newTestBalances(t, false)
+		client               = newClient(50*x10, balances)
+		tp, exp        int64 = 100, 1000
+		allocID, blobs       = addAllocation(t, ssc, client, tp, exp, 0,
+			balances)
+
+		alloc *StorageAllocation
+		quote *allocationUpdateQuote
+		err   error
+	)
+
+	// change terms
+	tp += 100
+	for _, b := range blobs {
+		var blob *StorageNode
+		blob, err = ssc.getBlobber(b.id, balances)
+		require.NoError(t, err)
+		blob.Terms.WritePrice = state.Balance(1.8 * x10)
+		_, err = updateBlobber(t, blob, 0, tp, ssc, balances)
+		require.NoError(t, err)
+	}
+
+	// the allocation stores data, thus extending moves tokens to its
+	// challenge pool
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	for _, d := range alloc.BlobberDetails {
+		d.Stats = &StorageAllocationStats{UsedSize: 100 * GB}
+	}
+	alloc.Stats = &StorageAllocationStats{UsedSize: 100 * GB}
+	mustSave(t, alloc.GetKey(ssc.ID), alloc, balances)
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	var uar updateAllocationRequest
+	uar.ID = alloc.ID
+	uar.Expiration = alloc.Expiration * 2
+	uar.Size = alloc.Size * 2
+	tp += 100
+
+	// the quote doesn't change anything
+	var q = uar
+	quote, err = ssc.allocationUpdateQuote(&q, common.Timestamp(tp), balances)
+	require.NoError(t, err)
+	assert.True(t, quote.Extending)
+	assert.False(t, quote.Closing)
+	assert.True(t, quote.MinLockDemandDelta > 0)
+	assert.True(t, quote.WritePoolRequired > 0)
+	assert.True(t, quote.ToChallengePool > 0)
+	assert.True(t, quote.WritePoolLocked+quote.WritePoolRequired >
+		alloc.restMinLockDemand()+quote.MinLockDemandDelta,
+		"challenge pool tokens aren't quoted")
+
+	var same *StorageAllocation
+	same, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	require.EqualValues(t, alloc, same)
+
+	// not enough tokens
+	_, err = uar.callUpdateAllocReq(t, client.id, 0, tp, ssc, balances)
+	requireErrMsg(t, err, "allocation_extending_failed: "+
+		"not enough tokens in write pool to extend allocation")
+
+	// a token less than quoted
+	_, err = uar.callUpdateAllocReq(t, client.id,
+		int64(quote.WritePoolRequired)-1, tp, ssc, balances)
+	require.Error(t, err)
+
+	// quoted tokens
+	_, err = uar.callUpdateAllocReq(t, client.id,
+		int64(quote.WritePoolRequired), tp, ssc, balances)
+	require.NoError(t, err)
+
+	var cp *challengePool
+	cp, err = ssc.getChallengePool(allocID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, quote.ToChallengePool, cp.Balance)
+
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	assert.Equal(t, quote.Size, alloc.Size)
+	assert.Equal(t, quote.Expiration, alloc.Expiration)
+	require.Len(t, quote.Blobbers, len(alloc.BlobberDetails))
+	for i, d := range alloc.BlobberDetails {
+		var bq = quote.Blobbers[i]
+		assert.Equal(t, d.BlobberID, bq.BlobberID)
+		assert.Equal(t, d.Size, bq.Size)
+		assert.Equal(t, d.Terms, bq.Terms)
+		assert.Equal(t, d.MinLockDemand, bq.MinLockDemand)
+	}
+
+	// closing
+	uar.Size, uar.Expiration = 0, -alloc.Expiration
+	quote, err = ssc.allocationUpdateQuote(&uar, common.Timestamp(tp),
+		balances)
+	require.NoError(t, err)
+	assert.True(t, quote.Closing)
+	assert.EqualValues(t, tp, quote.Expiration)
+
+	// handler
+	_, err = ssc.GetAllocationUpdateQuoteHandler(context.Background(),
+		url.Values{}, balances)
+	requireErrMsg(t, err, "allocation_update_quote_failed: "+
+		"missing 'allocation_id' URL query parameter")
+}
+
 // add empty blobber challenges
 func addBloberChallenges(t *testing.T, sscID string, alloc *StorageAllocation,
 	balances *testBalances) {
@@ -1215,3 +1334,376 @@ func Test_preferred_blobbers(t *testing.T) {
 	})
 
 }
+
+func TestStorageSmartContract_transferAllocationOwnership(t *testing.T) {
+
+	var (
+		ssc            = newTestStorageSC()
+		balances       = newTestBalances(t, false)
+		client         = newClient(100*x10, balances)
+		tp, exp  int64 = 0, int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	tp += 100
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	// owner's read pool of the allocation
+	tp += 100
+	var tx = newTransaction(client.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.newReadPool(tx, nil, balances)
+	require.NoError(t, err)
+	tx = newTransaction(client.id, ssc.ID, 2*x10, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.readPoolLock(tx, mustEncode(t, &lockRequest{
+		Duration:     20 * time.Minute,
+		AllocationID: allocID,
+	}), balances)
+	require.NoError(t, err)
+
+	// the new owner, id of a client is hash of its public key
+	var (
+		scheme = encryption.NewBLS0ChainScheme()
+		pkb    []byte
+	)
+	require.NoError(t, scheme.GenerateKeys())
+	pkb, err = hex.DecodeString(scheme.GetPublicKey())
+	require.NoError(t, err)
+	var newOwner = &Client{
+		id:     encryption.Hash(pkb),
+		pk:     scheme.GetPublicKey(),
+		scheme: scheme,
+	}
+
+	var transfer = func(clientID string,
+		req *transferAllocationOwnershipRequest) (resp string, err error) {
+
+		tp += 100
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		return ssc.transferAllocationOwnership(tx, mustEncode(t, req),
+			balances)
+	}
+	var req = &transferAllocationOwnershipRequest{
+		AllocationID:      allocID,
+		NewOwnerID:        newOwner.id,
+		NewOwnerPublicKey: newOwner.pk,
+	}
+
+	// not owner
+	_, err = transfer(newOwner.id, req)
+	requireErrMsg(t, err, "transfer_allocation_failed: "+
+		"only owner can transfer an allocation")
+
+	// public key of another client
+	_, err = transfer(client.id, &transferAllocationOwnershipRequest{
+		AllocationID:      allocID,
+		NewOwnerID:        newOwner.id,
+		NewOwnerPublicKey: client.pk,
+	})
+	requireErrMsg(t, err, "transfer_allocation_failed: invalid request: "+
+		"new owner id doesn't match the public key")
+
+	var wp *writePool
+	wp, err = ssc.getWritePool(client.id, balances)
+	require.NoError(t, err)
+	var locked = wp.Pools.allocUntil(allocID, 0)
+	require.NotZero(t, locked)
+
+	_, err = transfer(client.id, req)
+	require.NoError(t, err)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, newOwner.id, alloc.Owner)
+	assert.Equal(t, newOwner.pk, alloc.OwnerPublicKey)
+	assert.True(t, alloc.IsValidFinalizer(newOwner.id))
+	assert.False(t, alloc.IsValidFinalizer(client.id))
+
+	// allocations lists
+	var list *Allocations
+	list, err = ssc.getAllocationsList(client.id, balances)
+	require.NoError(t, err)
+	assert.False(t, list.has(allocID))
+	list, err = ssc.getAllocationsList(newOwner.id, balances)
+	require.NoError(t, err)
+	assert.True(t, list.has(allocID))
+
+	// pools
+	wp, err = ssc.getWritePool(client.id, balances)
+	require.NoError(t, err)
+	assert.Empty(t, wp.Pools.allocationCut(allocID))
+	wp, err = ssc.getWritePool(newOwner.id, balances)
+	require.NoError(t, err)
+	assert.Equal(t, locked, wp.Pools.allocUntil(allocID, 0))
+
+	var rp *readPool
+	rp, err = ssc.getReadPool(client.id, balances)
+	require.NoError(t, err)
+	assert.Empty(t, rp.Pools.allocationCut(allocID))
+	rp, err = ssc.getReadPool(newOwner.id, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2*x10, rp.Pools.allocUntil(allocID, 0))
+
+	require.Len(t, balances.events, 1)
+	assert.Equal(t, EventAllocationTransferred, balances.events[0].Type)
+	assert.Equal(t, client.id, balances.events[0].Attributes["from"])
+
+	// the previous owner can't transfer it back
+	_, err = transfer(client.id, &transferAllocationOwnershipRequest{
+		AllocationID:      allocID,
+		NewOwnerID:        newOwner.id,
+		NewOwnerPublicKey: newOwner.pk,
+	})
+	require.Error(t, err)
+
+	// owners listings
+	var listOf = func(owner string) (ids []string) {
+		var resp, err = ssc.GetAllocationsHandler(nil,
+			url.Values{"owner": []string{owner}}, balances)
+		require.NoError(t, err)
+		for _, a := range resp.(*allocationsPage).Allocations {
+			ids = append(ids, a.ID)
+		}
+		return
+	}
+	assert.Empty(t, listOf(client.id))
+	assert.Equal(t, []string{allocID}, listOf(newOwner.id))
+
+	// transferred to another client and back, it's listed once
+	var other = newClient(0, balances)
+	pkb, err = hex.DecodeString(other.pk)
+	require.NoError(t, err)
+	other.id = encryption.Hash(pkb)
+	_, err = transfer(newOwner.id, &transferAllocationOwnershipRequest{
+		AllocationID:      allocID,
+		NewOwnerID:        other.id,
+		NewOwnerPublicKey: other.pk,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, listOf(newOwner.id))
+	assert.Equal(t, []string{allocID}, listOf(other.id))
+	_, err = transfer(other.id, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{allocID}, listOf(newOwner.id))
+	assert.Empty(t, listOf(other.id))
+}
+
+func TestStorageSmartContract_replaceBlobber(t *testing.T) {
+
+	var (
+		ssc            = newTestStorageSC()
+		balances       = newTestBalances(t, false)
+		client         = newClient(100*x10, balances)
+		tp, exp  int64 = 0, int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	tp += 100
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var replace = func(clientID string, req *replaceBlobberRequest) (
+		resp string, err error) {
+
+		tp += 100
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		return ssc.replaceBlobber(tx, mustEncode(t, req), balances)
+	}
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	var (
+		removed = alloc.BlobberDetails[3].BlobberID
+		other   = alloc.BlobberDetails[4].BlobberID
+	)
+
+	var wp *writePool
+	wp, err = ssc.getWritePool(client.id, balances)
+	require.NoError(t, err)
+	var locked state.Balance
+	for _, ap := range wp.Pools.allocationCut(allocID) {
+		var bp, ok = ap.Blobbers.get(removed)
+		require.True(t, ok)
+		locked += bp.Balance
+	}
+	require.NotZero(t, locked)
+
+	// healthy blobber passing challenges
+	_, err = replace(client.id, &replaceBlobberRequest{
+		AllocationID: allocID,
+		BlobberID:    removed,
+	})
+	requireErrMsg(t, err, "replace_blobber_failed: blobber "+removed+
+		" is healthy and doesn't fail challenges")
+
+	// shut down blobber can be replaced
+	var rb *StorageNode
+	rb, err = ssc.getBlobber(removed, balances)
+	require.NoError(t, err)
+	rb.Status = blobberStatusShutdown
+	_, err = balances.InsertTrieNode(rb.GetKey(ssc.ID), rb)
+	require.NoError(t, err)
+
+	// not owner
+	_, err = replace(removed, &replaceBlobberRequest{
+		AllocationID: allocID,
+		BlobberID:    removed,
+	})
+	requireErrMsg(t, err, "replace_blobber_failed: "+
+		"only owner can replace a blobber of an allocation")
+
+	// not a blobber of the allocation
+	_, err = replace(client.id, &replaceBlobberRequest{
+		AllocationID: allocID,
+		BlobberID:    "not_a_blobber",
+	})
+	requireErrMsg(t, err, "replace_blobber_failed: "+
+		"blobber not_a_blobber is not in the allocation")
+
+	// preferred blobber is already in the allocation
+	_, err = replace(client.id, &replaceBlobberRequest{
+		AllocationID: allocID,
+		BlobberID:    removed,
+		NewBlobberID: other,
+	})
+	requireErrMsg(t, err, "replace_blobber_failed: "+
+		"preferred blobber doesn't match the allocation")
+
+	// open challenges of the allocation and of another one
+	var bc = &BlobberChallenge{BlobberID: removed}
+	bc.addChallenge(&StorageChallenge{ID: "own", AllocationID: allocID,
+		Created: common.Timestamp(tp)})
+	bc.addChallenge(&StorageChallenge{ID: "other", AllocationID: "other",
+		Created: common.Timestamp(tp)})
+	_, err = balances.InsertTrieNode(bc.GetKey(ssc.ID), bc)
+	require.NoError(t, err)
+	alloc.Stats.OpenChallenges = 1
+	alloc.BlobberDetails[3].Stats.OpenChallenges = 1
+	_, err = balances.InsertTrieNode(alloc.GetKey(ssc.ID), alloc)
+	require.NoError(t, err)
+
+	_, err = replace(client.id, &replaceBlobberRequest{
+		AllocationID: allocID,
+		BlobberID:    removed,
+	})
+	require.NoError(t, err)
+
+	bc, err = ssc.getBlobberChallenge(removed, balances)
+	require.NoError(t, err)
+	require.Len(t, bc.Challenges, 1)
+	assert.Equal(t, "other", bc.Challenges[0].ID)
+	_, ok := bc.ChallengeMap["own"]
+	assert.False(t, ok)
+
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	require.Len(t, alloc.BlobberDetails, 20)
+	require.Len(t, alloc.Blobbers, 20)
+	_, ok = alloc.BlobberMap[removed]
+	assert.False(t, ok)
+	assert.Zero(t, alloc.Stats.OpenChallenges)
+	assert.EqualValues(t, 1, alloc.Stats.SuccessChallenges)
+
+	var added = alloc.BlobberDetails[3]
+	assert.NotEqual(t, removed, added.BlobberID)
+	assert.Equal(t, alloc.BlobberDetails[4].Size, added.Size)
+	assert.NotZero(t, added.MinLockDemand)
+
+	// offers
+	var sp *stakePool
+	sp, err = ssc.getStakePool(removed, balances)
+	require.NoError(t, err)
+	assert.Nil(t, sp.findOffer(allocID))
+	sp, err = ssc.getStakePool(added.BlobberID, balances)
+	require.NoError(t, err)
+	assert.NotNil(t, sp.findOffer(allocID))
+
+	// capacity used
+	var b *StorageNode
+	b, err = ssc.getBlobber(removed, balances)
+	require.NoError(t, err)
+	assert.Zero(t, b.Used)
+	b, err = ssc.getBlobber(added.BlobberID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, added.Size, b.Used)
+
+	// write pool tokens moved to the new blobber
+	wp, err = ssc.getWritePool(client.id, balances)
+	require.NoError(t, err)
+	var moved state.Balance
+	for _, ap := range wp.Pools.allocationCut(allocID) {
+		_, ok = ap.Blobbers.get(removed)
+		assert.False(t, ok)
+		var bp, ok = ap.Blobbers.get(added.BlobberID)
+		require.True(t, ok)
+		moved += bp.Balance
+	}
+	assert.True(t, moved > 0 && moved <= locked)
+
+	require.Len(t, balances.events, 1)
+	assert.Equal(t, EventBlobberReplaced, balances.events[0].Type)
+	assert.Equal(t, removed, balances.events[0].Attributes["blobber"])
+	assert.Equal(t, added.BlobberID,
+		balances.events[0].Attributes["new_blobber"])
+
+	// replace with a preferred blobber
+	var all *StorageNodes
+	all, err = ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	var preferred string
+	for _, n := range all.Nodes {
+		if _, ok = alloc.BlobberMap[n.ID]; !ok && n.ID != removed {
+			preferred = n.ID
+			break
+		}
+	}
+	require.NotZero(t, preferred)
+
+	// blobber failed challenges can be replaced, it failed enough of them
+	// to revoke its min lock demand
+	alloc.BlobberDetails[4].Stats.FailedChallenges = 50
+	_, err = balances.InsertTrieNode(alloc.GetKey(ssc.ID), alloc)
+	require.NoError(t, err)
+
+	// but tokens locked for it should cover min lock demand of the new one
+	var wpb = wp.Encode()
+	for _, ap := range wp.Pools.allocationCut(allocID) {
+		if bp, ok := ap.Blobbers.get(other); ok {
+			ap.Balance -= bp.Balance
+			bp.Balance = 0
+		}
+	}
+	require.NoError(t, wp.save(ssc.ID, client.id, balances))
+	_, err = replace(client.id, &replaceBlobberRequest{
+		AllocationID: allocID,
+		BlobberID:    other,
+		NewBlobberID: preferred,
+	})
+	requireErrMsg(t, err, "replace_blobber_failed: not enough tokens in "+
+		"write pool for min lock demand of the new blobber")
+	wp = new(writePool)
+	require.NoError(t, wp.Decode(wpb))
+	require.NoError(t, wp.save(ssc.ID, client.id, balances))
+
+	_, err = replace(client.id, &replaceBlobberRequest{
+		AllocationID: allocID,
+		BlobberID:    other,
+		NewBlobberID: preferred,
+	})
+	require.NoError(t, err)
+
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, preferred, alloc.BlobberDetails[4].BlobberID)
+	_, ok = alloc.BlobberMap[other]
+	assert.False(t, ok)
+}
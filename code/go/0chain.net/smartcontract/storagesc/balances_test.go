@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"0chain.net/chaincore/block"
+	cstate "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
@@ -20,7 +21,9 @@ type testBalances struct {
 	balances  map[datastore.Key]state.Balance
 	txn       *transaction.Transaction
 	transfers []*state.Transfer
+	events    []*transaction.Event
 	tree      map[datastore.Key]util.Serializable
+	block     *block.Block
 
 	mpts      *mptStore // use for benchmarks
 	skipMerge bool      // don't merge for now
@@ -47,6 +50,7 @@ func (tb *testBalances) setTransaction(t testing.TB,
 	txn *transaction.Transaction) {
 
 	tb.txn = txn
+	tb.events = nil
 
 	if tb.mpts != nil && !tb.skipMerge {
 		tb.mpts.merge(t)
@@ -54,13 +58,14 @@ func (tb *testBalances) setTransaction(t testing.TB,
 }
 
 // stubs
-func (tb *testBalances) GetBlock() *block.Block                   { return nil }
+func (tb *testBalances) GetBlock() *block.Block                   { return tb.block }
 func (tb *testBalances) GetState() util.MerklePatriciaTrieI       { return nil }
 func (tb *testBalances) GetTransaction() *transaction.Transaction { return nil }
 func (tb *testBalances) GetBlockSharders(b *block.Block) []string { return nil }
 func (tb *testBalances) Validate() error                          { return nil }
 func (tb *testBalances) GetMints() []*state.Mint                  { return nil }
 func (tb *testBalances) SetStateContext(*state.State) error       { return nil }
+func (tb *testBalances) GetEvents() []*transaction.Event          { return tb.events }
 func (tb *testBalances) AddMint(*state.Mint) error                { return nil }
 func (tb *testBalances) GetTransfers() []*state.Transfer          { return nil }
 func (tb *testBalances) SetMagicBlock(block *block.MagicBlock)    {}
@@ -69,6 +74,12 @@ func (tb *testBalances) AddSignedTransfer(st *state.SignedTransfer) {
 func (tb *testBalances) GetSignedTransfers() []*state.SignedTransfer {
 	return nil
 }
+func (tb *testBalances) EmitEvent(eventType string,
+	attributes map[string]string) {
+
+	tb.events = append(tb.events, transaction.NewEvent(eventType, attributes))
+}
+
 func (tb *testBalances) DeleteTrieNode(key datastore.Key) (
 	datastore.Key, error) {
 
@@ -92,6 +103,15 @@ func (tb *testBalances) GetSignatureScheme() encryption.SignatureScheme {
 	return encryption.NewBLS0ChainScheme()
 }
 
+// NewCallContext returns a copy sharing the balances and the state
+func (tb *testBalances) NewCallContext(
+	txn *transaction.Transaction) cstate.StateContextI {
+
+	var cc = *tb
+	cc.txn = txn
+	return &cc
+}
+
 func (tb *testBalances) GetClientBalance(clientID datastore.Key) (
 	b state.Balance, err error) {
 
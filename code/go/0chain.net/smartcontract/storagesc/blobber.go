@@ -14,6 +14,13 @@ import (
 
 const blobberHealthTime = 60 * 60 // 1 Hour
 
+// blobber statuses
+const (
+	blobberStatusActive   = "active"
+	blobberStatusDraining = "draining"
+	blobberStatusShutdown = "shutdown"
+)
+
 func (sc *StorageSmartContract) getBlobbersList(balances cstate.StateContextI) (*StorageNodes, error) {
 	allBlobbersList := &StorageNodes{}
 	allBlobbersBytes, err := balances.GetTrieNode(ALL_BLOBBERS_KEY)
@@ -110,6 +117,11 @@ func (sc *StorageSmartContract) updateBlobber(t *transaction.Transaction,
 	blobber.Used = existingBlobber.Used      // copy
 	blobber.LastHealthCheck = t.CreationDate // health
 
+	// keep draining, a shut down blobber is active again
+	if existingBlobber.Status == blobberStatusDraining {
+		blobber.Status = existingBlobber.Status
+	}
+
 	// update in the list, or add to the list if the blobber was removed before
 	all.Nodes.add(blobber)
 
@@ -213,6 +225,7 @@ func (sc *StorageSmartContract) addBlobber(t *transaction.Transaction,
 	// set transaction information
 	newBlobber.ID = t.ClientID
 	newBlobber.PublicKey = t.PublicKey
+	newBlobber.Status = blobberStatusActive
 
 	// check out stored
 	var existb util.Serializable
@@ -233,6 +246,10 @@ func (sc *StorageSmartContract) addBlobber(t *transaction.Transaction,
 	// insert blobber case
 	case err == util.ErrValueNotPresent:
 		err = sc.insertBlobber(t, conf, newBlobber, allBlobbersList, balances)
+		if err == nil {
+			err = sc.addToIndex(blobbersIndexName, newBlobber.ID,
+				allBlobbersList.Nodes.ids(), balances)
+		}
 
 	// update blobber case
 	default:
@@ -319,6 +336,11 @@ func (sc *StorageSmartContract) updateBlobberSettings(t *transaction.Transaction
 			"access denied, allowed for delegate_wallet owner only")
 	}
 
+	if blob.Status == blobberStatusShutdown {
+		return "", common.NewError("update_blobber_settings_failed",
+			"blobber is shut down, add it again to activate")
+	}
+
 	// stake pool settings
 
 	if err = update.StakePoolSettings.validate(conf); err != nil {
@@ -488,7 +510,8 @@ func (sc *StorageSmartContract) commitBlobberRead(t *transaction.Transaction,
 	}
 
 	resp, err = rp.moveToBlobber(sc.ID, commitRead.ReadMarker.AllocationID,
-		commitRead.ReadMarker.BlobberID, sp, t.CreationDate, value, balances)
+		commitRead.ReadMarker.BlobberID, commitRead.ReadMarker.ClientID,
+		userID, sp, t.CreationDate, value, balances)
 	if err != nil {
 		return "", common.NewErrorf("commit_blobber_read",
 			"can't transfer tokens from read pool to stake pool: %v", err)
@@ -706,3 +729,119 @@ func (sc *StorageSmartContract) commitBlobberConnection(
 	sc.newWrite(balances, commitConnection.WriteMarker.Size)
 	return string(detailsBytes), err
 }
+
+// blobber status request
+type blobberStatusRequest struct {
+	BlobberID string `json:"blobber_id"` // the client by default
+	Status    string `json:"status"`
+}
+
+func (bsr *blobberStatusRequest) decode(b []byte) (err error) {
+	if err = json.Unmarshal(b, bsr); err != nil {
+		return
+	}
+	switch bsr.Status {
+	case blobberStatusActive, blobberStatusDraining, blobberStatusShutdown:
+	default:
+		return fmt.Errorf("invalid status %q", bsr.Status)
+	}
+	return
+}
+
+// updateBlobberStatus changes status of a blobber, allowed for the blobber
+// and its delegate wallet. A draining blobber serves its allocations, but
+// isn't selected for new ones. A blobber can be shut down when all its
+// allocations have expired or finalized, thus it has no offers and its stake
+// pool can be unlocked; it's removed from all blobbers list then and can be
+// added again by add_blobber transaction.
+func (sc *StorageSmartContract) updateBlobberStatus(
+	t *transaction.Transaction, input []byte, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	var req blobberStatusRequest
+	if err = req.decode(input); err != nil {
+		return "", common.NewError("update_blobber_status_failed",
+			"malformed request: "+err.Error())
+	}
+	if req.BlobberID == "" {
+		req.BlobberID = t.ClientID
+	}
+
+	var blob *StorageNode
+	if blob, err = sc.getBlobber(req.BlobberID, balances); err != nil {
+		return "", common.NewError("update_blobber_status_failed",
+			"can't get the blobber: "+err.Error())
+	}
+
+	var sp *stakePool
+	if sp, err = sc.getStakePool(blob.ID, balances); err != nil {
+		return "", common.NewError("update_blobber_status_failed",
+			"can't get related stake pool: "+err.Error())
+	}
+
+	if t.ClientID != blob.ID && (sp.Settings.DelegateWallet == "" ||
+		t.ClientID != sp.Settings.DelegateWallet) {
+		return "", common.NewError("update_blobber_status_failed",
+			"access denied, allowed for the blobber and its delegate_wallet")
+	}
+
+	if blob.Status == blobberStatusShutdown {
+		return "", common.NewError("update_blobber_status_failed",
+			"blobber is shut down, add it again to activate")
+	}
+
+	var all *StorageNodes
+	if all, err = sc.getBlobbersList(balances); err != nil {
+		return "", common.NewError("update_blobber_status_failed",
+			"Failed to get blobber list: "+err.Error())
+	}
+
+	if req.Status == blobberStatusShutdown {
+		// every allocation of the blobber has an offer in its stake pool
+		// until the allocation expires, check out the allocations
+		for allocID := range sp.Offers {
+			var alloc *StorageAllocation
+			switch alloc, err = sc.getAllocation(allocID, balances); err {
+			case nil:
+			case util.ErrValueNotPresent:
+				delete(sp.Offers, allocID)
+				continue
+			default:
+				return "", common.NewError("update_blobber_status_failed",
+					"can't get allocation "+allocID+": "+err.Error())
+			}
+			var _, serves = alloc.BlobberMap[blob.ID]
+			if serves && !alloc.Finalized && !alloc.Canceled &&
+				alloc.Until() > t.CreationDate {
+				return "", common.NewError("update_blobber_status_failed",
+					"blobber has not expired allocations")
+			}
+			delete(sp.Offers, allocID) // expired, finalized or replaced
+		}
+		if err = sp.save(sc.ID, blob.ID, balances); err != nil {
+			return "", common.NewError("update_blobber_status_failed",
+				"saving stake pool: "+err.Error())
+		}
+		if all.Nodes.remove(blob.ID) {
+			sc.statIncr(statRemoveBlobber)
+			sc.statDecr(statNumberOfBlobbers)
+		}
+	}
+
+	blob.Status = req.Status
+	all.Nodes.update(blob)
+
+	_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, all)
+	if err != nil {
+		return "", common.NewError("update_blobber_status_failed",
+			"saving all blobbers: "+err.Error())
+	}
+	_, err = balances.InsertTrieNode(blob.GetKey(sc.ID), blob)
+	if err != nil {
+		return "", common.NewError("update_blobber_status_failed",
+			"saving blobber: "+err.Error())
+	}
+
+	sc.statIncr(statUpdateBlobber)
+	return string(blob.Encode()), nil
+}
@@ -274,7 +274,8 @@ func Test_flow_reward(t *testing.T) {
 		_, err = ssc.newReadPool(tx, nil, balances)
 		require.NoError(t, err)
 
-		// read pool lock
+		// read pool lock, sponsored for another reader, the payer, that
+		// isn't the allocation owner, still can spend it
 		tp += 100
 		tx = newTransaction(reader.id, ssc.ID,
 			int64(len(alloc.BlobberDetails))*2*x10, tp)
@@ -282,6 +283,9 @@ func Test_flow_reward(t *testing.T) {
 		_, err = ssc.readPoolLock(tx, mustEncode(t, &lockRequest{
 			Duration:     20 * time.Minute,
 			AllocationID: allocID,
+			Readers: []*readerLockRequest{
+				{ClientID: client.id, Limit: 1},
+			},
 		}), balances)
 		require.NoError(t, err)
 
@@ -816,6 +820,14 @@ func Test_flow_penalty(t *testing.T) {
 			require.NoError(t, err)
 			require.NotZero(t, resp)
 
+			// slashed and failed events
+			require.Len(t, balances.events, 2)
+			assert.Equal(t, EventBlobberSlashed, balances.events[0].Type)
+			assert.Equal(t, b4.id, balances.events[0].Attributes["blobber"])
+			assert.Equal(t, EventChallengeFailed, balances.events[1].Type)
+			assert.Equal(t, challID, balances.events[1].Attributes["challenge"])
+			assert.Equal(t, allocID, balances.events[1].Attributes["allocation"])
+
 			inspectCPIV(t, fmt.Sprintf("after challenge %d", i), ssc, allocID,
 				balances)
 
@@ -1470,3 +1482,143 @@ func Test_blobber_choose_randomization(t *testing.T) {
 	}
 
 }
+
+func TestStorageSmartContract_updateBlobberStatus(t *testing.T) {
+
+	var (
+		ssc            = newTestStorageSC()
+		balances       = newTestBalances(t, false)
+		client         = newClient(100*x10, balances)
+		tp, exp  int64 = 100, int64(toSeconds(time.Hour))
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var allocID, blobs = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+
+	var allocated, other, spare *Client
+	for _, b := range blobs {
+		if _, ok := alloc.BlobberMap[b.id]; ok {
+			allocated, other = b, allocated
+		} else {
+			spare = b
+		}
+	}
+	require.NotNil(t, allocated)
+	require.NotNil(t, other)
+	require.NotNil(t, spare)
+
+	var status = func(clientID, blobberID, status string) (err error) {
+		tp += 1
+		var tx = newTransaction(clientID, ssc.ID, 0, tp)
+		balances.setTransaction(t, tx)
+		_, err = ssc.updateBlobberStatus(tx, mustEncode(t,
+			&blobberStatusRequest{BlobberID: blobberID, Status: status}),
+			balances)
+		return
+	}
+
+	requireErrMsg(t, status(spare.id, "", "unknown"),
+		`update_blobber_status_failed: malformed request: `+
+			`invalid status "unknown"`)
+	requireErrMsg(t, status(client.id, spare.id, blobberStatusDraining),
+		"update_blobber_status_failed: "+
+			"access denied, allowed for the blobber and its delegate_wallet")
+
+	// draining blobber is not selected for new allocations
+	require.NoError(t, status(spare.id, "", blobberStatusDraining))
+
+	var all *StorageNodes
+	all, err = ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	var drained, ok = all.Nodes.get(spare.id)
+	require.True(t, ok)
+	assert.Equal(t, blobberStatusDraining, drained.Status)
+	assert.True(t, ssc.filterBlobbersByFreeSpace(common.Timestamp(tp), 1,
+		balances)(drained))
+
+	// re-registration keeps it draining
+	_, err = spare.callAddBlobber(t, ssc, tp, balances)
+	require.NoError(t, err)
+	var b *StorageNode
+	b, err = ssc.getBlobber(spare.id, balances)
+	require.NoError(t, err)
+	assert.Equal(t, blobberStatusDraining, b.Status)
+
+	require.NoError(t, status(spare.id, "", blobberStatusActive))
+	all, err = ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	drained, ok = all.Nodes.get(spare.id)
+	require.True(t, ok)
+	assert.False(t, ssc.filterBlobbersByFreeSpace(common.Timestamp(tp), 1,
+		balances)(drained))
+
+	// can't shut down serving an allocation
+	require.NoError(t, status(allocated.id, "", blobberStatusDraining))
+	requireErrMsg(t, status(allocated.id, "", blobberStatusShutdown),
+		"update_blobber_status_failed: blobber has not expired allocations")
+
+	// the allocation finalized
+	alloc.Finalized = true
+	mustSave(t, alloc.GetKey(ssc.ID), alloc, balances)
+	require.NoError(t, status(allocated.id, "", blobberStatusShutdown))
+	alloc.Finalized = false
+	mustSave(t, alloc.GetKey(ssc.ID), alloc, balances)
+
+	// the allocation expired
+	requireErrMsg(t, status(other.id, "", blobberStatusShutdown),
+		"update_blobber_status_failed: blobber has not expired allocations")
+	tp = exp + int64(toSeconds(alloc.ChallengeCompletionTime)) + 1
+	require.NoError(t, status(other.id, "", blobberStatusShutdown))
+
+	all, err = ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	_, ok = all.Nodes.get(allocated.id)
+	assert.False(t, ok)
+
+	requireErrMsg(t, status(allocated.id, "", blobberStatusActive),
+		"update_blobber_status_failed: "+
+			"blobber is shut down, add it again to activate")
+
+	// stake of the blobber can be unlocked, even if an offer left
+	var usp *userStakePools
+	usp, err = ssc.getUserStakePool(allocated.id, balances)
+	require.NoError(t, err)
+	require.Len(t, usp.Pools[allocated.id], 1)
+
+	var sp *stakePool
+	sp, err = ssc.getStakePool(allocated.id, balances)
+	require.NoError(t, err)
+	sp.Offers["stale"] = &offerPool{Lock: sp.stake(),
+		Expire: common.Timestamp(tp + exp)}
+	require.NoError(t, sp.save(ssc.ID, allocated.id, balances))
+
+	tp += 1
+	var tx = newTransaction(allocated.id, ssc.ID, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.stakePoolUnlock(tx, mustEncode(t, &stakePoolRequest{
+		BlobberID: allocated.id,
+		PoolID:    usp.Pools[allocated.id][0],
+	}), balances)
+	require.NoError(t, err)
+
+	sp, err = ssc.getStakePool(allocated.id, balances)
+	require.NoError(t, err)
+	assert.Len(t, sp.Pools, 0)
+
+	// add it again
+	_, err = allocated.callAddBlobber(t, ssc, tp, balances)
+	require.NoError(t, err)
+	b, err = ssc.getBlobber(allocated.id, balances)
+	require.NoError(t, err)
+	assert.True(t, b.isActive())
+	all, err = ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	_, ok = all.Nodes.get(allocated.id)
+	assert.True(t, ok)
+}
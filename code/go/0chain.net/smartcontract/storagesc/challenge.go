@@ -145,6 +145,13 @@ func (sc *StorageSmartContract) blobberReward(t *transaction.Transaction,
 	}
 	details.ChallengeReward += reward
 
+	// partially passed challenge is partially failed
+	err = sc.updateBlobberReputation(conf, bc.BlobberID, partial,
+		t.CreationDate, balances)
+	if err != nil {
+		return
+	}
+
 	// validators' stake pools
 	var vsps []*stakePool
 	if vsps, err = sc.validatorsStakePools(validators, balances); err != nil {
@@ -205,10 +212,11 @@ func (ssc *StorageSmartContract) saveStakePools(validators []datastore.Key,
 	return
 }
 
-// move tokens from challenge pool back to write pool
+// move tokens from challenge pool back to write pool; if closer of an
+// expired challenge given, then it's rewarded out of the blobber's slash
 func (sc *StorageSmartContract) blobberPenalty(t *transaction.Transaction,
 	alloc *StorageAllocation, prev common.Timestamp, bc *BlobberChallenge,
-	details *BlobberAllocation, validators []string,
+	details *BlobberAllocation, validators []string, closerID string,
 	balances c_state.StateContextI) (err error) {
 
 	var conf *scConfig
@@ -244,8 +252,12 @@ func (sc *StorageSmartContract) blobberPenalty(t *transaction.Transaction,
 		move = details.challenge(dtu, rdtu)
 	)
 
-	// part of this tokens goes to related validators
-	var validatorsReward = state.Balance(conf.ValidatorReward * float64(move))
+	// part of this tokens goes to related validators; an expired challenge
+	// has no validators and all its tokens go back to the write pool
+	var validatorsReward state.Balance
+	if len(validators) > 0 {
+		validatorsReward = state.Balance(conf.ValidatorReward * float64(move))
+	}
 	move -= validatorsReward
 
 	// validators' stake pools
@@ -277,6 +289,12 @@ func (sc *StorageSmartContract) blobberPenalty(t *transaction.Transaction,
 	alloc.MovedBack += move
 	details.Returned += move
 
+	err = sc.updateBlobberReputation(conf, bc.BlobberID, 0, t.CreationDate,
+		balances)
+	if err != nil {
+		return
+	}
+
 	// blobber stake penalty
 	if conf.BlobberSlash > 0 && move > 0 &&
 		state.Balance(conf.BlobberSlash*float64(move)) > 0 {
@@ -312,20 +330,45 @@ func (sc *StorageSmartContract) blobberPenalty(t *transaction.Transaction,
 				alloc.ID)
 		}
 
+		// the closer reward is paid out of the slash, the rest goes to
+		// the write pool
+		var reward state.Balance
+		if closerID != "" {
+			reward = conf.ExpiredChallengeReward
+			if reward > slash {
+				reward = slash
+			}
+			if reward > offer.Lock {
+				reward = offer.Lock
+			}
+		}
+		reward, err = sp.slashToClient(sc.ID, closerID, reward, balances)
+		if err != nil {
+			return fmt.Errorf("can't reward closer of expired challenge: %v",
+				err)
+		}
+		offer.Lock -= reward
+		details.Penalty += reward
+
 		var move state.Balance
 		move, err = sp.slash(alloc.ID, details.BlobberID, until, wp, offer.Lock,
-			slash)
+			slash-reward)
 		if err != nil {
 			return fmt.Errorf("can't move tokens to write pool: %v", err)
 		}
 
 		offer.Lock -= move      // subtract the offer stake
 		details.Penalty += move // penalty statistic
+		move += reward          // entire slash
 
 		// save stake pool
 		if err = sp.save(sc.ID, bc.BlobberID, balances); err != nil {
 			return fmt.Errorf("can't save blobber's stake pool: %v", err)
 		}
+
+		if move > 0 {
+			emitBlobberSlashed(alloc, bc.BlobberID, int64(move), balances)
+		}
 	}
 
 	// save pools
@@ -469,6 +512,8 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 			return "", common.NewError("challenge_reward_error", err.Error())
 		}
 
+		emitChallengeResolved(EventChallengePassed, challReq.ID, alloc,
+			details.BlobberID, balances)
 		if success < threshold {
 			return "challenge passed partially by blobber", nil
 		}
@@ -503,7 +548,7 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 		Logger.Info("Challenge failed", zap.Any("challenge", challResp.ID))
 
 		err = sc.blobberPenalty(t, alloc, prev, blobberChall, details,
-			validators, balances)
+			validators, "", balances)
 		if err != nil {
 			return "", common.NewError("challenge_penalty_error", err.Error())
 		}
@@ -514,6 +559,8 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 			return "", common.NewError("challenge_reward_error", err.Error())
 		}
 
+		emitChallengeResolved(EventChallengeFailed, challReq.ID, alloc,
+			details.BlobberID, balances)
 		if pass && !fresh {
 			return "late challenge (failed)", nil
 		}
@@ -617,6 +664,23 @@ func (sc *StorageSmartContract) generateChallenges(t *transaction.Transaction,
 			"not enough validators for the challenge")
 	}
 
+	err = sc.startHealthChecks(validators, t.CreationDate, balances)
+	if err != nil {
+		return common.NewErrorf("adding_challenge_error",
+			"saving the validators list: %v", err)
+	}
+
+	// dead validators are not selected for challenges; if all of them are
+	// dead, then no challenges generated, but the markers are accepted
+	validators.Nodes = filterHealthyValidators(validators.Nodes,
+		validators.HealthChecksSince, t.CreationDate)
+
+	if len(validators.Nodes) == 0 {
+		Logger.Info("generate_challenges: no healthy validators",
+			zap.String("txn", t.Hash))
+		return nil
+	}
+
 	var all *Allocations
 	if all, err = sc.getAllAllocationsList(balances); err != nil {
 		return common.NewErrorf("adding_challenge_error",
@@ -739,6 +803,17 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 			alloc.ID, blobberAllocation.BlobberID)
 	}
 
+	return sc.addBlobberChallenge(alloc, selectedBlobberObj, blobberAllocation,
+		validators, challengeID, creationDate, r, challengeSeed, balances)
+}
+
+// addBlobberChallenge adds challenge for given blobber of an allocation
+func (sc *StorageSmartContract) addBlobberChallenge(alloc *StorageAllocation,
+	selectedBlobberObj *StorageNode, blobberAllocation *BlobberAllocation,
+	validators *ValidatorNodes, challengeID string,
+	creationDate common.Timestamp, r *rand.Rand, challengeSeed int64,
+	balances c_state.StateContextI) (resp string, err error) {
+
 	selectedValidators := make([]*ValidationNode, 0)
 	perm := r.Perm(minInt(len(validators.Nodes), alloc.DataShards+1))
 	for _, v := range perm {
@@ -791,3 +866,430 @@ func (sc *StorageSmartContract) addChallenge(alloc *StorageAllocation,
 	sc.newChallenge(balances, storageChallenge.Created)
 	return string(challengeBytes), err
 }
+
+//
+// per round challenges
+//
+
+func challengeScheduleKey(scKey string) datastore.Key {
+	return datastore.Key(scKey + ":challengeschedule")
+}
+
+// challengeSchedule is state of per round challenges generation
+type challengeSchedule struct {
+	LastRound int64            `json:"last_round"`
+	LastTime  common.Timestamp `json:"last_time"`
+	// Pending is fractional part of challenges expected, carried to next
+	// rounds, thus small rates generate challenges too
+	Pending float64 `json:"pending"`
+}
+
+// Encode implements util.Serializable interface.
+func (cs *challengeSchedule) Encode() []byte {
+	var b, err = json.Marshal(cs)
+	if err != nil {
+		panic(err) // must never happens
+	}
+	return b
+}
+
+// Decode implements util.Serializable interface.
+func (cs *challengeSchedule) Decode(p []byte) error {
+	return json.Unmarshal(p, cs)
+}
+
+func (sc *StorageSmartContract) getChallengeSchedule(
+	balances c_state.StateContextI) (cs *challengeSchedule, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(challengeScheduleKey(sc.ID))
+	if err == util.ErrValueNotPresent {
+		return new(challengeSchedule), nil
+	}
+	if err != nil {
+		return
+	}
+	cs = new(challengeSchedule)
+	if err = cs.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("decoding challenge schedule: %v", err)
+	}
+	return
+}
+
+// getStorageStats returns total storage stats of the SC
+func (sc *StorageSmartContract) getStorageStats(
+	balances c_state.StateContextI) (stats *StorageStats, err error) {
+
+	stats = &StorageStats{Stats: &StorageAllocationStats{}}
+	var val util.Serializable
+	val, err = balances.GetTrieNode(stats.GetKey(sc.ID))
+	if err == util.ErrValueNotPresent {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = stats.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("decoding storage stats: %v", err)
+	}
+	return
+}
+
+// expected number of challenges for given stored size and time passed;
+// the rest is pending challenges
+func (cs *challengeSchedule) expected(rate float64, size int64,
+	now common.Timestamp) (n int64) {
+
+	var hours = float64(now-cs.LastTime) / float64(60*60)
+	var total = cs.Pending + rate*sizeInGB(size)*hours
+	n = int64(total)
+	cs.Pending = total - float64(n)
+	return
+}
+
+// selectChallengedBlobber returns a blobber of the allocation, with
+// probability proportional to data stored by it; nil if the allocation
+// has no data
+func selectChallengedBlobber(alloc *StorageAllocation, r *rand.Rand) (
+	details *BlobberAllocation) {
+
+	var total int64
+	for _, d := range alloc.BlobberDetails {
+		if d.Stats != nil && d.AllocationRoot != "" {
+			total += d.Stats.UsedSize
+		}
+	}
+	if total <= 0 {
+		return
+	}
+	var pos = r.Int63n(total)
+	for _, d := range alloc.BlobberDetails {
+		if d.Stats == nil || d.AllocationRoot == "" {
+			continue
+		}
+		if pos < d.Stats.UsedSize {
+			return d
+		}
+		pos -= d.Stats.UsedSize
+	}
+	return
+}
+
+// roundChallengesRequest is input of generate_round_challenges
+type roundChallengesRequest struct {
+	Round int64 `json:"round"`
+}
+
+// generateRoundChallenges is protocol level transaction of block generator
+// that generates challenges every round; number of the challenges depends
+// on time passed and total data stored, not on read or write markers;
+// allocations and blobbers selected using round random seed
+func (sc *StorageSmartContract) generateRoundChallenges(
+	t *transaction.Transaction, input []byte,
+	balances c_state.StateContextI) (resp string, err error) {
+
+	var req roundChallengesRequest
+	if err = json.Unmarshal(input, &req); err != nil {
+		return "", common.NewError("generate_round_challenges_failed",
+			"invalid request: "+err.Error())
+	}
+
+	var b = balances.GetBlock()
+	if t.ClientID != b.MinerID {
+		return "", common.NewError("generate_round_challenges_failed",
+			"not block generator")
+	}
+	if req.Round != b.Round {
+		return "", common.NewError("generate_round_challenges_failed",
+			"invalid round")
+	}
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, true); err != nil {
+		return "", common.NewError("generate_round_challenges_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	if !conf.roundChallengesEnabled() {
+		return "per round challenges disabled", nil
+	}
+
+	var cs *challengeSchedule
+	if cs, err = sc.getChallengeSchedule(balances); err != nil {
+		return "", common.NewError("generate_round_challenges_failed",
+			"can't get challenge schedule: "+err.Error())
+	}
+
+	if b.Round <= cs.LastRound {
+		return "", common.NewError("generate_round_challenges_failed",
+			"challenges of the round already generated")
+	}
+
+	var n int64
+	if cs.LastTime > 0 && t.CreationDate > cs.LastTime {
+		var stats *StorageStats
+		if stats, err = sc.getStorageStats(balances); err != nil {
+			return "", common.NewError("generate_round_challenges_failed",
+				"can't get storage stats: "+err.Error())
+		}
+		n = cs.expected(conf.ChallengesPerGBPerHour, stats.Stats.UsedSize,
+			t.CreationDate)
+		if n > int64(conf.MaxChallengesPerGeneration) {
+			n = int64(conf.MaxChallengesPerGeneration)
+		}
+	}
+	if t.CreationDate > cs.LastTime {
+		cs.LastTime = t.CreationDate
+	}
+	cs.LastRound = b.Round
+
+	_, err = balances.InsertTrieNode(challengeScheduleKey(sc.ID), cs)
+	if err != nil {
+		return "", common.NewError("generate_round_challenges_failed",
+			"saving challenge schedule: "+err.Error())
+	}
+
+	if n == 0 {
+		return "no challenges generated", nil
+	}
+
+	var validators *ValidatorNodes
+	if validators, err = sc.getValidatorsList(balances); err != nil {
+		return "", common.NewError("generate_round_challenges_failed",
+			"can't get validators list: "+err.Error())
+	}
+	err = sc.startHealthChecks(validators, t.CreationDate, balances)
+	if err != nil {
+		return "", common.NewError("generate_round_challenges_failed",
+			"can't save validators list: "+err.Error())
+	}
+	validators.Nodes = filterHealthyValidators(validators.Nodes,
+		validators.HealthChecksSince, t.CreationDate)
+
+	var all *Allocations
+	if all, err = sc.getAllAllocationsList(balances); err != nil {
+		return "", common.NewError("generate_round_challenges_failed",
+			"can't get allocations list: "+err.Error())
+	}
+
+	if len(validators.Nodes) == 0 || len(all.List) == 0 {
+		return "no challenges generated", nil
+	}
+
+	var (
+		seed = strconv.FormatInt(b.Round, 10) + ":" +
+			strconv.FormatInt(b.GetRoundRandomSeed(), 10)
+		hashString = encryption.Hash(seed)
+		r          = rand.New(rand.NewSource(b.GetRoundRandomSeed()))
+		added      int
+	)
+
+	for i := int64(0); i < n; i++ {
+
+		var alloc *StorageAllocation
+		alloc, err = sc.getAllocation(all.List[r.Intn(len(all.List))],
+			balances)
+		if err == util.ErrValueNotPresent {
+			continue // invalid list
+		}
+		if err != nil {
+			return "", common.NewError("generate_round_challenges_failed",
+				"can't get allocation: "+err.Error())
+		}
+		if alloc.Expiration < t.CreationDate || alloc.Stats == nil {
+			continue
+		}
+
+		var details = selectChallengedBlobber(alloc, r)
+		if details == nil {
+			continue // no data stored
+		}
+
+		var blobber *StorageNode
+		for _, ab := range alloc.Blobbers {
+			if ab.ID == details.BlobberID {
+				blobber = ab
+				break
+			}
+		}
+		if blobber == nil {
+			continue // invalid allocation
+		}
+
+		var (
+			challengeID   = encryption.Hash(hashString + strconv.FormatInt(i, 10))
+			challengeSeed uint64
+		)
+		challengeSeed, err = strconv.ParseUint(challengeID[0:16], 16, 64)
+		if err != nil {
+			return "", common.NewError("generate_round_challenges_failed",
+				"creating challenge seed: "+err.Error())
+		}
+
+		_, err = sc.addBlobberChallenge(alloc, blobber, details, validators,
+			challengeID, t.CreationDate, r, int64(challengeSeed), balances)
+		if err != nil {
+			Logger.Error("Error in adding challenge", zap.Error(err))
+			continue
+		}
+		added++
+	}
+
+	return fmt.Sprintf("%d challenges generated", added), nil
+}
+
+// maxExpiredChallengesPerClose is max number of expired challenges closed
+// by a close_expired_challenges transaction
+const maxExpiredChallengesPerClose = 50
+
+// closeExpiredChallengesRequest of close_expired_challenges
+type closeExpiredChallengesRequest struct {
+	BlobberID string `json:"blobber_id"`
+}
+
+// closeExpiredChallenge closes given open challenge (the first one of the
+// blobber) as failed and penalizes the blobber; the closed is false for a
+// challenge of removed, finalized or cancelled allocation, or if the blobber
+// is not part of the allocation anymore, since such challenges are already
+// adjusted and only removed from the list
+func (sc *StorageSmartContract) closeExpiredChallenge(
+	t *transaction.Transaction, bc *BlobberChallenge, c *StorageChallenge,
+	alloc *StorageAllocation, balances c_state.StateContextI) (closed bool,
+	err error) {
+
+	// time of previous complete challenge (not the current one)
+	// or allocation start time if no challenges
+	var prev common.Timestamp
+	if last := bc.LatestCompletedChallenge; last != nil {
+		prev = last.Created
+	}
+
+	sc.completeChallengeForBlobber(bc, c, &ChallengeResponse{ID: c.ID})
+
+	if alloc == nil || alloc.Finalized || alloc.Canceled {
+		return false, nil
+	}
+	var details, ok = alloc.BlobberMap[bc.BlobberID]
+	if !ok {
+		return false, nil
+	}
+	if prev == 0 {
+		prev = alloc.StartTime
+	}
+
+	alloc.Stats.LastestClosedChallengeTxn = c.ID
+	alloc.Stats.FailedChallenges++
+	alloc.Stats.OpenChallenges--
+
+	details.Stats.LastestClosedChallengeTxn = c.ID
+	details.Stats.FailedChallenges++
+	details.Stats.OpenChallenges--
+
+	sc.challengeResolved(balances, false)
+
+	err = sc.blobberPenalty(t, alloc, prev, bc, details, nil, t.ClientID,
+		balances)
+	if err != nil {
+		return false, fmt.Errorf("penalizing blobber: %v", err)
+	}
+
+	_, err = balances.InsertTrieNode(alloc.GetKey(sc.ID), alloc)
+	if err != nil {
+		return false, fmt.Errorf("saving allocation: %v", err)
+	}
+
+	emitChallengeResolved(EventChallengeFailed, c.ID, alloc,
+		details.BlobberID, balances)
+	return true, nil
+}
+
+// closeExpiredChallenges is permissionless transaction that closes open
+// challenges of a blobber not answered in allocation's challenge completion
+// time; the challenges are failed, the blobber penalized, and the caller
+// rewarded out of the blobber's slash for every challenge closed; challenges
+// closed in order, an expired challenge after a not expired one stays open;
+// the blobber and its delegate wallet can't close its challenges
+func (sc *StorageSmartContract) closeExpiredChallenges(
+	t *transaction.Transaction, input []byte,
+	balances c_state.StateContextI) (resp string, err error) {
+
+	var req closeExpiredChallengesRequest
+	if err = json.Unmarshal(input, &req); err != nil {
+		return "", common.NewError("close_expired_challenges_failed",
+			"invalid request: "+err.Error())
+	}
+
+	if t.ClientID == req.BlobberID {
+		return "", common.NewError("close_expired_challenges_failed",
+			"blobber can't close its own challenges")
+	}
+	var sp *stakePool
+	sp, err = sc.getStakePool(req.BlobberID, balances)
+	if err != nil && err != util.ErrValueNotPresent {
+		return "", common.NewError("close_expired_challenges_failed",
+			"can't get stake pool of the blobber: "+err.Error())
+	}
+	if sp != nil && sp.Settings.DelegateWallet == t.ClientID {
+		return "", common.NewError("close_expired_challenges_failed",
+			"delegate wallet of blobber can't close its challenges")
+	}
+
+	var bc *BlobberChallenge
+	bc, err = sc.getBlobberChallenge(req.BlobberID, balances)
+	if err == util.ErrValueNotPresent {
+		return "", common.NewError("close_expired_challenges_failed",
+			"no challenges of the blobber")
+	}
+	if err != nil {
+		return "", common.NewError("close_expired_challenges_failed",
+			"can't get blobber challenges: "+err.Error())
+	}
+
+	var closed, removed int
+	for len(bc.Challenges) > 0 &&
+		closed+removed < maxExpiredChallengesPerClose {
+
+		var (
+			c     = bc.Challenges[0]
+			alloc *StorageAllocation
+		)
+		alloc, err = sc.getAllocation(c.AllocationID, balances)
+		if err != nil && err != util.ErrValueNotPresent {
+			return "", common.NewError("close_expired_challenges_failed",
+				"can't get related allocation: "+err.Error())
+		}
+		if alloc != nil && !alloc.Finalized && !alloc.Canceled {
+			var details, ok = alloc.BlobberMap[bc.BlobberID]
+			if ok && c.Created+toSeconds(
+				details.Terms.ChallengeCompletionTime) >= t.CreationDate {
+
+				break // not expired yet
+			}
+		}
+
+		var ok bool
+		ok, err = sc.closeExpiredChallenge(t, bc, c, alloc, balances)
+		if err != nil {
+			return "", common.NewError("close_expired_challenges_failed",
+				err.Error())
+		}
+		if ok {
+			closed++
+		} else {
+			removed++
+		}
+	}
+
+	if closed+removed == 0 {
+		return "", common.NewError("close_expired_challenges_failed",
+			"no expired challenges")
+	}
+
+	_, err = balances.InsertTrieNode(bc.GetKey(sc.ID), bc)
+	if err != nil {
+		return "", common.NewError("close_expired_challenges_failed",
+			"saving blobber challenges: "+err.Error())
+	}
+
+	return fmt.Sprintf("%d expired challenges closed", closed+removed), nil
+}
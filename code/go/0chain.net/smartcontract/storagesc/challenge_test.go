@@ -0,0 +1,286 @@
+package storagesc
+
+import (
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_challengeSchedule_expected(t *testing.T) {
+	var cs = challengeSchedule{LastTime: 100}
+	// 1.5 GB for 1 hour, 2 challenges per GB per hour
+	assert.EqualValues(t, 3, cs.expected(2, 1.5*GB, 100+60*60))
+	assert.Zero(t, cs.Pending)
+	// 0.25 GB for 1 hour
+	assert.EqualValues(t, 0, cs.expected(2, GB/4, 100+60*60))
+	assert.InDelta(t, 0.5, cs.Pending, 1e-9)
+	assert.EqualValues(t, 1, cs.expected(2, GB/4, 100+60*60))
+	assert.InDelta(t, 0, cs.Pending, 1e-9)
+}
+
+func TestRoundChallengesEnabled(t *testing.T) {
+	var enabled = func(conf scConfig) bool {
+		var ok, err = RoundChallengesEnabled(conf.Encode())
+		require.NoError(t, err)
+		return ok
+	}
+	var conf = scConfig{ChallengeEnabled: true, ChallengesPerGBPerHour: 2}
+	assert.True(t, enabled(conf))
+	conf.ChallengesPerGBPerHour = 0
+	assert.False(t, enabled(conf))
+	conf.ChallengesPerGBPerHour, conf.ChallengeEnabled = 2, false
+	assert.False(t, enabled(conf))
+	var _, err = RoundChallengesEnabled([]byte("}{"))
+	assert.Error(t, err)
+}
+
+func TestStorageSmartContract_markersChallengesEnabled(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		conf     = setConfig(t, balances)
+	)
+
+	var enabled, err = ssc.markersChallengesEnabled(balances)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	conf.ChallengesPerGBPerHour = 20
+	mustSave(t, scConfigKey(ssc.ID), conf, balances)
+	enabled, err = ssc.markersChallengesEnabled(balances)
+	require.NoError(t, err)
+	assert.False(t, enabled, "round challenges enabled")
+
+	conf.ChallengesPerGBPerHour, conf.ChallengeEnabled = 0, false
+	mustSave(t, scConfigKey(ssc.ID), conf, balances)
+	enabled, err = ssc.markersChallengesEnabled(balances)
+	require.NoError(t, err)
+	assert.False(t, enabled, "challenges disabled")
+}
+
+func TestStorageSmartContract_generateRoundChallenges(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		miner    = newClient(0, balances)
+		tp, exp  = int64(100), int64(toSeconds(time.Hour))
+		resp     string
+		err      error
+	)
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var conf = setConfig(t, balances)
+	conf.ChallengesPerGBPerHour = 20
+	mustSave(t, scConfigKey(ssc.ID), conf, balances)
+
+	// only the first blobber of the allocation stores data
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	var details = alloc.BlobberDetails[0]
+	details.AllocationRoot = "root"
+	details.Stats = &StorageAllocationStats{UsedSize: 1 * GB}
+	alloc.Stats = &StorageAllocationStats{UsedSize: 1 * GB, NumWrites: 1}
+	mustSave(t, alloc.GetKey(ssc.ID), alloc, balances)
+
+	var stats = &StorageStats{Stats: &StorageAllocationStats{UsedSize: GB}}
+	mustSave(t, stats.GetKey(ssc.ID), stats, balances)
+
+	var generate = func(from string, round, now int64) (string, error) {
+		balances.block = &block.Block{}
+		balances.block.MinerID = miner.id
+		balances.block.Round = round
+		balances.block.CreationDate = common.Timestamp(now)
+		balances.block.SetRoundRandomSeed(round * 7)
+		var tx = newTransaction(from, ssc.ID, 0, now)
+		balances.setTransaction(t, tx)
+		return ssc.generateRoundChallenges(tx,
+			mustEncode(t, &roundChallengesRequest{Round: round}), balances)
+	}
+
+	// first round starts the schedule
+	resp, err = generate(miner.id, 1, tp)
+	require.NoError(t, err)
+	assert.Equal(t, "no challenges generated", resp)
+
+	_, err = generate(miner.id, 1, tp)
+	requireErrMsg(t, err, "generate_round_challenges_failed: "+
+		"challenges of the round already generated")
+
+	_, err = generate(client.id, 2, tp)
+	requireErrMsg(t, err, "generate_round_challenges_failed: "+
+		"not block generator")
+
+	// half an hour later, 10 challenges for the stored GB
+	tp += 30 * 60
+	for i := 0; i < 5; i++ {
+		addValidator(t, ssc, tp, balances)
+	}
+	resp, err = generate(miner.id, 2, tp)
+	require.NoError(t, err)
+	assert.Equal(t, "10 challenges generated", resp)
+
+	var bc *BlobberChallenge
+	bc, err = ssc.getBlobberChallenge(details.BlobberID, balances)
+	require.NoError(t, err)
+	assert.Len(t, bc.Challenges, 10)
+	for _, c := range bc.Challenges {
+		assert.Equal(t, allocID, c.AllocationID)
+		assert.Equal(t, details.BlobberID, c.Blobber.ID)
+		assert.NotEmpty(t, c.Validators)
+	}
+
+	// a second later, nothing
+	tp++
+	resp, err = generate(miner.id, 3, tp)
+	require.NoError(t, err)
+	assert.Equal(t, "no challenges generated", resp)
+
+	// disabled
+	conf.ChallengesPerGBPerHour = 0
+	mustSave(t, scConfigKey(ssc.ID), conf, balances)
+	resp, err = generate(miner.id, 4, tp+60*60)
+	require.NoError(t, err)
+	assert.Equal(t, "per round challenges disabled", resp)
+}
+
+func TestStorageSmartContract_closeExpiredChallenges(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		miner    = newClient(0, balances)
+		closer   = newClient(0, balances)
+		tp, exp  = int64(100), int64(toSeconds(time.Hour))
+		resp     string
+		err      error
+	)
+
+	var allocID, _ = addAllocation(t, ssc, client, tp, exp, 0, balances)
+
+	var conf = setConfig(t, balances)
+	conf.ChallengesPerGBPerHour = 20
+	conf.ExpiredChallengeReward = 1e6
+	conf.BlobberSlash = 0.5
+	mustSave(t, scConfigKey(ssc.ID), conf, balances)
+
+	var alloc *StorageAllocation
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	var details = alloc.BlobberDetails[0]
+	details.AllocationRoot = "root"
+	details.Stats = &StorageAllocationStats{UsedSize: 1 * GB}
+	details.ChallengePoolIntegralValue = 10 * x10
+	alloc.Stats = &StorageAllocationStats{UsedSize: 1 * GB, NumWrites: 1}
+	mustSave(t, alloc.GetKey(ssc.ID), alloc, balances)
+
+	var cp *challengePool
+	cp, err = ssc.getChallengePool(allocID, balances)
+	require.NoError(t, err)
+	cp.Balance = 10 * x10
+	require.NoError(t, cp.save(ssc.ID, allocID, balances))
+
+	var stats = &StorageStats{Stats: &StorageAllocationStats{UsedSize: GB}}
+	mustSave(t, stats.GetKey(ssc.ID), stats, balances)
+
+	var generate = func(round, now int64) (string, error) {
+		balances.block = &block.Block{}
+		balances.block.MinerID = miner.id
+		balances.block.Round = round
+		balances.block.CreationDate = common.Timestamp(now)
+		balances.block.SetRoundRandomSeed(round * 7)
+		var tx = newTransaction(miner.id, ssc.ID, 0, now)
+		balances.setTransaction(t, tx)
+		return ssc.generateRoundChallenges(tx,
+			mustEncode(t, &roundChallengesRequest{Round: round}), balances)
+	}
+
+	var closeExpiredBy = func(clientID string, now int64) (string, error) {
+		var tx = newTransaction(clientID, ssc.ID, 0, now)
+		balances.setTransaction(t, tx)
+		return ssc.closeExpiredChallenges(tx,
+			mustEncode(t, &closeExpiredChallengesRequest{
+				BlobberID: details.BlobberID,
+			}), balances)
+	}
+	var closeExpired = func(now int64) (string, error) {
+		return closeExpiredBy(closer.id, now)
+	}
+
+	_, err = generate(1, tp)
+	require.NoError(t, err)
+	tp += 30 * 60
+	for i := 0; i < 5; i++ {
+		addValidator(t, ssc, tp, balances)
+	}
+	resp, err = generate(2, tp)
+	require.NoError(t, err)
+	require.Equal(t, "10 challenges generated", resp)
+
+	// not expired yet
+	_, err = closeExpired(tp + 1)
+	requireErrMsg(t, err, "close_expired_challenges_failed: "+
+		"no expired challenges")
+
+	// the blobber and its delegate wallet can't close its challenges
+	_, err = closeExpiredBy(details.BlobberID, tp+201)
+	requireErrMsg(t, err, "close_expired_challenges_failed: "+
+		"blobber can't close its own challenges")
+	var sp *stakePool
+	sp, err = ssc.getStakePool(details.BlobberID, balances)
+	require.NoError(t, err)
+	sp.Settings.DelegateWallet = "delegate"
+	require.NoError(t, sp.save(ssc.ID, details.BlobberID, balances))
+	_, err = closeExpiredBy("delegate", tp+201)
+	requireErrMsg(t, err, "close_expired_challenges_failed: "+
+		"delegate wallet of blobber can't close its challenges")
+
+	resp, err = closeExpired(tp + 201)
+	require.NoError(t, err)
+	assert.Equal(t, "10 expired challenges closed", resp)
+
+	var bc *BlobberChallenge
+	bc, err = ssc.getBlobberChallenge(details.BlobberID, balances)
+	require.NoError(t, err)
+	assert.Len(t, bc.Challenges, 0)
+
+	alloc, err = ssc.getAllocation(allocID, balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, alloc.Stats.FailedChallenges)
+	assert.EqualValues(t, 0, alloc.Stats.OpenChallenges)
+	assert.EqualValues(t, 10, alloc.BlobberDetails[0].Stats.FailedChallenges)
+	assert.NotZero(t, alloc.MovedBack)
+	assert.Zero(t, alloc.MovedToValidators)
+
+	cp, err = ssc.getChallengePool(allocID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, 10*x10-alloc.MovedBack, cp.Balance)
+
+	// the reward is paid out of the blobber's slash, challenges without
+	// tokens moved back to the write pool aren't slashed nor rewarded
+	var reward = balances.balances[closer.id]
+	assert.True(t, reward > 0)
+	assert.True(t, reward <= 10*conf.ExpiredChallengeReward)
+	assert.True(t, alloc.BlobberDetails[0].Penalty > reward)
+	sp, err = ssc.getStakePool(details.BlobberID, balances)
+	require.NoError(t, err)
+	var penalty state.Balance
+	for _, dp := range sp.Pools {
+		penalty += dp.Penalty
+	}
+	assert.Equal(t, alloc.BlobberDetails[0].Penalty, penalty)
+
+	_, err = closeExpired(tp + 202)
+	requireErrMsg(t, err, "close_expired_challenges_failed: "+
+		"no expired challenges")
+}
@@ -20,6 +20,23 @@ func scConfigKey(scKey string) datastore.Key {
 	return datastore.Key(scKey + ":configurations")
 }
 
+// ConfigKey is key of the storage SC configurations in the state.
+func ConfigKey() datastore.Key {
+	return scConfigKey(ADDRESS)
+}
+
+// RoundChallengesEnabled is true if challenges generated every round by the
+// block generator w.r.t. given encoded storage SC configurations stored in
+// a state; the block generator and verifiers check it out against state of
+// previous block, that is the same for all of them.
+func RoundChallengesEnabled(confb []byte) (bool, error) {
+	var conf scConfig
+	if err := conf.Decode(confb); err != nil {
+		return false, err
+	}
+	return conf.roundChallengesEnabled(), nil
+}
+
 // stake pool configs
 
 type stakePoolConfig struct {
@@ -45,6 +62,10 @@ type writePoolConfig struct {
 	MaxLockPeriod time.Duration `json:"max_lock_period"`
 }
 
+// default reputation configurations, for configurations without the
+// reputation section
+var defaultReputationConfig = reputationConfig{}
+
 // scConfig represents SC configurations ('storagesc:' from sc.yaml).
 type scConfig struct {
 	// TimeUnit is a duration used as divider for a write price. A write price
@@ -108,6 +129,13 @@ type scConfig struct {
 	MaxChallengesPerGeneration int `json:"max_challenges_per_generation"`
 	// ChallengeGenerationRate is number of challenges generated for a MB/min.
 	ChallengeGenerationRate float64 `json:"challenge_rate_per_mb_min"`
+	// ChallengesPerGBPerHour is target number of challenges generated every
+	// round by block generator for a GB of stored data per hour. Zero
+	// means challenges generated by read and write markers.
+	ChallengesPerGBPerHour float64 `json:"challenges_per_gb_hour"`
+	// ExpiredChallengeReward is paid, out of the blobber's slash, to a client
+	// closes an expired challenge not answered by its blobber.
+	ExpiredChallengeReward state.Balance `json:"expired_challenge_reward"`
 
 	// MinStake allowed by a blobber/validator (entire SC boundary).
 	MinStake state.Balance `json:"min_stake"`
@@ -119,6 +147,9 @@ type scConfig struct {
 
 	// MaxCharge that blobber gets from rewards to its delegate_wallet.
 	MaxCharge float64 `json:"max_charge"`
+
+	// Reputation of blobbers related configurations.
+	Reputation *reputationConfig `json:"reputation,omitempty"`
 }
 
 func (sc *scConfig) validate() (err error) {
@@ -189,6 +220,14 @@ func (sc *scConfig) validate() (err error) {
 		return fmt.Errorf("negative challenge_rate_per_mb_min: %v",
 			sc.ChallengeGenerationRate)
 	}
+	if sc.ChallengesPerGBPerHour < 0 {
+		return fmt.Errorf("negative challenges_per_gb_hour: %v",
+			sc.ChallengesPerGBPerHour)
+	}
+	if sc.ExpiredChallengeReward < 0 {
+		return fmt.Errorf("negative expired_challenge_reward: %v",
+			sc.ExpiredChallengeReward)
+	}
 	if sc.MinStake < 0 {
 		return fmt.Errorf("negative min_stake: %v", sc.MinStake)
 	}
@@ -206,9 +245,28 @@ func (sc *scConfig) validate() (err error) {
 		return fmt.Errorf("max_change >= 1.0 (> 100%%, invalid): %v",
 			sc.MaxCharge)
 	}
+	if sc.Reputation != nil {
+		if err = sc.Reputation.validate(); err != nil {
+			return
+		}
+	}
 	return
 }
 
+// reputation configurations or default ones
+func (conf *scConfig) reputation() *reputationConfig {
+	if conf.Reputation == nil {
+		return &defaultReputationConfig
+	}
+	return conf.Reputation
+}
+
+// roundChallengesEnabled is true if challenges generated every round by
+// the block generator instead of read and write markers
+func (conf *scConfig) roundChallengesEnabled() bool {
+	return conf.ChallengeEnabled && conf.ChallengesPerGBPerHour > 0
+}
+
 func (conf *scConfig) canMint() bool {
 	return conf.Minted < conf.MaxMint
 }
@@ -311,9 +369,17 @@ func getConfiguredConfig() (conf *scConfig, err error) {
 		pfx + "max_challenges_per_generation")
 	conf.ChallengeGenerationRate = scc.GetFloat64(
 		pfx + "challenge_rate_per_mb_min")
+	conf.ChallengesPerGBPerHour = scc.GetFloat64(
+		pfx + "challenges_per_gb_hour")
+	conf.ExpiredChallengeReward = state.Balance(
+		scc.GetFloat64(pfx+"expired_challenge_reward") * 1e10)
 
 	conf.MaxDelegates = scc.GetInt(pfx + "max_delegates")
 	conf.MaxCharge = scc.GetFloat64(pfx + "max_charge")
+	// reputation
+	conf.Reputation = new(reputationConfig)
+	conf.Reputation.HalfLife = scc.GetDuration(pfx + "reputation.half_life")
+	conf.Reputation.MinScore = scc.GetFloat64(pfx + "reputation.min_score")
 
 	err = conf.validate()
 	return
@@ -0,0 +1,70 @@
+package storagesc
+
+import (
+	"strconv"
+
+	chainstate "0chain.net/chaincore/chain/state"
+)
+
+// types of the events emitted by the storage SC
+const (
+	EventAllocationCreated = "allocation_created"
+	EventChallengePassed   = "challenge_passed"
+	EventChallengeFailed   = "challenge_failed"
+	EventBlobberSlashed    = "blobber_slashed"
+
+	EventAllocationTransferred = "allocation_transferred"
+	EventBlobberReplaced       = "blobber_replaced"
+)
+
+func emitAllocationCreated(sa *StorageAllocation,
+	balances chainstate.StateContextI) {
+
+	balances.EmitEvent(EventAllocationCreated, map[string]string{
+		"allocation": sa.ID,
+		"owner":      sa.Owner,
+		"size":       strconv.FormatInt(sa.Size, 10),
+		"expiration": strconv.FormatInt(int64(sa.Expiration), 10),
+	})
+}
+
+func emitChallengeResolved(eventType string, challengeID string,
+	alloc *StorageAllocation, blobberID string,
+	balances chainstate.StateContextI) {
+
+	balances.EmitEvent(eventType, map[string]string{
+		"challenge":  challengeID,
+		"allocation": alloc.ID,
+		"blobber":    blobberID,
+	})
+}
+
+func emitBlobberSlashed(alloc *StorageAllocation, blobberID string,
+	amount int64, balances chainstate.StateContextI) {
+
+	balances.EmitEvent(EventBlobberSlashed, map[string]string{
+		"allocation": alloc.ID,
+		"blobber":    blobberID,
+		"amount":     strconv.FormatInt(amount, 10),
+	})
+}
+
+func emitAllocationTransferred(sa *StorageAllocation, prevOwner string,
+	balances chainstate.StateContextI) {
+
+	balances.EmitEvent(EventAllocationTransferred, map[string]string{
+		"allocation": sa.ID,
+		"from":       prevOwner,
+		"to":         sa.Owner,
+	})
+}
+
+func emitBlobberReplaced(sa *StorageAllocation, blobberID, newBlobberID string,
+	balances chainstate.StateContextI) {
+
+	balances.EmitEvent(EventBlobberReplaced, map[string]string{
+		"allocation":  sa.ID,
+		"blobber":     blobberID,
+		"new_blobber": newBlobberID,
+	})
+}
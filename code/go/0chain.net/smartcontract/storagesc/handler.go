@@ -3,7 +3,9 @@ package storagesc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	"0chain.net/core/logging"
@@ -14,7 +16,8 @@ import (
 	"0chain.net/core/util"
 )
 
-// GetBlobberHandler returns Blobber object from its individual stored value.
+// GetBlobberHandler returns Blobber object from its individual stored value
+// with the blobber's reputation.
 func (ssc *StorageSmartContract) GetBlobberHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (
 	resp interface{}, err error) {
@@ -24,14 +27,36 @@ func (ssc *StorageSmartContract) GetBlobberHandler(ctx context.Context,
 		return nil, errors.New("missing 'blobber_id' URL query parameter")
 	}
 
-	return ssc.getBlobber(blobberID, balances)
+	var bwr blobberWithReputation
+	if bwr.StorageNode, err = ssc.getBlobber(blobberID, balances); err != nil {
+		return
+	}
+	bwr.Reputation, err = ssc.getBlobberReputation(blobberID, balances)
+	if err != nil {
+		return
+	}
+
+	// decay the history to now
+	var conf *scConfig
+	if conf, err = ssc.getConfig(balances, true); err != nil {
+		return nil, fmt.Errorf("can't get SC configurations: %v", err)
+	}
+	bwr.Reputation.decay(common.Timestamp(time.Now().Unix()),
+		conf.reputation().HalfLife)
+
+	return &bwr, nil
 }
 
 // GetBlobbersHandler returns list of all blobbers alive (e.g. excluding
-// blobbers with zero capacity).
+// blobbers with zero capacity). If any of pagination or filter parameters
+// given, then it returns the requested page of the filtered blobbers.
 func (ssc *StorageSmartContract) GetBlobbersHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (interface{}, error) {
 
+	if isListingRequest(params, blobbersFilterParams...) {
+		return ssc.getBlobbersPage(params, balances)
+	}
+
 	blobbers, err := ssc.getBlobbersList(balances)
 	if err != nil {
 		return nil, err
@@ -39,9 +64,17 @@ func (ssc *StorageSmartContract) GetBlobbersHandler(ctx context.Context,
 	return blobbers, nil
 }
 
+// GetAllocationsHandler returns all allocations of a client. If any of
+// pagination or filter parameters given, then it returns the requested page
+// of the filtered allocations of the client, or of all allocations if the
+// client is not given.
 func (ssc *StorageSmartContract) GetAllocationsHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (interface{}, error) {
 
+	if isListingRequest(params, allocationsFilterParams...) {
+		return ssc.getAllocationsPage(params, balances)
+	}
+
 	clientID := params.Get("client")
 	allocations, err := ssc.getAllocationsList(clientID, balances)
 	if err != nil {
@@ -62,6 +95,56 @@ func (ssc *StorageSmartContract) GetAllocationsHandler(ctx context.Context,
 	return result, nil
 }
 
+// GetAllocationUpdateQuoteHandler returns expected changes of an allocation
+// updated to given new size and (or) new expiration by update_allocation_request
+// transaction: new weighted average terms of the blobbers, min lock demand
+// changes, tokens required in write pool and tokens moved to or from
+// challenge pool.
+func (ssc *StorageSmartContract) GetAllocationUpdateQuoteHandler(
+	ctx context.Context, params url.Values, balances cstate.StateContextI) (
+	interface{}, error) {
+
+	var (
+		now     = common.Timestamp(time.Now().Unix())
+		request updateAllocationRequest
+		alloc   *StorageAllocation
+		err     error
+	)
+
+	if request.ID = params.Get("allocation_id"); request.ID == "" {
+		return nil, common.NewError("allocation_update_quote_failed",
+			"missing 'allocation_id' URL query parameter")
+	}
+	if alloc, err = ssc.getAllocation(request.ID, balances); err != nil {
+		return nil, common.NewErrorf("allocation_update_quote_failed",
+			"can't get allocation: %v", err)
+	}
+
+	if size := params.Get("size"); size != "" {
+		var ns int64
+		if ns, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return nil, common.NewErrorf("allocation_update_quote_failed",
+				"invalid 'size' URL query parameter: %v", err)
+		}
+		request.Size = ns - alloc.Size
+	}
+	if exp := params.Get("expiration_date"); exp != "" {
+		var ne int64
+		if ne, err = strconv.ParseInt(exp, 10, 64); err != nil {
+			return nil, common.NewErrorf("allocation_update_quote_failed",
+				"invalid 'expiration_date' URL query parameter: %v", err)
+		}
+		request.Expiration = common.Timestamp(ne) - alloc.Expiration
+	}
+
+	var quote *allocationUpdateQuote
+	if quote, err = ssc.allocationUpdateQuote(&request, now, balances); err != nil {
+		return nil, common.NewError("allocation_update_quote_failed",
+			err.Error())
+	}
+	return quote, nil
+}
+
 func (ssc *StorageSmartContract) GetAllocationMinLockHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (interface{}, error) {
 
@@ -103,7 +186,8 @@ func (ssc *StorageSmartContract) GetAllocationMinLockHandler(ctx context.Context
 		// filtered list
 		list = sa.filterBlobbers(allBlobbersList.Nodes.copy(), creationDate,
 			bsize, filterHealthyBlobbers(creationDate),
-			ssc.filterBlobbersByFreeSpace(creationDate, bsize, balances))
+			ssc.filterBlobbersByFreeSpace(creationDate, bsize, balances),
+			ssc.filterBlobbersByReputation(conf, creationDate, balances))
 	)
 
 	if len(list) < size {
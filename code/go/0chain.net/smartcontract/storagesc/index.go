@@ -0,0 +1,246 @@
+package storagesc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+// indexPageSize is max number of IDs in a page of an index
+const indexPageSize = 100
+
+// names of the indexes
+const (
+	blobbersIndexName    = "blobbers"
+	allocationsIndexName = "allocations"
+)
+
+// ownerAllocationsIndexName is name of the index of allocations of a client,
+// including allocations transferred to other clients later
+func ownerAllocationsIndexName(owner string) string {
+	return allocationsIndexName + ":" + owner
+}
+
+func idIndexKey(scKey, name string) datastore.Key {
+	return datastore.Key(scKey + ":index:" + name)
+}
+
+func idIndexPageKey(scKey, name string, page int) datastore.Key {
+	return datastore.Key(scKey + ":index:" + name + ":" + strconv.Itoa(page))
+}
+
+// idIndexPage is a page of an index of IDs; every page is stored in MPT as
+// its own node, thus a part of an index is loaded without loading all of it
+type idIndexPage struct {
+	IDs []string `json:"ids"`
+}
+
+// Encode implements util.Serializable interface.
+func (ip *idIndexPage) Encode() []byte {
+	var b, err = json.Marshal(ip)
+	if err != nil {
+		panic(err) // must never happens
+	}
+	return b
+}
+
+// Decode implements util.Serializable interface.
+func (ip *idIndexPage) Decode(p []byte) error {
+	return json.Unmarshal(p, ip)
+}
+
+// idIndex is append only list of IDs stored by pages in MPT, an ID is
+// added to an index once, when the indexed item is created
+type idIndex struct {
+	Size int `json:"size"` // total number of IDs
+
+	name   string // name of the index
+	scKey  string // key of the SC
+	exists bool   // stored in MPT
+}
+
+// Encode implements util.Serializable interface.
+func (idx *idIndex) Encode() []byte {
+	var b, err = json.Marshal(idx)
+	if err != nil {
+		panic(err) // must never happens
+	}
+	return b
+}
+
+// Decode implements util.Serializable interface.
+func (idx *idIndex) Decode(p []byte) error {
+	return json.Unmarshal(p, idx)
+}
+
+func (sc *StorageSmartContract) getIDIndex(name string,
+	balances cstate.StateContextI) (idx *idIndex, err error) {
+
+	idx = &idIndex{name: name, scKey: sc.ID}
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(idIndexKey(sc.ID, name))
+	if err == util.ErrValueNotPresent {
+		return idx, nil // empty
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = idx.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("decoding %s index: %v", name, err)
+	}
+	idx.exists = true
+	return
+}
+
+// pages number
+func (idx *idIndex) pages() int {
+	return (idx.Size + indexPageSize - 1) / indexPageSize
+}
+
+func (idx *idIndex) getPage(page int, balances cstate.StateContextI) (
+	ip *idIndexPage, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(idIndexPageKey(idx.scKey, idx.name, page))
+	if err != nil {
+		return nil, fmt.Errorf("getting page %d of %s index: %v", page,
+			idx.name, err)
+	}
+	ip = new(idIndexPage)
+	if err = ip.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("decoding page %d of %s index: %v", page,
+			idx.name, err)
+	}
+	return
+}
+
+// add IDs to the end of the index and save it
+func (idx *idIndex) add(balances cstate.StateContextI, ids ...string) (
+	err error) {
+
+	var (
+		page = idx.Size / indexPageSize
+		ip   = new(idIndexPage)
+	)
+	if idx.Size%indexPageSize != 0 {
+		if ip, err = idx.getPage(page, balances); err != nil {
+			return
+		}
+	}
+	for _, id := range ids {
+		ip.IDs = append(ip.IDs, id)
+		idx.Size++
+		if len(ip.IDs) < indexPageSize {
+			continue
+		}
+		_, err = balances.InsertTrieNode(
+			idIndexPageKey(idx.scKey, idx.name, page), ip)
+		if err != nil {
+			return fmt.Errorf("saving page %d of %s index: %v", page,
+				idx.name, err)
+		}
+		page, ip = page+1, new(idIndexPage)
+	}
+	if len(ip.IDs) > 0 {
+		_, err = balances.InsertTrieNode(
+			idIndexPageKey(idx.scKey, idx.name, page), ip)
+		if err != nil {
+			return fmt.Errorf("saving page %d of %s index: %v", page,
+				idx.name, err)
+		}
+	}
+	if _, err = balances.InsertTrieNode(idIndexKey(idx.scKey, idx.name),
+		idx); err != nil {
+		return fmt.Errorf("saving %s index: %v", idx.name, err)
+	}
+	idx.exists = true
+	return
+}
+
+// walk over IDs of the index starting from given offset loading pages
+// by demand until the given function returns true
+func (idx *idIndex) walk(offset int, balances cstate.StateContextI,
+	fn func(id string) (stop bool, err error)) (err error) {
+
+	if offset < 0 {
+		offset = 0
+	}
+	for page := offset / indexPageSize; page < idx.pages(); page++ {
+		var ip *idIndexPage
+		if ip, err = idx.getPage(page, balances); err != nil {
+			return
+		}
+		var ids = ip.IDs
+		if page == offset/indexPageSize {
+			ids = ids[minInt(offset%indexPageSize, len(ids)):]
+		}
+		for _, id := range ids {
+			var stop bool
+			if stop, err = fn(id); err != nil || stop {
+				return
+			}
+		}
+	}
+	return
+}
+
+// walkList walks over a list of IDs starting from given offset the same way
+// the idIndex.walk does; it's used for a list which index doesn't exist yet,
+// because no item was created since the indexes introduced
+func walkList(ids []string, offset int,
+	fn func(id string) (stop bool, err error)) (err error) {
+
+	if offset < 0 {
+		offset = 0
+	}
+	for _, id := range ids[minInt(offset, len(ids)):] {
+		var stop bool
+		if stop, err = fn(id); err != nil || stop {
+			return
+		}
+	}
+	return
+}
+
+// has is true if the index has the ID; it loads all pages of the index
+func (idx *idIndex) has(id string, balances cstate.StateContextI) (
+	found bool, err error) {
+
+	err = idx.walk(0, balances, func(iid string) (bool, error) {
+		found = iid == id
+		return found, nil
+	})
+	return
+}
+
+// addToIndex adds a new item to the index; an index created after the
+// items it indexes is filled by the seed list first; the seed list is the
+// list of the indexed items, including the new one
+func (sc *StorageSmartContract) addToIndex(name, id string, seed []string,
+	balances cstate.StateContextI) (err error) {
+
+	var idx *idIndex
+	if idx, err = sc.getIDIndex(name, balances); err != nil {
+		return
+	}
+	if !idx.exists && len(seed) > 0 {
+		var found bool
+		for _, sid := range seed {
+			if sid == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			seed = append(append(make([]string, 0, len(seed)+1), seed...),
+				id)
+		}
+		return idx.add(balances, seed...)
+	}
+	return idx.add(balances, id)
+}
@@ -0,0 +1,348 @@
+package storagesc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+)
+
+// default and max number of items of a listing page, and max number of
+// items loaded to fill a page
+const (
+	defaultListingLimit = 20
+	maxListingLimit     = 100
+	maxListingScan      = 10 * maxListingLimit
+)
+
+// URL query parameters of blobbers and allocations listing filters
+var (
+	blobbersFilterParams = []string{"capacity_free", "write_price_min",
+		"write_price_max", "healthy_since", "all"}
+	allocationsFilterParams = []string{"owner", "expired", "finalized"}
+)
+
+// isListingRequest is true if any of pagination or given filter
+// parameters is in the query
+func isListingRequest(params url.Values, filters ...string) bool {
+	for _, key := range append([]string{"offset", "limit"}, filters...) {
+		if _, ok := params[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// listingPage is pagination parameters of a listing; the offset is offset
+// in the listed index, the next offset is offset of the next page, it's
+// omitted at the end of the index
+type listingPage struct {
+	Offset     int `json:"offset"`
+	Limit      int `json:"limit"`
+	NextOffset int `json:"next_offset,omitempty"`
+}
+
+func getIntParam(params url.Values, key string, def int64) (int64, error) {
+	var value = params.Get(key)
+	if value == "" {
+		return def, nil
+	}
+	var i, err = strconv.ParseInt(value, 10, 64)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid '%s' URL query parameter", key)
+	}
+	return i, nil
+}
+
+// getBoolParam returns nil if the parameter is not given
+func getBoolParam(params url.Values, key string) (*bool, error) {
+	var value = params.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	var b, err = strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s' URL query parameter", key)
+	}
+	return &b, nil
+}
+
+func getListingPage(params url.Values) (lp listingPage, err error) {
+	var offset, limit int64
+	if offset, err = getIntParam(params, "offset", 0); err != nil {
+		return
+	}
+	limit, err = getIntParam(params, "limit", defaultListingLimit)
+	if err != nil {
+		return
+	}
+	if limit == 0 || limit > maxListingLimit {
+		return lp, fmt.Errorf("'limit' should be in [1; %d] range",
+			maxListingLimit)
+	}
+	lp.Offset, lp.Limit = int(offset), int(limit)
+	return
+}
+
+// collect items of the page from a filtered list starting from the offset,
+// the skip function should return true for items filtered out; the filters
+// aren't backed by indexes (except allocations owner), and no more than
+// maxListingScan items are checked out by a request, thus a page of a sparse
+// filter can be short, or even empty, while the next offset is set; a client
+// should follow the next offset until it's omitted to get all items
+func (lp *listingPage) collector(skip func(id string) (bool, error),
+	add func(id string)) func(id string) (stop bool, err error) {
+
+	var scanned, added int
+	return func(id string) (stop bool, err error) {
+		scanned++
+		var kick bool
+		if kick, err = skip(id); err != nil {
+			return
+		}
+		if !kick {
+			add(id)
+			added++
+		}
+		if added >= lp.Limit || scanned >= maxListingScan {
+			lp.NextOffset = lp.Offset + scanned
+			return true, nil
+		}
+		return
+	}
+}
+
+//
+// blobbers
+//
+
+// blobbersFilter of blobbers listing
+type blobbersFilter struct {
+	capacityFree int64            // free capacity, at least
+	writePrice   PriceRange       // write price range
+	healthySince common.Timestamp // last health check, not before
+	all          bool             // including removed and shut down
+}
+
+func newBlobbersFilter(params url.Values) (bf *blobbersFilter, err error) {
+	bf = new(blobbersFilter)
+	if bf.capacityFree, err = getIntParam(params, "capacity_free", 0); err != nil {
+		return
+	}
+	var wpmin, wpmax, since int64
+	if wpmin, err = getIntParam(params, "write_price_min", 0); err != nil {
+		return
+	}
+	wpmax, err = getIntParam(params, "write_price_max", math.MaxInt64)
+	if err != nil {
+		return
+	}
+	bf.writePrice = PriceRange{Min: state.Balance(wpmin),
+		Max: state.Balance(wpmax)}
+	if !bf.writePrice.isValid() {
+		return nil, errors.New("invalid write price range")
+	}
+	if since, err = getIntParam(params, "healthy_since", 0); err != nil {
+		return
+	}
+	bf.healthySince = common.Timestamp(since)
+	var all *bool
+	if all, err = getBoolParam(params, "all"); err != nil {
+		return
+	}
+	bf.all = all != nil && *all
+	return
+}
+
+func (bf *blobbersFilter) match(b *StorageNode) bool {
+	if !bf.all && (b.Capacity == 0 || b.Status == blobberStatusShutdown) {
+		return false
+	}
+	return b.Capacity-b.Used >= bf.capacityFree &&
+		bf.writePrice.isMatch(b.Terms.WritePrice) &&
+		b.LastHealthCheck >= bf.healthySince
+}
+
+// blobbersPage is a page of blobbers listing
+type blobbersPage struct {
+	Nodes []*StorageNode `json:"Nodes"`
+	listingPage
+}
+
+// getBlobbersPage walks over the blobbers index loading blobbers until the
+// page is full
+func (ssc *StorageSmartContract) getBlobbersPage(params url.Values,
+	balances cstate.StateContextI) (page *blobbersPage, err error) {
+
+	var lp listingPage
+	if lp, err = getListingPage(params); err != nil {
+		return
+	}
+	var bf *blobbersFilter
+	if bf, err = newBlobbersFilter(params); err != nil {
+		return
+	}
+
+	var idx *idIndex
+	if idx, err = ssc.getIDIndex(blobbersIndexName, balances); err != nil {
+		return nil, fmt.Errorf("getting blobbers index: %v", err)
+	}
+
+	page = &blobbersPage{Nodes: make([]*StorageNode, 0), listingPage: lp}
+
+	var (
+		blob *StorageNode
+		skip = func(id string) (kick bool, err error) {
+			if blob, err = ssc.getBlobber(id, balances); err != nil {
+				return false, fmt.Errorf("getting blobber %s: %v", id, err)
+			}
+			return !bf.match(blob), nil
+		}
+		add     = func(string) { page.Nodes = append(page.Nodes, blob) }
+		collect = page.collector(skip, add)
+	)
+
+	// no blobber added since the indexes introduced
+	if !idx.exists {
+		var all *StorageNodes
+		if all, err = ssc.getBlobbersList(balances); err != nil {
+			return nil, err
+		}
+		var ids = make([]string, 0, len(all.Nodes))
+		for _, b := range all.Nodes {
+			ids = append(ids, b.ID)
+		}
+		if err = walkList(ids, lp.Offset, collect); err != nil {
+			return nil, err
+		}
+		return
+	}
+
+	if err = idx.walk(lp.Offset, balances, collect); err != nil {
+		return nil, err
+	}
+	return
+}
+
+//
+// allocations
+//
+
+// allocationsFilter of allocations listing
+type allocationsFilter struct {
+	owner     string
+	expired   *bool
+	finalized *bool
+	now       common.Timestamp
+}
+
+func newAllocationsFilter(params url.Values) (af *allocationsFilter,
+	err error) {
+
+	af = new(allocationsFilter)
+	if af.owner = params.Get("owner"); af.owner == "" {
+		af.owner = params.Get("client")
+	}
+	if af.expired, err = getBoolParam(params, "expired"); err != nil {
+		return
+	}
+	if af.finalized, err = getBoolParam(params, "finalized"); err != nil {
+		return
+	}
+	af.now = common.Timestamp(time.Now().Unix())
+	return
+}
+
+func (af *allocationsFilter) match(alloc *StorageAllocation) bool {
+	if af.owner != "" && alloc.Owner != af.owner {
+		return false // transferred
+	}
+	if af.expired != nil && *af.expired != (alloc.Expiration < af.now) {
+		return false
+	}
+	if af.finalized != nil && *af.finalized != alloc.Finalized {
+		return false
+	}
+	return true
+}
+
+// allocationsPage is a page of allocations listing
+type allocationsPage struct {
+	Allocations []*StorageAllocation `json:"allocations"`
+	listingPage
+}
+
+// getAllocationsPage walks over allocations index of the owner, or over
+// all allocations index, loading allocations until the page is full
+func (ssc *StorageSmartContract) getAllocationsPage(params url.Values,
+	balances cstate.StateContextI) (page *allocationsPage, err error) {
+
+	var lp listingPage
+	if lp, err = getListingPage(params); err != nil {
+		return
+	}
+	var af *allocationsFilter
+	if af, err = newAllocationsFilter(params); err != nil {
+		return
+	}
+
+	page = &allocationsPage{
+		Allocations: make([]*StorageAllocation, 0),
+		listingPage: lp,
+	}
+
+	var (
+		alloc *StorageAllocation
+		skip  = func(id string) (kick bool, err error) {
+			alloc, err = ssc.getAllocation(id, balances)
+			if err == util.ErrValueNotPresent {
+				return true, nil // invalid list, skip
+			}
+			if err != nil {
+				return false, fmt.Errorf("getting allocation %s: %v", id, err)
+			}
+			return !af.match(alloc), nil
+		}
+		add     = func(string) { page.Allocations = append(page.Allocations, alloc) }
+		collect = page.collector(skip, add)
+		name    = allocationsIndexName
+	)
+
+	if af.owner != "" {
+		name = ownerAllocationsIndexName(af.owner)
+	}
+
+	var idx *idIndex
+	if idx, err = ssc.getIDIndex(name, balances); err != nil {
+		return nil, fmt.Errorf("getting allocations index: %v", err)
+	}
+
+	// no allocation created since the indexes introduced (by the owner)
+	if !idx.exists {
+		var list *Allocations
+		if af.owner != "" {
+			list, err = ssc.getAllocationsList(af.owner, balances)
+		} else {
+			list, err = ssc.getAllAllocationsList(balances)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = walkList(list.List, lp.Offset, collect); err != nil {
+			return nil, err
+		}
+		return
+	}
+
+	if err = idx.walk(lp.Offset, balances, collect); err != nil {
+		return nil, err
+	}
+	return
+}
@@ -0,0 +1,228 @@
+package storagesc
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_idIndex(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		ids      []string
+	)
+
+	for i := 0; i < 2*indexPageSize+50; i++ {
+		ids = append(ids, "id"+strconv.Itoa(i))
+	}
+
+	// seeded on first use
+	require.NoError(t, ssc.addToIndex("test", ids[10], ids[:10], balances))
+	for _, id := range ids[11:] {
+		require.NoError(t, ssc.addToIndex("test", id, nil, balances))
+	}
+
+	var idx, err = ssc.getIDIndex("test", balances)
+	require.NoError(t, err)
+	assert.Equal(t, len(ids), idx.Size)
+	assert.Equal(t, 3, idx.pages())
+
+	var got []string
+	err = idx.walk(indexPageSize+90, balances, func(id string) (bool, error) {
+		got = append(got, id)
+		return len(got) == 20, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ids[indexPageSize+90:indexPageSize+110], got)
+}
+
+func TestStorageSmartContract_getBlobbersPage(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		tp       = int64(0)
+		blobs    []*Client
+	)
+
+	setConfig(t, balances)
+
+	for i := 0; i < 5; i++ {
+		var terms = avgTerms
+		terms.WritePrice = avgTerms.WritePrice * state.Balance(i+1) / 5
+		tp++
+		blobs = append(blobs, addBlobber(t, ssc, 2*GB, tp, terms, 50*x10,
+			balances))
+	}
+
+	var next int
+	var list = func(query string) (ids []string) {
+		var params, err = url.ParseQuery(query)
+		require.NoError(t, err)
+		var resp interface{}
+		resp, err = ssc.GetBlobbersHandler(nil, params, balances)
+		require.NoError(t, err)
+		for _, b := range resp.(*blobbersPage).Nodes {
+			ids = append(ids, b.ID)
+		}
+		next = resp.(*blobbersPage).NextOffset
+		return
+	}
+
+	assert.Equal(t, []string{blobs[1].id, blobs[2].id},
+		list("offset=1&limit=2"))
+	assert.Equal(t, 3, next)
+	assert.Equal(t, []string{blobs[2].id, blobs[3].id},
+		list("write_price_min="+strconv.Itoa(int(avgTerms.WritePrice/2))+
+			"&write_price_max="+strconv.Itoa(int(avgTerms.WritePrice*4/5))))
+	assert.Zero(t, next)
+
+	// the offset is offset in the index, filtered pages are continued
+	// from the next offset
+	var minPrice = "write_price_min=" +
+		strconv.Itoa(int(avgTerms.WritePrice/2))
+	assert.Equal(t, []string{blobs[2].id}, list(minPrice+"&limit=1"))
+	assert.Equal(t, 3, next)
+	assert.Equal(t, []string{blobs[3].id},
+		list(minPrice+"&offset="+strconv.Itoa(next)+"&limit=1"))
+	assert.Equal(t, 4, next)
+	assert.Equal(t, []string{blobs[3].id, blobs[4].id},
+		list("healthy_since=4"))
+	assert.Len(t, list("capacity_free="+strconv.Itoa(2*GB+1)), 0)
+
+	// removed blobber is listed only with 'all' flag
+	var b, err = ssc.getBlobber(blobs[0].id, balances)
+	require.NoError(t, err)
+	b.Capacity = 0
+	tp++
+	_, err = updateBlobber(t, b, 0, tp, ssc, balances)
+	require.NoError(t, err)
+
+	assert.Equal(t, blobs[1].id, list("limit=1")[0])
+	assert.Equal(t, blobs[0].id, list("limit=1&all=true")[0])
+
+	var params = url.Values{"limit": []string{"1000"}}
+	_, err = ssc.GetBlobbersHandler(nil, params, balances)
+	require.Error(t, err)
+
+	// no blobber added since the indexes introduced, the blobbers list used
+	_, err = balances.DeleteTrieNode(idIndexKey(ssc.ID, blobbersIndexName))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{blobs[1].id, blobs[2].id, blobs[3].id,
+		blobs[4].id}, list("offset=0"))
+	assert.Len(t, list("limit=1"), 1)
+	assert.Equal(t, 1, next)
+}
+
+func Test_listingPage_collector(t *testing.T) {
+	var (
+		lp    = listingPage{Offset: 10, Limit: 5}
+		added int
+		skip  = func(id string) (bool, error) { return id != "match", nil }
+		add   = func(string) { added++ }
+		stop  bool
+		err   error
+	)
+
+	// a sparse filter stops after maxListingScan items checked out
+	var collect = lp.collector(skip, add)
+	for i := 0; i < maxListingScan && !stop; i++ {
+		stop, err = collect("other")
+		require.NoError(t, err)
+	}
+	assert.True(t, stop)
+	assert.Zero(t, added)
+	assert.Equal(t, 10+maxListingScan, lp.NextOffset)
+
+	// a full page
+	lp.NextOffset, stop = 0, false
+	collect = lp.collector(skip, add)
+	for i := 0; !stop; i++ {
+		var id = "other"
+		if i%2 == 0 {
+			id = "match"
+		}
+		stop, err = collect(id)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 5, added)
+	assert.Equal(t, 10+9, lp.NextOffset)
+}
+
+func TestStorageSmartContract_getAllocationsPage(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		alice    = newClient(100*x10, balances)
+		bob      = newClient(100*x10, balances)
+		tp       = int64(100)
+		exp      = int64(toSeconds(time.Hour))
+		aliceIDs []string
+	)
+
+	var allocID, _ = addAllocation(t, ssc, alice, tp, exp, 0, balances)
+	aliceIDs = append(aliceIDs, allocID)
+	allocID, _ = addAllocation(t, ssc, bob, tp, exp, 0, balances)
+	var bobID = allocID
+	allocID, _ = addAllocation(t, ssc, alice, tp, exp, 0, balances)
+	aliceIDs = append(aliceIDs, allocID)
+
+	// the expired filter uses wall clock time; finalize the second
+	// allocation of alice
+	var future = common.Timestamp(time.Now().Add(time.Hour).Unix())
+	for _, id := range []string{aliceIDs[0], bobID, aliceIDs[1]} {
+		var alloc, err = ssc.getAllocation(id, balances)
+		require.NoError(t, err)
+		alloc.Expiration = future
+		if id == aliceIDs[1] {
+			alloc.Finalized, alloc.Expiration = true, 1
+		}
+		_, err = balances.InsertTrieNode(alloc.GetKey(ssc.ID), alloc)
+		require.NoError(t, err)
+	}
+
+	var list = func(query string) (ids []string) {
+		var params, err = url.ParseQuery(query)
+		require.NoError(t, err)
+		var resp interface{}
+		resp, err = ssc.GetAllocationsHandler(nil, params, balances)
+		require.NoError(t, err)
+		for _, a := range resp.(*allocationsPage).Allocations {
+			ids = append(ids, a.ID)
+		}
+		return
+	}
+
+	assert.Equal(t, []string{aliceIDs[0], bobID, aliceIDs[1]},
+		list("offset=0"))
+	assert.Equal(t, []string{bobID}, list("offset=1&limit=1"))
+	assert.ElementsMatch(t, aliceIDs, list("owner="+alice.id))
+	assert.Equal(t, []string{aliceIDs[1]},
+		list("owner="+alice.id+"&finalized=true"))
+	assert.Equal(t, []string{aliceIDs[0], bobID}, list("expired=false"))
+	assert.Equal(t, []string{bobID}, list("expired=false&offset=1"))
+
+	// no allocation created since the indexes introduced, the allocations
+	// lists used
+	for _, name := range []string{allocationsIndexName,
+		ownerAllocationsIndexName(alice.id)} {
+
+		var _, err = balances.DeleteTrieNode(idIndexKey(ssc.ID, name))
+		require.NoError(t, err)
+	}
+	assert.ElementsMatch(t, []string{aliceIDs[0], bobID, aliceIDs[1]},
+		list("offset=0"))
+	assert.ElementsMatch(t, aliceIDs, list("owner="+alice.id))
+	assert.Equal(t, []string{aliceIDs[1]},
+		list("owner="+alice.id+"&finalized=true"))
+}
@@ -160,6 +160,10 @@ type ValidationNode struct {
 	BaseURL           string            `json:"url"`
 	PublicKey         string            `json:"-"`
 	StakePoolSettings stakePoolSettings `json:"stake_pool_settings"`
+	LastHealthCheck   common.Timestamp  `json:"last_health_check"`
+	// IsShutdown is set when the validator has been shut down and removed
+	// from the all validators list.
+	IsShutdown bool `json:"is_shutdown,omitempty"`
 }
 
 func (sn *ValidationNode) GetKey(globalKey string) datastore.Key {
@@ -189,6 +193,9 @@ func (sn *ValidationNode) GetHashBytes() []byte {
 
 type ValidatorNodes struct {
 	Nodes []*ValidationNode
+	// HealthChecksSince is time of the first challenges generation since
+	// the health checks of validators introduced.
+	HealthChecksSince common.Timestamp `json:"health_checks_since,omitempty"`
 }
 
 func (sn *ValidatorNodes) Encode() []byte {
@@ -280,6 +287,15 @@ type StorageNode struct {
 	PublicKey       string           `json:"-"`
 	// StakePoolSettings used initially to create and setup stake pool.
 	StakePoolSettings stakePoolSettings `json:"stake_pool_settings"`
+	// Status of the blobber: active, draining or shutdown. A draining
+	// blobber serves its allocations, but doesn't accept new ones. Empty
+	// status of blobbers registered before is active.
+	Status string `json:"status,omitempty"`
+}
+
+// isActive blobber accepts new allocations
+func (sn *StorageNode) isActive() bool {
+	return sn.Status == "" || sn.Status == blobberStatusActive
 }
 
 // validate the blobber configurations
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
 	cstate "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/state"
@@ -15,6 +16,145 @@ import (
 	"0chain.net/core/util"
 )
 
+//
+// readers of a read pool lock
+//
+
+// readerLockRequest is a reader allowed to spend tokens of a read pool
+// lock, up to the limit, and until the lock expires, or for given duration
+type readerLockRequest struct {
+	ClientID datastore.Key `json:"client_id"`
+	Limit    state.Balance `json:"limit"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+func (rlr *readerLockRequest) validate(lockDuration time.Duration) error {
+	if rlr.ClientID == "" {
+		return errors.New("missing reader client_id")
+	}
+	if rlr.Limit <= 0 {
+		return fmt.Errorf("invalid limit of reader %s: %v", rlr.ClientID,
+			rlr.Limit)
+	}
+	if rlr.Duration < 0 || rlr.Duration > lockDuration {
+		return fmt.Errorf("duration of reader %s (%s) is not in [0; %s] range",
+			rlr.ClientID, rlr.Duration, lockDuration)
+	}
+	return nil
+}
+
+// readerLimit is spending limit of a reader of a read pool lock
+type readerLimit struct {
+	ClientID datastore.Key    `json:"client_id"`
+	Limit    state.Balance    `json:"limit"`
+	Spent    state.Balance    `json:"spent"`
+	ExpireAt common.Timestamp `json:"expire_at"` // inclusive
+}
+
+// left to spend
+func (rl *readerLimit) left(now common.Timestamp) state.Balance {
+	if rl.ExpireAt < now || rl.Spent >= rl.Limit {
+		return 0
+	}
+	return rl.Limit - rl.Spent
+}
+
+type readerLimitStat struct {
+	ClientID datastore.Key    `json:"client_id"`
+	Limit    state.Balance    `json:"limit"`
+	Spent    state.Balance    `json:"spent"`
+	Left     state.Balance    `json:"left"`
+	ExpireAt common.Timestamp `json:"expire_at"`
+}
+
+func (rl *readerLimit) stat(lockExpireAt, now common.Timestamp) (
+	stat readerLimitStat) {
+
+	stat.ClientID = rl.ClientID
+	stat.Limit = rl.Limit
+	stat.Spent = rl.Spent
+	stat.ExpireAt = rl.ExpireAt
+	if lockExpireAt >= now {
+		stat.Left = rl.left(now)
+	}
+	return
+}
+
+// readerLimits of a read pool lock
+type readerLimits []*readerLimit
+
+func newReaderLimits(readers []*readerLockRequest,
+	lockDuration time.Duration, now common.Timestamp) (
+	rls readerLimits, err error) {
+
+	var seen = make(map[datastore.Key]bool, len(readers))
+	for _, rlr := range readers {
+		if rlr == nil {
+			return nil, errors.New("invalid reader")
+		}
+		if err = rlr.validate(lockDuration); err != nil {
+			return
+		}
+		if seen[rlr.ClientID] {
+			return nil, fmt.Errorf("duplicate reader %s", rlr.ClientID)
+		}
+		seen[rlr.ClientID] = true
+		var duration = rlr.Duration
+		if duration == 0 {
+			duration = lockDuration
+		}
+		rls = append(rls, &readerLimit{
+			ClientID: rlr.ClientID,
+			Limit:    rlr.Limit,
+			ExpireAt: now + toSeconds(duration),
+		})
+	}
+	return
+}
+
+func (rls readerLimits) get(clientID datastore.Key) (rl *readerLimit,
+	ok bool) {
+
+	for _, rl = range rls {
+		if rl.ClientID == clientID {
+			return rl, true
+		}
+	}
+	return nil, false
+}
+
+// canSpend returns value can be spent by the reader, for a lock without
+// readers and for the owner of the read pool (the payer) the value is
+// unlimited
+func (rls readerLimits) canSpend(clientID, payerID datastore.Key,
+	value state.Balance, now common.Timestamp) state.Balance {
+
+	if len(rls) == 0 || clientID == payerID {
+		return value
+	}
+	var rl, ok = rls.get(clientID)
+	if !ok {
+		return 0
+	}
+	if left := rl.left(now); left < value {
+		return left
+	}
+	return value
+}
+
+// spend by the reader, no-op for a lock without readers and for the
+// owner of the read pool (the payer)
+func (rls readerLimits) spend(clientID, payerID datastore.Key,
+	value state.Balance) {
+
+	if clientID == payerID {
+		return
+	}
+	if rl, ok := rls.get(clientID); ok {
+		rl.Spent += value
+	}
+}
+
 //
 // client read pool (consist of allocation pools)
 //
@@ -147,8 +287,11 @@ func toJson(val interface{}) string {
 	return string(b)
 }
 
-func (rp *readPool) moveToBlobber(sscKey, allocID, blobID string,
-	sp *stakePool, now common.Timestamp, value state.Balance,
+// moveToBlobber moves tokens of the allocation read pool locks can be
+// spent by given reader to the blobber; the payer, that is the owner of
+// the read pool, can spend tokens of all the locks
+func (rp *readPool) moveToBlobber(sscKey, allocID, blobID, readerID,
+	payerID string, sp *stakePool, now common.Timestamp, value state.Balance,
 	balances cstate.StateContextI) (resp string, err error) {
 
 	var cut = rp.blobberCut(allocID, blobID, now)
@@ -172,13 +315,16 @@ func (rp *readPool) moveToBlobber(sscKey, allocID, blobID string,
 		}
 		var (
 			bp   = ap.Blobbers[bi]
-			move state.Balance
+			move = ap.Readers.canSpend(readerID, payerID, value, now)
 		)
-		if value >= bp.Balance {
-			move, bp.Balance = bp.Balance, 0
-		} else {
-			move, bp.Balance = value, bp.Balance-value
+		if move == 0 {
+			continue // not allowed, or the reader limit reached
 		}
+		if move > bp.Balance {
+			move = bp.Balance
+		}
+		bp.Balance -= move
+		ap.Readers.spend(readerID, payerID, move)
 
 		err = rp.movePartToBlobber(sscKey, ap, sp, move, balances)
 		if err != nil {
@@ -202,7 +348,8 @@ func (rp *readPool) moveToBlobber(sscKey, allocID, blobID string,
 
 	if value != 0 {
 		return "", fmt.Errorf("not enough tokens in read pool for "+
-			"allocation: %s, blobber: %s", allocID, blobID)
+			"allocation: %s, blobber: %s, reader: %s", allocID, blobID,
+			readerID)
 	}
 
 	// remove empty allocation pools
@@ -365,6 +512,12 @@ func (ssc *StorageSmartContract) readPoolLock(t *transaction.Transaction,
 				lr.Duration.String(), conf.MaxLockPeriod.String()))
 	}
 
+	var readers readerLimits
+	readers, err = newReaderLimits(lr.Readers, lr.Duration, t.CreationDate)
+	if err != nil {
+		return "", common.NewError("read_pool_lock_failed", err.Error())
+	}
+
 	// check client balance
 	if err = checkFill(t, balances); err != nil {
 		return "", common.NewError("read_pool_lock_failed", err.Error())
@@ -426,6 +579,7 @@ func (ssc *StorageSmartContract) readPoolLock(t *transaction.Transaction,
 	ap.AllocationID = lr.AllocationID
 	ap.ExpireAt = t.CreationDate + toSeconds(lr.Duration)
 	ap.Blobbers = bps
+	ap.Readers = readers
 
 	// add and save
 
@@ -486,7 +640,8 @@ func (ssc *StorageSmartContract) readPoolUnlock(t *transaction.Transaction,
 // stat
 //
 
-// statistic for an allocation/blobber (used by blobbers)
+// statistic for an allocation/blobber (used by blobbers); if optional
+// reader_id given, then only tokens can be spent by the reader returned
 func (ssc *StorageSmartContract) getReadPoolAllocBlobberStatHandler(
 	ctx context.Context, params url.Values, balances cstate.StateContextI) (
 	resp interface{}, err error) {
@@ -495,6 +650,7 @@ func (ssc *StorageSmartContract) getReadPoolAllocBlobberStatHandler(
 		clientID  = params.Get("client_id")
 		allocID   = params.Get("allocation_id")
 		blobberID = params.Get("blobber_id")
+		readerID  = params.Get("reader_id")
 		rp        *readPool
 	)
 
@@ -503,7 +659,8 @@ func (ssc *StorageSmartContract) getReadPoolAllocBlobberStatHandler(
 	}
 
 	var (
-		cut  = rp.blobberCut(allocID, blobberID, common.Now())
+		now  = common.Now()
+		cut  = rp.blobberCut(allocID, blobberID, now)
 		stat []untilStat
 	)
 
@@ -512,9 +669,17 @@ func (ssc *StorageSmartContract) getReadPoolAllocBlobberStatHandler(
 		if !ok {
 			continue
 		}
+		var balance = bp.Balance
+		if readerID != "" {
+			balance = ap.Readers.canSpend(readerID, clientID, balance,
+				now)
+			if balance == 0 {
+				continue
+			}
+		}
 		stat = append(stat, untilStat{
 			PoolID:   ap.ID,
-			Balance:  bp.Balance,
+			Balance:  balance,
 			ExpireAt: ap.ExpireAt,
 		})
 	}
@@ -11,6 +11,7 @@ import (
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/tokenpool"
 	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -262,3 +263,123 @@ func TestStorageSmartContract_readPoolLock(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotZero(t, resp)
 }
+
+func Test_readPool_moveToBlobber_readers(t *testing.T) {
+
+	const (
+		allocID, blobberID = "alloc", "blobber"
+		reader, payer      = "reader", "payer"
+		allocOwner         = "owner" // the allocation owner isn't the payer
+	)
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		sp       = newStakePool()
+		dp       = new(delegatePool)
+		rp       readPool
+		err      error
+	)
+
+	balances.setTransaction(t, &transaction.Transaction{ToClientID: ssc.ID})
+	dp.ID, dp.Balance, dp.DelegateID = "delegate", 10, "delegate"
+	sp.Pools[dp.ID] = dp
+
+	var newPool = func(id string, expireAt common.Timestamp,
+		readers readerLimits) {
+
+		rp.Pools.add(&allocationPool{
+			ZcnPool: tokenpool.ZcnPool{
+				TokenPool: tokenpool.TokenPool{ID: id, Balance: 10},
+			},
+			AllocationID: allocID,
+			Blobbers: blobberPools{
+				&blobberPool{BlobberID: blobberID, Balance: 10},
+			},
+			ExpireAt: expireAt,
+			Readers:  readers,
+		})
+	}
+	newPool("open", 200, nil)
+	newPool("sponsored", 100, readerLimits{
+		&readerLimit{ClientID: reader, Limit: 4, ExpireAt: 50},
+	})
+
+	var move = func(readerID string, now common.Timestamp,
+		value state.Balance) error {
+
+		_, err := rp.moveToBlobber(ssc.ID, allocID, blobberID, readerID,
+			payer, sp, now, value, balances)
+		return err
+	}
+
+	// up to the limit from the sponsored lock, the rest from open one
+	require.NoError(t, move(reader, 10, 6))
+	assert.EqualValues(t, 14, rp.allocBlobberTotal(allocID, blobberID, 10))
+
+	var stat = rp.stat(10)
+	require.Len(t, stat.Pools, 2)
+	for _, aps := range stat.Pools {
+		if aps.ID != "sponsored" {
+			assert.Len(t, aps.Readers, 0)
+			assert.EqualValues(t, 8, aps.Balance)
+			continue
+		}
+		assert.EqualValues(t, 6, aps.Balance)
+		require.Len(t, aps.Readers, 1)
+		assert.EqualValues(t, 4, aps.Readers[0].Spent)
+		assert.EqualValues(t, 0, aps.Readers[0].Left)
+	}
+
+	// not a reader of the sponsored lock, only open lock tokens
+	require.NoError(t, move("stranger", 10, 5))
+
+	// the reader expired, only open lock tokens
+	require.NoError(t, move(reader, 60, 3))
+	assert.EqualValues(t, 6, rp.allocBlobberTotal(allocID, blobberID, 60))
+
+	err = move("stranger", 60, 1)
+	require.Error(t, err)
+	err = move(reader, 60, 1)
+	require.Error(t, err)
+
+	// the allocation owner isn't a reader of the payer's locks
+	err = move(allocOwner, 60, 1)
+	require.Error(t, err)
+
+	// the payer spends the sponsored lock without the limits
+	require.NoError(t, move(payer, 60, 5))
+	assert.EqualValues(t, 1, rp.allocBlobberTotal(allocID, blobberID, 60))
+	stat = rp.stat(60)
+	require.Len(t, stat.Pools, 1)
+	require.Len(t, stat.Pools[0].Readers, 1)
+	assert.EqualValues(t, 4, stat.Pools[0].Readers[0].Spent)
+}
+
+func Test_newReaderLimits(t *testing.T) {
+	var rls, err = newReaderLimits([]*readerLockRequest{
+		{ClientID: "a", Limit: 10},
+		{ClientID: "b", Limit: 5, Duration: 10 * time.Second},
+	}, time.Minute, 100)
+	require.NoError(t, err)
+	require.Len(t, rls, 2)
+	assert.EqualValues(t, 160, rls[0].ExpireAt)
+	assert.EqualValues(t, 110, rls[1].ExpireAt)
+	assert.EqualValues(t, 5, rls.canSpend("a", "payer", 5, 160))
+	assert.EqualValues(t, 0, rls.canSpend("a", "payer", 5, 161))
+	assert.EqualValues(t, 0, rls.canSpend("c", "payer", 5, 100))
+	assert.EqualValues(t, 5, rls.canSpend("payer", "payer", 5, 161))
+	assert.EqualValues(t, 5, readerLimits(nil).canSpend("c", "payer", 5, 100))
+
+	_, err = newReaderLimits([]*readerLockRequest{
+		{ClientID: "a", Limit: 10}, {ClientID: "a", Limit: 5},
+	}, time.Minute, 100)
+	require.Error(t, err)
+	_, err = newReaderLimits([]*readerLockRequest{
+		{ClientID: "a", Limit: 10, Duration: 2 * time.Minute},
+	}, time.Minute, 100)
+	require.Error(t, err)
+	_, err = newReaderLimits([]*readerLockRequest{{ClientID: "a"}},
+		time.Minute, 100)
+	require.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package storagesc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+// reputation configurations
+type reputationConfig struct {
+	// HalfLife is period a challenge result loses half of its weight.
+	// Zero means no decay.
+	HalfLife time.Duration `json:"half_life"`
+	// MinScore of a blobber to be selected for new allocations or as a
+	// replacement. A blobber without challenges has 0.5 score, and the
+	// score of a blobber decays toward it, thus it's not greater than
+	// maxReputationMinScore, otherwise new blobbers are never selected.
+	MinScore float64 `json:"min_score"`
+}
+
+// maxReputationMinScore is score of a blobber without challenges
+const maxReputationMinScore = 0.5
+
+func (rc *reputationConfig) validate() (err error) {
+	if rc.HalfLife < 0 {
+		return fmt.Errorf("negative reputation.half_life: %v", rc.HalfLife)
+	}
+	if rc.MinScore < 0.0 || maxReputationMinScore < rc.MinScore {
+		return fmt.Errorf("reputation.min_score not in [0; %v] range: %v",
+			maxReputationMinScore, rc.MinScore)
+	}
+	return
+}
+
+func blobberReputationKey(scKey, blobberID string) datastore.Key {
+	return datastore.Key(scKey + ":reputation:" + blobberID)
+}
+
+// blobberReputation is time-decayed challenges history of a blobber
+type blobberReputation struct {
+	BlobberID string `json:"blobber_id"`
+	// decayed number of passed and failed challenges
+	Passed float64 `json:"passed"`
+	Failed float64 `json:"failed"`
+	// Score in [0; 1] range, for the last update
+	Score      float64          `json:"score"`
+	LastUpdate common.Timestamp `json:"last_update"`
+}
+
+func newBlobberReputation(blobberID string) (br *blobberReputation) {
+	br = new(blobberReputation)
+	br.BlobberID = blobberID
+	br.Score = br.score()
+	return
+}
+
+// Encode implements util.Serializable interface.
+func (br *blobberReputation) Encode() []byte {
+	var b, err = json.Marshal(br)
+	if err != nil {
+		panic(err) // must never happens
+	}
+	return b
+}
+
+// Decode implements util.Serializable interface.
+func (br *blobberReputation) Decode(p []byte) error {
+	return json.Unmarshal(p, br)
+}
+
+// score is share of passed challenges smoothed toward 0.5 for a blobber
+// with few challenges
+func (br *blobberReputation) score() float64 {
+	return (br.Passed + 1) / (br.Passed + br.Failed + 2)
+}
+
+// decay the history to given time
+func (br *blobberReputation) decay(now common.Timestamp,
+	halfLife time.Duration) {
+
+	if halfLife > 0 && now > br.LastUpdate {
+		var k = math.Pow(0.5, float64(now-br.LastUpdate)/halfLife.Seconds())
+		br.Passed *= k
+		br.Failed *= k
+	}
+	if now > br.LastUpdate {
+		br.LastUpdate = now
+	}
+	br.Score = br.score()
+}
+
+// add a challenge result, the passed is in [0; 1] range, where
+// values between are for partially passed challenges
+func (br *blobberReputation) add(passed float64, now common.Timestamp,
+	halfLife time.Duration) {
+
+	br.decay(now, halfLife)
+	br.Passed += passed
+	br.Failed += 1 - passed
+	br.Score = br.score()
+}
+
+// getBlobberReputation returns reputation of a blobber, or new reputation
+// if the blobber has no challenges yet
+func (ssc *StorageSmartContract) getBlobberReputation(blobberID string,
+	balances cstate.StateContextI) (br *blobberReputation, err error) {
+
+	var val util.Serializable
+	val, err = balances.GetTrieNode(blobberReputationKey(ssc.ID, blobberID))
+	if err == util.ErrValueNotPresent {
+		return newBlobberReputation(blobberID), nil
+	}
+	if err != nil {
+		return
+	}
+	br = new(blobberReputation)
+	if err = br.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("decoding blobber reputation: %v", err)
+	}
+	return
+}
+
+// updateBlobberReputation adds a challenge result to the blobber's
+// reputation and saves it
+func (ssc *StorageSmartContract) updateBlobberReputation(conf *scConfig,
+	blobberID string, passed float64, now common.Timestamp,
+	balances cstate.StateContextI) (err error) {
+
+	var br *blobberReputation
+	if br, err = ssc.getBlobberReputation(blobberID, balances); err != nil {
+		return fmt.Errorf("can't get blobber reputation: %v", err)
+	}
+	br.add(passed, now, conf.reputation().HalfLife)
+	_, err = balances.InsertTrieNode(blobberReputationKey(ssc.ID, blobberID),
+		br)
+	if err != nil {
+		return fmt.Errorf("can't save blobber reputation: %v", err)
+	}
+	return
+}
+
+// filterBlobbersByReputation kicks off blobbers with score less than
+// configured
+func (ssc *StorageSmartContract) filterBlobbersByReputation(conf *scConfig,
+	now common.Timestamp, balances cstate.StateContextI) (
+	filter filterBlobberFunc) {
+
+	var rc = conf.reputation()
+	return filterBlobberFunc(func(b *StorageNode) (kick bool) {
+		if rc.MinScore == 0 {
+			return false // disabled
+		}
+		var br, err = ssc.getBlobberReputation(b.ID, balances)
+		if err != nil {
+			return true // kick off
+		}
+		br.decay(now, rc.HalfLife)
+		return br.Score < rc.MinScore
+	})
+}
+
+// blobberWithReputation is response of the /getBlobber handler
+type blobberWithReputation struct {
+	*StorageNode
+	Reputation *blobberReputation `json:"reputation"`
+}
@@ -0,0 +1,106 @@
+package storagesc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_blobberReputation(t *testing.T) {
+
+	var (
+		br       = newBlobberReputation("blobber")
+		halfLife = 10 * time.Second
+	)
+
+	assert.Equal(t, 0.5, br.Score)
+
+	br.add(1, 10, halfLife)
+	br.add(1, 10, halfLife)
+	assert.InDelta(t, 0.75, br.Score, 1e-9)
+
+	br.add(0, 10, halfLife)
+	br.add(0.5, 10, halfLife)
+	assert.InDelta(t, 3.5/6.0, br.Score, 1e-9)
+
+	// half life later
+	br.decay(20, halfLife)
+	assert.InDelta(t, 1.25, br.Passed, 1e-9)
+	assert.InDelta(t, 0.75, br.Failed, 1e-9)
+	assert.EqualValues(t, 20, br.LastUpdate)
+
+	// no decay
+	br.decay(100, 0)
+	assert.InDelta(t, 1.25, br.Passed, 1e-9)
+}
+
+func TestStorageSmartContract_blobberReputation(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		conf     = setConfig(t, balances)
+		tp       = int64(100)
+		err      error
+	)
+
+	var (
+		good = addBlobber(t, ssc, 2*GB, tp, avgTerms, 50*x10, balances)
+		bad  = addBlobber(t, ssc, 2*GB, tp, avgTerms, 50*x10, balances)
+	)
+
+	for i := 0; i < 5; i++ {
+		tp++
+		require.NoError(t, ssc.updateBlobberReputation(conf, good.id, 1,
+			common.Timestamp(tp), balances))
+		require.NoError(t, ssc.updateBlobberReputation(conf, bad.id, 0,
+			common.Timestamp(tp), balances))
+	}
+
+	var goodb, badb *StorageNode
+	goodb, err = ssc.getBlobber(good.id, balances)
+	require.NoError(t, err)
+	badb, err = ssc.getBlobber(bad.id, balances)
+	require.NoError(t, err)
+
+	// disabled
+	var filter = ssc.filterBlobbersByReputation(conf,
+		common.Timestamp(tp), balances)
+	assert.False(t, filter(goodb))
+	assert.False(t, filter(badb))
+
+	conf.Reputation = &reputationConfig{MinScore: 0.4}
+	filter = ssc.filterBlobbersByReputation(conf, common.Timestamp(tp),
+		balances)
+	assert.False(t, filter(goodb))
+	assert.True(t, filter(badb))
+
+	// exposed by /getBlobber
+	var resp interface{}
+	resp, err = ssc.GetBlobberHandler(context.Background(),
+		url.Values{"blobber_id": []string{bad.id}}, balances)
+	require.NoError(t, err)
+	var bwr = resp.(*blobberWithReputation)
+	assert.Equal(t, bad.id, bwr.ID)
+	assert.InDelta(t, 5.0, bwr.Reputation.Failed, 1e-9)
+	assert.InDelta(t, 1.0/7.0, bwr.Reputation.Score, 1e-9)
+}
+
+func Test_reputationConfig_validate(t *testing.T) {
+	assert.NoError(t, (&reputationConfig{MinScore: 0}).validate())
+	assert.NoError(t, (&reputationConfig{MinScore: 0.5}).validate())
+	// a new blobber would never be selected
+	assert.Error(t, (&reputationConfig{MinScore: 0.6}).validate())
+	assert.Error(t, (&reputationConfig{MinScore: -0.1}).validate())
+	assert.Error(t, (&reputationConfig{HalfLife: -1}).validate())
+
+	// a blobber without challenges passes the max min_score
+	var br = newBlobberReputation("blobber")
+	assert.False(t, br.Score < maxReputationMinScore)
+}
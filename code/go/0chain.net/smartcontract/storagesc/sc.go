@@ -40,18 +40,26 @@ func (ssc *StorageSmartContract) SetSC(sc *sci.SmartContract, bcContext sci.BCCo
 	ssc.SmartContract.RestHandlers["/allocation"] = ssc.AllocationStatsHandler
 	ssc.SmartContract.RestHandlers["/allocations"] = ssc.GetAllocationsHandler
 	ssc.SmartContract.RestHandlers["/allocation_min_lock"] = ssc.GetAllocationMinLockHandler
+	ssc.SmartContract.RestHandlers["/allocation_update_quote"] = ssc.GetAllocationUpdateQuoteHandler
 	ssc.SmartContractExecutionStats["new_allocation_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "new_allocation_request"), nil)
 	ssc.SmartContractExecutionStats["update_allocation_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_allocation_request"), nil)
 	ssc.SmartContractExecutionStats["finalize_allocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "finalize_allocation"), nil)
 	ssc.SmartContractExecutionStats["cancel_allocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "cancel_allocation"), nil)
+	ssc.SmartContractExecutionStats["transfer_allocation_ownership"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "transfer_allocation_ownership"), nil)
+	ssc.SmartContractExecutionStats["replace_blobber"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "replace_blobber"), nil)
 	// challenge
 	ssc.SmartContract.RestHandlers["/openchallenges"] = ssc.OpenChallengeHandler
 	ssc.SmartContract.RestHandlers["/getchallenge"] = ssc.GetChallengeHandler
 	ssc.SmartContractExecutionStats["challenge_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "challenge_request"), nil)
 	ssc.SmartContractExecutionStats["challenge_response"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "challenge_response"), nil)
 	ssc.SmartContractExecutionStats["generate_challenges"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "generate_challenges"), nil)
+	ssc.SmartContractExecutionStats["generate_round_challenges"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "generate_round_challenges"), nil)
+	ssc.SmartContractExecutionStats["close_expired_challenges"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "close_expired_challenges"), nil)
 	// validator
 	ssc.SmartContractExecutionStats["add_validator"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_validator (add/update SC function)"), nil)
+	ssc.SmartContractExecutionStats["update_validator_settings"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_validator_settings"), nil)
+	ssc.SmartContractExecutionStats["shutdown_validator"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "shutdown_validator"), nil)
+	ssc.SmartContractExecutionStats["validator_health_check"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "validator_health_check"), nil)
 	// validators stat (not function calls)
 	ssc.SmartContractExecutionStats[statAddValidator] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_validator"), nil)
 	ssc.SmartContractExecutionStats[statUpdateValidator] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_validator"), nil)
@@ -61,6 +69,7 @@ func (ssc *StorageSmartContract) SetSC(sc *sci.SmartContract, bcContext sci.BCCo
 	ssc.SmartContract.RestHandlers["/getBlobber"] = ssc.GetBlobberHandler
 	ssc.SmartContractExecutionStats["add_blobber"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_blobber (add/update/remove SC function)"), nil)
 	ssc.SmartContractExecutionStats["update_blobber_settings"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_blobber_settings"), nil)
+	ssc.SmartContractExecutionStats["update_blobber_status"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_blobber_status"), nil)
 	// blobber statistic (not function calls)
 	ssc.SmartContractExecutionStats[statNumberOfBlobbers] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "stat: number of blobbers"), nil)
 	ssc.SmartContractExecutionStats[statAddBlobber] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "stat: add bblober"), nil)
@@ -141,6 +150,35 @@ func (ssc *StorageSmartContract) statDecr(name string) {
 	count.Dec(1)
 }
 
+// markersChallengesEnabled is true if challenges generated by read and write
+// markers; it's false if challenges disabled or generated every round; it's
+// decided by the SC configurations in the state, not by the local sc.yaml
+func (sc *StorageSmartContract) markersChallengesEnabled(
+	balances chainstate.StateContextI) (bool, error) {
+
+	var conf, err = sc.getConfig(balances, true)
+	if err != nil {
+		return false, fmt.Errorf("can't get SC configurations: %v", err)
+	}
+	return conf.ChallengeEnabled && conf.ChallengesPerGBPerHour == 0, nil
+}
+
+// generateMarkersChallenges generates challenges for given read or write
+// marker transaction if the markers challenges enabled
+func (sc *StorageSmartContract) generateMarkersChallenges(
+	t *transaction.Transaction, input []byte,
+	balances chainstate.StateContextI) error {
+
+	var enabled, err = sc.markersChallengesEnabled(balances)
+	if err != nil {
+		return common.NewError("generate_challenges_failed", err.Error())
+	}
+	if !enabled {
+		return nil
+	}
+	return sc.generateChallenges(t, balances.GetBlock(), input, balances)
+}
+
 // functions execution
 
 func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
@@ -155,13 +193,8 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 		if resp, err = sc.commitBlobberRead(t, input, balances); err != nil {
 			return
 		}
-		challengesEnabled := config.SmartContractConfig.GetBool(
-			"smart_contracts.storagesc.challenge_enabled")
-		if challengesEnabled {
-			err = sc.generateChallenges(t, balances.GetBlock(), input, balances)
-			if err != nil {
-				return "", err
-			}
+		if err = sc.generateMarkersChallenges(t, input, balances); err != nil {
+			return "", err
 		}
 
 	case "commit_connection":
@@ -170,13 +203,8 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 			return
 		}
 
-		challengesEnabled := config.SmartContractConfig.GetBool(
-			"smart_contracts.storagesc.challenge_enabled")
-		if challengesEnabled {
-			err = sc.generateChallenges(t, balances.GetBlock(), input, balances)
-			if err != nil {
-				return "", err
-			}
+		if err = sc.generateMarkersChallenges(t, input, balances); err != nil {
+			return "", err
 		}
 
 	// allocations
@@ -189,6 +217,10 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 		resp, err = sc.finalizeAllocation(t, input, balances)
 	case "cancel_allocation":
 		resp, err = sc.cacnelAllocationRequest(t, input, balances)
+	case "transfer_allocation_ownership":
+		resp, err = sc.transferAllocationOwnership(t, input, balances)
+	case "replace_blobber":
+		resp, err = sc.replaceBlobber(t, input, balances)
 
 	// blobbers
 
@@ -196,10 +228,18 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 		resp, err = sc.addBlobber(t, input, balances)
 	case "add_validator":
 		resp, err = sc.addValidator(t, input, balances)
+	case "update_validator_settings":
+		resp, err = sc.updateValidatorSettings(t, input, balances)
+	case "shutdown_validator":
+		resp, err = sc.shutdownValidator(t, input, balances)
+	case "validator_health_check":
+		resp, err = sc.validatorHealthCheck(t, input, balances)
 	case "blobber_health_check":
 		resp, err = sc.blobberHealthCheck(t, input, balances)
 	case "update_blobber_settings":
 		resp, err = sc.updateBlobberSettings(t, input, balances)
+	case "update_blobber_status":
+		resp, err = sc.updateBlobberStatus(t, input, balances)
 
 	// read_pool
 
@@ -246,9 +286,15 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 		}
 		return "Challenges generated", nil
 
+	case "generate_round_challenges":
+		resp, err = sc.generateRoundChallenges(t, input, balances)
+
 	case "challenge_response":
 		resp, err = sc.verifyChallenge(t, input, balances)
 
+	case "close_expired_challenges":
+		resp, err = sc.closeExpiredChallenges(t, input, balances)
+
 	// configurations
 
 	case "update_config":
@@ -139,3 +139,11 @@ func (sb sortedBlobbers) copy() (cp []*StorageNode) {
 	}
 	return
 }
+
+func (sb sortedBlobbers) ids() (ids []string) {
+	ids = make([]string, 0, len(sb))
+	for _, b := range sb {
+		ids = append(ids, b.ID)
+	}
+	return
+}
@@ -340,7 +340,8 @@ func (sp *stakePool) timeToUnstake(dp *delegatePool) (
 
 // empty a delegate pool if possible, call update before the empty
 func (sp *stakePool) empty(sscID, poolID, clientID string,
-	info *stakePoolUpdateInfo, balances chainstate.StateContextI) (
+	info *stakePoolUpdateInfo, shutdown bool,
+	balances chainstate.StateContextI) (
 	resp string, unstake common.Timestamp, err error) {
 
 	var dp, ok = sp.Pools[poolID]
@@ -352,7 +353,9 @@ func (sp *stakePool) empty(sscID, poolID, clientID string,
 		return "", 0, errors.New("trying to unlock not by delegate pool owner")
 	}
 
-	if info.stake-info.offers-dp.Balance < 0 {
+	// a shut down blobber has no allocations to serve, and its stake
+	// can be unlocked regardless offers left
+	if !shutdown && info.stake-info.offers-dp.Balance < 0 {
 		// is marked as 'unstake'
 		if dp.Unstake > 0 {
 			return "", 0, errors.New("the stake pool locked for opened " +
@@ -603,6 +606,39 @@ func (sp *stakePool) slash(allocID, blobID string, until common.Timestamp,
 	return
 }
 
+// slashToClient moves given part of the stake to given client, e.g. a reward
+// paid out of a penalty; every delegate pool loses its share of the slash
+func (sp *stakePool) slashToClient(sscKey, clientID string,
+	slash state.Balance, balances chainstate.StateContextI) (
+	move state.Balance, err error) {
+
+	var stake = sp.stake()
+	if slash == 0 || stake == 0 {
+		return // nothing to move
+	}
+
+	var ratio = (float64(slash) / float64(stake))
+
+	for _, dp := range sp.orderedPools() {
+		var one = state.Balance(float64(dp.Balance) * ratio)
+		if one == 0 {
+			continue
+		}
+		var transfer *state.Transfer
+		transfer, _, err = dp.DrainPool(sscKey, clientID, one, nil)
+		if err != nil {
+			return 0, fmt.Errorf("draining blobber slash: %v", err)
+		}
+		if err = balances.AddTransfer(transfer); err != nil {
+			return 0, fmt.Errorf("transferring blobber slash: %v", err)
+		}
+		dp.Penalty += one
+		move += one
+	}
+
+	return
+}
+
 // free staked capacity of related blobber, excluding delegate pools want to
 // unstake.
 func (sp *stakePool) cleanCapacity(now common.Timestamp,
@@ -1028,9 +1064,18 @@ func (ssc *StorageSmartContract) stakePoolUnlock(t *transaction.Transaction,
 			"can't get related user stake pools: %v", err)
 	}
 
+	var blob *StorageNode
+	switch blob, err = ssc.getBlobber(spr.BlobberID, balances); err {
+	case nil, util.ErrValueNotPresent:
+	default:
+		return "", common.NewErrorf("stake_pool_unlock_failed",
+			"can't get the blobber: %v", err)
+	}
+	var shutdown = blob != nil && blob.Status == blobberStatusShutdown
+
 	var unstake common.Timestamp
 	resp, unstake, err = sp.empty(ssc.ID, spr.PoolID, t.ClientID, info,
-		balances)
+		shutdown, balances)
 	if err != nil {
 		return "", common.NewErrorf("stake_pool_unlock_failed",
 			"unlocking tokens: %v", err)
@@ -75,3 +75,31 @@ func Test_stakePool_fill(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, state.Balance(90), sp.stake())
 }
+
+func Test_stakePool_slashToClient(t *testing.T) {
+	var (
+		sp       = newStakePool()
+		balances = newTestBalances(t, false)
+		move     state.Balance
+		err      error
+	)
+	balances.setTransaction(t, &transaction.Transaction{ToClientID: ADDRESS})
+
+	move, err = sp.slashToClient(ADDRESS, "closer", 10, balances)
+	require.NoError(t, err)
+	assert.Zero(t, move, "no stake")
+
+	for _, id := range []string{"a", "b"} {
+		var dp = new(delegatePool)
+		dp.ID, dp.Balance, dp.DelegateID = id, 50, id
+		sp.Pools[id] = dp
+	}
+	move, err = sp.slashToClient(ADDRESS, "closer", 10, balances)
+	require.NoError(t, err)
+	assert.Equal(t, state.Balance(10), move)
+	assert.Equal(t, state.Balance(10), balances.balances["closer"])
+	for _, dp := range sp.Pools {
+		assert.Equal(t, state.Balance(45), dp.Balance)
+		assert.Equal(t, state.Balance(5), dp.Penalty)
+	}
+}
@@ -2,13 +2,18 @@ package storagesc
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
 
 	c_state "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
+	"0chain.net/core/util"
 )
 
+const validatorHealthTime = 60 * 60 // 1 Hour
+
 func (sc *StorageSmartContract) getValidatorsList(balances c_state.StateContextI) (*ValidatorNodes, error) {
 	allValidatorsList := &ValidatorNodes{}
 	allValidatorsBytes, err := balances.GetTrieNode(ALL_VALIDATORS_KEY)
@@ -37,6 +42,8 @@ func (sc *StorageSmartContract) addValidator(t *transaction.Transaction, input [
 	}
 	newValidator.ID = t.ClientID
 	newValidator.PublicKey = t.PublicKey
+	newValidator.LastHealthCheck = t.CreationDate // health
+	newValidator.IsShutdown = false
 	blobberBytes, _ := balances.GetTrieNode(newValidator.GetKey(sc.ID))
 	if blobberBytes == nil {
 		allValidatorsList.Nodes = append(allValidatorsList.Nodes, newValidator)
@@ -46,6 +53,15 @@ func (sc *StorageSmartContract) addValidator(t *transaction.Transaction, input [
 
 		sc.statIncr(statAddValidator)
 		sc.statIncr(statNumberOfValidators)
+	} else if existing, err := sc.getValidator(t.ClientID, balances); err == nil &&
+		existing.IsShutdown {
+		// reborn
+		allValidatorsList.Nodes = append(allValidatorsList.Nodes, newValidator)
+		balances.InsertTrieNode(ALL_VALIDATORS_KEY, allValidatorsList)
+		balances.InsertTrieNode(newValidator.GetKey(sc.ID), newValidator)
+
+		sc.statIncr(statUpdateValidator)
+		sc.statIncr(statNumberOfValidators)
 	} else {
 		sc.statIncr(statUpdateValidator)
 	}
@@ -72,3 +88,270 @@ func (sc *StorageSmartContract) addValidator(t *transaction.Transaction, input [
 	buff := newValidator.Encode()
 	return string(buff), nil
 }
+
+func (sc *StorageSmartContract) getValidator(validatorID string,
+	balances c_state.StateContextI) (validator *ValidationNode, err error) {
+
+	validator = &ValidationNode{ID: validatorID}
+	var validatorBytes util.Serializable
+	validatorBytes, err = balances.GetTrieNode(validator.GetKey(sc.ID))
+	if err != nil {
+		return nil, err
+	}
+	if err = validator.Decode(validatorBytes.Encode()); err != nil {
+		return nil, fmt.Errorf("decoding stored validator: %v", err)
+	}
+	return
+}
+
+// getIndex of a validator in the list, the list is not sorted on insertion
+func (sn *ValidatorNodes) getIndex(id string) (i int, ok bool) {
+	var vn *ValidationNode
+	for i, vn = range sn.Nodes {
+		if vn.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// filterHealthyValidators returns validators sent a health check
+// transaction recently; validators registered before the health checks
+// introduced have zero last health check, they are considered healthy
+// during one health check period since the health checks started to be
+// enforced, then they should send a health check
+func filterHealthyValidators(list []*ValidationNode,
+	since, now common.Timestamp) (healthy []*ValidationNode) {
+
+	healthy = make([]*ValidationNode, 0, len(list))
+	for _, vn := range list {
+		var last = vn.LastHealthCheck
+		if last == 0 {
+			last = since // legacy validator, grace period
+		}
+		if last > (now - validatorHealthTime) {
+			healthy = append(healthy, vn)
+		}
+	}
+	return
+}
+
+// startHealthChecks sets time the health checks of validators enforced
+// since, once, by the first challenges generation after the upgrade
+func (sc *StorageSmartContract) startHealthChecks(all *ValidatorNodes,
+	now common.Timestamp, balances c_state.StateContextI) (err error) {
+
+	if all.HealthChecksSince != 0 || len(all.Nodes) == 0 {
+		return
+	}
+	all.HealthChecksSince = now
+	_, err = balances.InsertTrieNode(ALL_VALIDATORS_KEY, all)
+	return
+}
+
+func (sc *StorageSmartContract) validatorHealthCheck(
+	t *transaction.Transaction, _ []byte, balances c_state.StateContextI) (
+	string, error) {
+
+	all, err := sc.getValidatorsList(balances)
+	if err != nil {
+		return "", common.NewError("validator_health_check_failed",
+			"Failed to get validator list: "+err.Error())
+	}
+
+	var existing *ValidationNode
+	if existing, err = sc.getValidator(t.ClientID, balances); err != nil {
+		return "", common.NewError("validator_health_check_failed",
+			"can't get the validator "+t.ClientID+": "+err.Error())
+	}
+
+	var i, ok = all.getIndex(t.ClientID)
+	// if validator has been shut down, then it shouldn't send the health
+	// check transactions
+	if !ok {
+		return "", common.NewError("validator_health_check_failed",
+			"validator "+t.ClientID+" not found in all validators list")
+	}
+
+	existing.LastHealthCheck = t.CreationDate
+	all.Nodes[i].LastHealthCheck = t.CreationDate
+
+	if _, err = balances.InsertTrieNode(ALL_VALIDATORS_KEY, all); err != nil {
+		return "", common.NewError("validator_health_check_failed",
+			"can't save all validators list: "+err.Error())
+	}
+
+	_, err = balances.InsertTrieNode(existing.GetKey(sc.ID), existing)
+	if err != nil {
+		return "", common.NewError("validator_health_check_failed",
+			"can't save validator: "+err.Error())
+	}
+
+	return string(existing.Encode()), nil
+}
+
+// validatorDelegateWalletOnly checks the client is the delegate wallet of
+// stake pool of the validator
+func validatorDelegateWalletOnly(sp *stakePool, clientID string) error {
+	if sp.Settings.DelegateWallet == "" {
+		return errors.New("validator's delegate_wallet is not set")
+	}
+	if clientID != sp.Settings.DelegateWallet {
+		return errors.New("access denied, allowed for delegate_wallet owner only")
+	}
+	return nil
+}
+
+// updateValidatorSettings updates URL and stake pool settings of a validator,
+// allowed for delegate wallet of the validator only
+func (sc *StorageSmartContract) updateValidatorSettings(
+	t *transaction.Transaction, input []byte,
+	balances c_state.StateContextI) (resp string, err error) {
+
+	var conf *scConfig
+	if conf, err = sc.getConfig(balances, true); err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	var update = new(ValidationNode)
+	if err = update.Decode(input); err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			"malformed request: "+err.Error())
+	}
+
+	var validator *ValidationNode
+	if validator, err = sc.getValidator(update.ID, balances); err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			"can't get the validator: "+err.Error())
+	}
+
+	var sp *stakePool
+	if sp, err = sc.getStakePool(update.ID, balances); err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			"can't get related stake pool: "+err.Error())
+	}
+
+	if err = validatorDelegateWalletOnly(sp, t.ClientID); err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			err.Error())
+	}
+
+	if err = update.StakePoolSettings.validate(conf); err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			"validating new stake pool settings: "+err.Error())
+	}
+
+	sp.Settings.MinStake = update.StakePoolSettings.MinStake
+	sp.Settings.MaxStake = update.StakePoolSettings.MaxStake
+	sp.Settings.ServiceCharge = update.StakePoolSettings.ServiceCharge
+	sp.Settings.NumDelegates = update.StakePoolSettings.NumDelegates
+
+	validator.StakePoolSettings = update.StakePoolSettings
+	validator.StakePoolSettings.DelegateWallet = sp.Settings.DelegateWallet
+	if update.BaseURL != "" {
+		validator.BaseURL = update.BaseURL
+	}
+
+	if !validator.IsShutdown {
+		var all *ValidatorNodes
+		if all, err = sc.getValidatorsList(balances); err != nil {
+			return "", common.NewError("update_validator_settings_failed",
+				"Failed to get validator list: "+err.Error())
+		}
+		if i, ok := all.getIndex(validator.ID); ok {
+			all.Nodes[i] = validator
+		}
+		_, err = balances.InsertTrieNode(ALL_VALIDATORS_KEY, all)
+		if err != nil {
+			return "", common.NewError("update_validator_settings_failed",
+				"saving all validators: "+err.Error())
+		}
+	}
+
+	_, err = balances.InsertTrieNode(validator.GetKey(sc.ID), validator)
+	if err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			"saving validator: "+err.Error())
+	}
+
+	if err = sp.save(sc.ID, validator.ID, balances); err != nil {
+		return "", common.NewError("update_validator_settings_failed",
+			"saving stake pool: "+err.Error())
+	}
+
+	sc.statIncr(statUpdateValidator)
+	return string(validator.Encode()), nil
+}
+
+// shutdown validator request
+type shutdownValidatorRequest struct {
+	ID string `json:"id"` // validator id, the client by default
+}
+
+// shutdownValidator removes a validator from the all validators list, thus
+// it's not selected for new challenges anymore; allowed for the validator
+// and delegate wallet of the validator; its stake pool is kept, delegates
+// can unlock their tokens; the validator can be added back by add_validator
+func (sc *StorageSmartContract) shutdownValidator(
+	t *transaction.Transaction, input []byte,
+	balances c_state.StateContextI) (resp string, err error) {
+
+	var req shutdownValidatorRequest
+	if len(input) > 0 {
+		if err = json.Unmarshal(input, &req); err != nil {
+			return "", common.NewError("shutdown_validator_failed",
+				"malformed request: "+err.Error())
+		}
+	}
+	if req.ID == "" {
+		req.ID = t.ClientID
+	}
+
+	var validator *ValidationNode
+	if validator, err = sc.getValidator(req.ID, balances); err != nil {
+		return "", common.NewError("shutdown_validator_failed",
+			"can't get the validator: "+err.Error())
+	}
+
+	if t.ClientID != validator.ID {
+		var sp *stakePool
+		if sp, err = sc.getStakePool(validator.ID, balances); err != nil {
+			return "", common.NewError("shutdown_validator_failed",
+				"can't get related stake pool: "+err.Error())
+		}
+		if err = validatorDelegateWalletOnly(sp, t.ClientID); err != nil {
+			return "", common.NewError("shutdown_validator_failed",
+				err.Error())
+		}
+	}
+
+	if validator.IsShutdown {
+		return "", common.NewError("shutdown_validator_failed",
+			"validator is already shut down")
+	}
+
+	var all *ValidatorNodes
+	if all, err = sc.getValidatorsList(balances); err != nil {
+		return "", common.NewError("shutdown_validator_failed",
+			"Failed to get validator list: "+err.Error())
+	}
+	if i, ok := all.getIndex(validator.ID); ok {
+		all.Nodes = append(all.Nodes[:i], all.Nodes[i+1:]...)
+	}
+
+	validator.IsShutdown = true
+
+	if _, err = balances.InsertTrieNode(ALL_VALIDATORS_KEY, all); err != nil {
+		return "", common.NewError("shutdown_validator_failed",
+			"saving all validators: "+err.Error())
+	}
+	_, err = balances.InsertTrieNode(validator.GetKey(sc.ID), validator)
+	if err != nil {
+		return "", common.NewError("shutdown_validator_failed",
+			"saving validator: "+err.Error())
+	}
+
+	sc.statDecr(statNumberOfValidators)
+	return string(validator.Encode()), nil
+}
@@ -0,0 +1,194 @@
+package storagesc
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_filterHealthyValidators(t *testing.T) {
+	var (
+		now  = common.Timestamp(validatorHealthTime * 10)
+		list = []*ValidationNode{
+			{ID: "dead", LastHealthCheck: now - validatorHealthTime},
+			{ID: "alive", LastHealthCheck: now - validatorHealthTime + 1},
+			{ID: "legacy"}, // registered before health checks
+		}
+	)
+	// legacy validators are healthy during the grace period
+	var healthy = filterHealthyValidators(list, now-validatorHealthTime+1, now)
+	require.Len(t, healthy, 2)
+	assert.Equal(t, "alive", healthy[0].ID)
+	assert.Equal(t, "legacy", healthy[1].ID)
+	// and dead after it
+	healthy = filterHealthyValidators(list, now-validatorHealthTime, now)
+	require.Len(t, healthy, 1)
+	assert.Equal(t, "alive", healthy[0].ID)
+}
+
+func TestStorageSmartContract_validatorLifecycle(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		owner    = newClient(0, balances)
+		tp       = int64(100)
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	// add validator with a delegate wallet
+	var valid = newClient(0, balances)
+	var vn ValidationNode
+	vn.BaseURL = getValidatorURL(valid.id)
+	vn.StakePoolSettings.DelegateWallet = owner.id
+	vn.StakePoolSettings.NumDelegates = 100
+	vn.StakePoolSettings.MaxStake = 1000e10
+	var tx = newTransaction(valid.id, ADDRESS, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.addValidator(tx, mustEncode(t, &vn), balances)
+	require.NoError(t, err)
+
+	var all *ValidatorNodes
+	all, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	require.Len(t, all.Nodes, 1)
+	assert.EqualValues(t, tp, all.Nodes[0].LastHealthCheck)
+
+	// health check
+	tp += validatorHealthTime
+	tx = newTransaction(valid.id, ADDRESS, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.validatorHealthCheck(tx, nil, balances)
+	require.NoError(t, err)
+	all, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, tp, all.Nodes[0].LastHealthCheck)
+	require.Len(t, filterHealthyValidators(all.Nodes,
+		all.HealthChecksSince, tx.CreationDate), 1)
+
+	// update settings
+	var update ValidationNode
+	update.ID = valid.id
+	update.BaseURL = "http://new.url"
+	update.StakePoolSettings.NumDelegates = 10
+	update.StakePoolSettings.MaxStake = 100e10
+	tp += 1
+	tx = newTransaction(valid.id, ADDRESS, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.updateValidatorSettings(tx, mustEncode(t, &update), balances)
+	requireErrMsg(t, err, "update_validator_settings_failed: "+
+		"access denied, allowed for delegate_wallet owner only")
+
+	tx = newTransaction(owner.id, ADDRESS, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.updateValidatorSettings(tx, mustEncode(t, &update), balances)
+	require.NoError(t, err)
+
+	var sp *stakePool
+	sp, err = ssc.getStakePool(valid.id, balances)
+	require.NoError(t, err)
+	assert.Equal(t, 10, sp.Settings.NumDelegates)
+	assert.Equal(t, owner.id, sp.Settings.DelegateWallet)
+	all, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	assert.Equal(t, "http://new.url", all.Nodes[0].BaseURL)
+
+	// shutdown
+	tp += 1
+	tx = newTransaction(valid.id, ADDRESS, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.shutdownValidator(tx, nil, balances)
+	require.NoError(t, err)
+
+	all, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	assert.Len(t, all.Nodes, 0)
+
+	var got *ValidationNode
+	got, err = ssc.getValidator(valid.id, balances)
+	require.NoError(t, err)
+	assert.True(t, got.IsShutdown)
+
+	_, err = ssc.shutdownValidator(tx, nil, balances)
+	requireErrMsg(t, err, "shutdown_validator_failed: "+
+		"validator is already shut down")
+
+	_, err = ssc.validatorHealthCheck(tx, nil, balances)
+	require.Error(t, err)
+
+	// add back
+	tp += 1
+	tx = newTransaction(valid.id, ADDRESS, 0, tp)
+	balances.setTransaction(t, tx)
+	_, err = ssc.addValidator(tx, mustEncode(t, &vn), balances)
+	require.NoError(t, err)
+	all, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	require.Len(t, all.Nodes, 1)
+	got, err = ssc.getValidator(valid.id, balances)
+	require.NoError(t, err)
+	assert.False(t, got.IsShutdown)
+}
+
+func TestStorageSmartContract_generateChallenges_noHealthyValidators(t *testing.T) {
+
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		tp       = int64(validatorHealthTime * 10)
+		b        = &block.Block{}
+		err      error
+	)
+
+	setConfig(t, balances)
+
+	var stats = &StorageStats{
+		Stats:              &StorageAllocationStats{UsedSize: GB},
+		LastChallengedTime: common.Timestamp(tp - 60),
+	}
+	mustSave(t, stats.GetKey(ssc.ID), stats, balances)
+
+	var tx = newTransaction("blobber", ADDRESS, 0, tp)
+	balances.setTransaction(t, tx)
+
+	// no validators at all
+	err = ssc.generateChallenges(tx, b, nil, balances)
+	requireErrMsg(t, err, "no_validators: "+
+		"not enough validators for the challenge")
+
+	// all validators are dead, the markers are accepted without challenges
+	var all = &ValidatorNodes{Nodes: []*ValidationNode{
+		{ID: "dead", LastHealthCheck: common.Timestamp(tp - validatorHealthTime)},
+	}}
+	mustSave(t, ALL_VALIDATORS_KEY, all, balances)
+	require.NoError(t, ssc.generateChallenges(tx, b, nil, balances))
+
+	// the first challenges generation starts the grace period of legacy
+	// validators, they are selected (and there are no allocations)
+	all = &ValidatorNodes{Nodes: []*ValidationNode{{ID: "legacy"}}}
+	mustSave(t, ALL_VALIDATORS_KEY, all, balances)
+	err = ssc.generateChallenges(tx, b, nil, balances)
+	requireErrMsg(t, err, "adding_challenge_error: "+
+		"no allocations at this time")
+	all, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, tp, all.HealthChecksSince)
+	require.Len(t, all.Nodes, 1)
+
+	// the grace period is not moved by next generations, and a legacy
+	// validator without a health check is dead after it
+	tx = newTransaction("blobber", ADDRESS, 0, tp+validatorHealthTime)
+	balances.setTransaction(t, tx)
+	require.NoError(t, ssc.generateChallenges(tx, b, nil, balances))
+	all, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	assert.EqualValues(t, tp, all.HealthChecksSince)
+	assert.Empty(t, filterHealthyValidators(all.Nodes, all.HealthChecksSince,
+		tx.CreationDate))
+}
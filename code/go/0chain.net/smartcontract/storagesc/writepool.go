@@ -257,21 +257,9 @@ func (wp *writePool) fill(t *transaction.Transaction, alloc *StorageAllocation,
 	until common.Timestamp, balances chainState.StateContextI) (
 	resp string, err error) {
 
-	var bps blobberPools
 	if err = checkFill(t, balances); err != nil {
 		return
 	}
-	var total float64
-	for _, b := range alloc.BlobberDetails {
-		total += float64(b.Terms.WritePrice)
-	}
-	for _, b := range alloc.BlobberDetails {
-		var ratio = float64(b.Terms.WritePrice) / total
-		bps.add(&blobberPool{
-			Balance:   state.Balance(float64(t.Value) * ratio),
-			BlobberID: b.BlobberID,
-		})
-	}
 	var (
 		ap       allocationPool
 		transfer *state.Transfer
@@ -287,13 +275,32 @@ func (wp *writePool) fill(t *transaction.Transaction, alloc *StorageAllocation,
 	// set fields
 	ap.AllocationID = alloc.ID
 	ap.ExpireAt = until
-	ap.Blobbers = bps
+	ap.Blobbers = fillBlobbers(alloc, state.Balance(t.Value))
 
 	// add the allocation pool
 	wp.Pools.add(&ap)
 	return
 }
 
+// fillBlobbers divides given tokens between blobbers of the allocation
+// according to their write prices
+func fillBlobbers(alloc *StorageAllocation, value state.Balance) (
+	bps blobberPools) {
+
+	var total float64
+	for _, b := range alloc.BlobberDetails {
+		total += float64(b.Terms.WritePrice)
+	}
+	for _, b := range alloc.BlobberDetails {
+		var ratio = float64(b.Terms.WritePrice) / total
+		bps.add(&blobberPool{
+			Balance:   state.Balance(float64(value) * ratio),
+			BlobberID: b.BlobberID,
+		})
+	}
+	return
+}
+
 func (wp *writePool) allocUntil(allocID string, until common.Timestamp) (
 	value state.Balance) {
 
@@ -396,6 +403,11 @@ func (ssc *StorageSmartContract) writePoolLock(t *transaction.Transaction,
 			"missing allocation ID in request")
 	}
 
+	if len(lr.Readers) > 0 {
+		return "", common.NewError("write_pool_lock_failed",
+			"readers are allowed for read pool locks only")
+	}
+
 	if t.Value < conf.MinLock {
 		return "", common.NewError("write_pool_lock_failed",
 			"insufficient amount to lock")
@@ -2,6 +2,7 @@ package vestingsc
 
 import (
 	"0chain.net/chaincore/block"
+	chainstate "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
@@ -40,6 +41,8 @@ func (tb *testBalances) GetBlockSharders(b *block.Block) []string { return nil }
 func (tb *testBalances) Validate() error                          { return nil }
 func (tb *testBalances) GetMints() []*state.Mint                  { return nil }
 func (tb *testBalances) SetStateContext(*state.State) error       { return nil }
+func (tb *testBalances) EmitEvent(string, map[string]string)      {}
+func (tb *testBalances) GetEvents() []*transaction.Event          { return nil }
 func (tb *testBalances) AddMint(*state.Mint) error                { return nil }
 func (tb *testBalances) GetTransfers() []*state.Transfer          { return nil }
 func (tb *testBalances) AddSignedTransfer(st *state.SignedTransfer) {
@@ -53,6 +56,15 @@ func (tb *testBalances) GetLastestFinalizedMagicBlock() *block.Block {
 func (tb *testBalances) GetSignatureScheme() encryption.SignatureScheme {
 	return encryption.NewBLS0ChainScheme()
 }
+
+// NewCallContext returns a copy sharing the balances and the state
+func (tb *testBalances) NewCallContext(
+	txn *transaction.Transaction) chainstate.StateContextI {
+
+	var cc = *tb
+	cc.txn = txn
+	return &cc
+}
 func (tb *testBalances) GetSignedTransfers() []*state.SignedTransfer {
 	return nil
 }
@@ -9,6 +9,14 @@ import (
 	chainstate "0chain.net/chaincore/chain/state"
 	configpkg "0chain.net/chaincore/config"
 	"0chain.net/chaincore/state"
+	"0chain.net/core/datastore"
+)
+
+// defaults of stepped vesting schedule configurations, used by nodes having
+// sc.yaml without them
+const (
+	defaultMinStep  = 1 * time.Minute
+	defaultMaxSteps = 120
 )
 
 type config struct {
@@ -17,6 +25,11 @@ type config struct {
 	MaxDuration          time.Duration `json:"max_duration"`
 	MaxDestinations      int           `json:"max_destinations"`
 	MaxDescriptionLength int           `json:"max_description_length"`
+	MinStep              time.Duration `json:"min_step"`
+	MaxSteps             int           `json:"max_steps"`
+	// Treasury receives tokens of revoked destinations not vested yet,
+	// empty treasury disables revocable pools.
+	Treasury datastore.Key `json:"treasury"`
 }
 
 func (c *config) validate() (err error) {
@@ -31,6 +44,10 @@ func (c *config) validate() (err error) {
 		return errors.New("invalid max_destinations (< 1)")
 	case c.MaxDescriptionLength < 1:
 		return errors.New("invalid max_description_length (< 1)")
+	case toSeconds(c.MinStep) < 1:
+		return errors.New("invalid min_step (< 1s)")
+	case c.MaxSteps < 1:
+		return errors.New("invalid max_steps (< 1)")
 	}
 	return
 }
@@ -53,6 +70,15 @@ func getConfig() (conf *config, err error) {
 	conf.MaxDuration = scconf.GetDuration(prefix + "max_duration")
 	conf.MaxDestinations = scconf.GetInt(prefix + "max_destinations")
 	conf.MaxDescriptionLength = scconf.GetInt(prefix + "max_description_length")
+	conf.MinStep = defaultMinStep
+	if scconf.IsSet(prefix + "min_step") {
+		conf.MinStep = scconf.GetDuration(prefix + "min_step")
+	}
+	conf.MaxSteps = defaultMaxSteps
+	if scconf.IsSet(prefix + "max_steps") {
+		conf.MaxSteps = scconf.GetInt(prefix + "max_steps")
+	}
+	conf.Treasury = scconf.GetString(prefix + "treasury")
 
 	err = conf.validate()
 	return
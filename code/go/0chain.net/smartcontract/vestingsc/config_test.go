@@ -7,6 +7,7 @@ import (
 
 	configpkg "0chain.net/chaincore/config"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -35,26 +36,32 @@ func Test_config_validate(t *testing.T) {
 		err    string
 	}{
 		// min lock
-		{config{-1, 0, 0, 0, 0}, "invalid min_lock (<= 0)"},
-		{config{0, 0, 0, 0, 0}, "invalid min_lock (<= 0)"},
+		{config{-1, 0, 0, 0, 0, 0, 0, ""}, "invalid min_lock (<= 0)"},
+		{config{0, 0, 0, 0, 0, 0, 0, ""}, "invalid min_lock (<= 0)"},
 		// min duration
-		{config{1, s(-1), 0, 0, 0}, "invalid min_duration (< 1s)"},
-		{config{1, s(0), 0, 0, 0}, "invalid min_duration (< 1s)"},
+		{config{1, s(-1), 0, 0, 0, 0, 0, ""}, "invalid min_duration (< 1s)"},
+		{config{1, s(0), 0, 0, 0, 0, 0, ""}, "invalid min_duration (< 1s)"},
 		// max duration
-		{config{1, s(1), s(0), 0, 0},
+		{config{1, s(1), s(0), 0, 0, 0, 0, ""},
 			"invalid max_duration: less or equal to min_duration"},
-		{config{1, s(1), s(1), 0, 0},
+		{config{1, s(1), s(1), 0, 0, 0, 0, ""},
 			"invalid max_duration: less or equal to min_duration"},
 		// max_destinations
-		{config{1, s(1), s(2), 0, 0}, "invalid max_destinations (< 1)"},
+		{config{1, s(1), s(2), 0, 0, 0, 0, ""}, "invalid max_destinations (< 1)"},
 		// max_description_length
-		{config{1, s(1), s(2), 1, 0}, "invalid max_description_length (< 1)"},
+		{config{1, s(1), s(2), 1, 0, 0, 0, ""}, "invalid max_description_length (< 1)"},
+		// min_step
+		{config{1, s(1), s(2), 1, 1, 0, 0, ""}, "invalid min_step (< 1s)"},
+		// max_steps
+		{config{1, s(1), s(2), 1, 1, s(1), 0, ""}, "invalid max_steps (< 1)"},
 	} {
 		t.Log(i)
 		assertErrMsg(t, tt.config.validate(), tt.err)
 	}
 }
 
+const treasuryID = "treasury_hex"
+
 func configureConfig() (configured *config) {
 	const pfx = "smart_contracts.vestingsc."
 
@@ -63,11 +70,16 @@ func configureConfig() (configured *config) {
 	configpkg.SmartContractConfig.Set(pfx+"max_duration", 10*time.Hour)
 	configpkg.SmartContractConfig.Set(pfx+"max_destinations", 2)
 	configpkg.SmartContractConfig.Set(pfx+"max_description_length", 20)
+	configpkg.SmartContractConfig.Set(pfx+"min_step", 1*time.Minute)
+	configpkg.SmartContractConfig.Set(pfx+"max_steps", 12)
+	configpkg.SmartContractConfig.Set(pfx+"treasury", treasuryID)
 
 	return &config{
 		100e10,
 		1 * time.Second, 10 * time.Hour,
 		2, 20,
+		1 * time.Minute, 12,
+		treasuryID,
 	}
 }
 
@@ -80,6 +92,26 @@ func Test_getConfig(t *testing.T) {
 	assert.EqualValues(t, configured, conf)
 }
 
+func Test_getConfig_defaultSteps(t *testing.T) {
+	const pfx = "smart_contracts.vestingsc."
+
+	var configured = configureConfig()
+	configured.MinStep, configured.MaxSteps = defaultMinStep, defaultMaxSteps
+
+	// sc.yaml without stepped vesting schedule configurations
+	var scconf = configpkg.SmartContractConfig
+	defer func() { configpkg.SmartContractConfig = scconf }()
+	configpkg.SmartContractConfig = viper.New()
+	for _, key := range []string{"min_lock", "min_duration", "max_duration",
+		"max_destinations", "max_description_length", "treasury"} {
+		configpkg.SmartContractConfig.Set(pfx+key, scconf.Get(pfx+key))
+	}
+
+	var conf, err = getConfig()
+	require.NoError(t, err)
+	assert.EqualValues(t, configured, conf)
+}
+
 func TestVestingSmartContract_getConfigHandler(t *testing.T) {
 
 	var (
@@ -165,5 +165,19 @@ func (c *Client) delete(t *testing.T, vsc *VestingSmartContract,
 	)
 	balances.(*testBalances).txn = tx
 	dr.PoolID = poolID
-	return vsc.unlock(tx, mustEncode(t, &dr), balances)
+	return vsc.delete(tx, mustEncode(t, &dr), balances)
+}
+
+func (c *Client) revoke(t *testing.T, vsc *VestingSmartContract,
+	poolID, dest datastore.Key, now common.Timestamp,
+	balances chainstate.StateContextI) (resp string, err error) {
+
+	var (
+		tx = newTransaction(c.id, ADDRESS, 0, now)
+		rr stopRequest
+	)
+	balances.(*testBalances).txn = tx
+	rr.PoolID = poolID
+	rr.Destination = dest
+	return vsc.revoke(tx, mustEncode(t, &rr), balances)
 }
@@ -21,6 +21,10 @@ func clientPoolsKey(vscKey, clientID datastore.Key) datastore.Key {
 
 type clientPools struct {
 	Pools []datastore.Key `json:"pools"`
+	// Revocable is list of pools the client can revoke destinations of.
+	Revocable []datastore.Key `json:"revocable,omitempty"`
+	// Revocations of pools of the client, or revoked by the client.
+	Revocations []*revocation `json:"revocations,omitempty"`
 }
 
 func (cp *clientPools) Encode() (b []byte) {
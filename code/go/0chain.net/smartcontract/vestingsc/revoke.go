@@ -0,0 +1,222 @@
+package vestingsc
+
+import (
+	"encoding/json"
+	"sort"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+)
+
+//
+// revocation of a destination of a revocable pool
+//
+
+// maxClientRevocations is number of the latest revocations kept in a
+// client's pools list, all revocations of a pool are in its history
+const maxClientRevocations = 50
+
+// revocation of a destination, recorded in the pool history and in the
+// pool owner's and the revoker's pools lists
+type revocation struct {
+	PoolID      datastore.Key    `json:"pool_id"`
+	Destination datastore.Key    `json:"destination"`
+	Revoker     datastore.Key    `json:"revoker"`
+	Treasury    datastore.Key    `json:"treasury"`
+	Vested      state.Balance    `json:"vested"`  // sent to destination
+	Revoked     state.Balance    `json:"revoked"` // sent to treasury
+	Time        common.Timestamp `json:"time"`
+	TxHash      string           `json:"tx_hash"`
+}
+
+func (r *revocation) encode() string {
+	var b, err = json.Marshal(r)
+	if err != nil {
+		panic(err) // must never happen
+	}
+	return string(b)
+}
+
+// revoke the destination sending vested tokens to the destination and
+// tokens not vested yet to the treasury, the destination removed
+func (vp *vestingPool) revoke(t *transaction.Transaction, destID,
+	treasury datastore.Key, balances chainstate.StateContextI) (
+	r *revocation, err error) {
+
+	var d *destination
+	if d, err = vp.find(destID); err != nil {
+		return
+	}
+
+	var before = d.Vested
+	_, err = vp.vest(t.ToClientID, destID, t.CreationDate, balances)
+	if err != nil && err != errZeroVesting {
+		return
+	}
+
+	// the pool can have less tokens than the destination is left to vest
+	var left = d.left()
+	if left > vp.Balance {
+		left = vp.Balance
+	}
+
+	r = &revocation{
+		PoolID:      vp.ID,
+		Destination: destID,
+		Revoker:     t.ClientID,
+		Treasury:    treasury,
+		Vested:      d.Vested - before,
+		Revoked:     left,
+		Time:        t.CreationDate,
+		TxHash:      t.Hash,
+	}
+
+	if r.Revoked > 0 {
+		_, err = vp.moveToDest(t.ToClientID, treasury, r.Revoked, balances)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = vp.delete(destID); err != nil {
+		return
+	}
+
+	vp.History = append(vp.History, r)
+	return
+}
+
+//
+// revocable pools and revocations of a client
+//
+
+func (cp *clientPools) addRevocable(poolID datastore.Key) {
+	var i = sort.SearchStrings(cp.Revocable, poolID)
+	if i < len(cp.Revocable) && cp.Revocable[i] == poolID {
+		return // already have
+	}
+	cp.Revocable = append(cp.Revocable[:i],
+		append([]string{poolID}, cp.Revocable[i:]...)...)
+}
+
+func (cp *clientPools) removeRevocable(poolID datastore.Key) {
+	var i = sort.SearchStrings(cp.Revocable, poolID)
+	if i < len(cp.Revocable) && cp.Revocable[i] == poolID {
+		cp.Revocable = append(cp.Revocable[:i], cp.Revocable[i+1:]...)
+	}
+}
+
+// isEmpty is true if the list can be removed
+func (cp *clientPools) isEmpty() bool {
+	return len(cp.Pools) == 0 && len(cp.Revocable) == 0 &&
+		len(cp.Revocations) == 0
+}
+
+// saveOrDelete the client's pools list, an empty list is removed
+func (cp *clientPools) saveOrDelete(vscKey, clientID datastore.Key,
+	balances chainstate.StateContextI) (err error) {
+
+	if cp.isEmpty() {
+		_, err = balances.DeleteTrieNode(clientPoolsKey(vscKey, clientID))
+		return
+	}
+	return cp.save(vscKey, clientID, balances)
+}
+
+// addRevocation to the client's pools list, the oldest revocations over
+// maxClientRevocations are removed
+func (cp *clientPools) addRevocation(r *revocation) {
+	cp.Revocations = append(cp.Revocations, r)
+	if over := len(cp.Revocations) - maxClientRevocations; over > 0 {
+		cp.Revocations = append(cp.Revocations[:0],
+			cp.Revocations[over:]...)
+	}
+}
+
+// addRevocation to pools lists of given clients
+func (vsc *VestingSmartContract) addRevocation(r *revocation,
+	balances chainstate.StateContextI, clientIDs ...datastore.Key) (
+	err error) {
+
+	for i, clientID := range clientIDs {
+		if i > 0 && clientID == clientIDs[0] {
+			continue // the same client
+		}
+		var cp *clientPools
+		if cp, err = vsc.getOrCreateClientPools(clientID, balances); err != nil {
+			return
+		}
+		cp.addRevocation(r)
+		if err = cp.save(vsc.ID, clientID, balances); err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// SC function
+//
+
+// revoke a destination by revoker of the pool
+func (vsc *VestingSmartContract) revoke(t *transaction.Transaction,
+	input []byte, balances chainstate.StateContextI) (resp string, err error) {
+
+	var rr stopRequest // the same fields
+	if err = rr.decode(input); err != nil {
+		return "", common.NewError("revoke_vesting_failed",
+			"malformed request: "+err.Error())
+	}
+
+	if rr.Destination == "" {
+		return "", common.NewError("revoke_vesting_failed",
+			"missing destination to revoke")
+	}
+
+	var conf *config
+	if conf, err = getConfig(); err != nil {
+		return "", common.NewError("revoke_vesting_failed",
+			"can't get SC configurations: "+err.Error())
+	}
+
+	if conf.Treasury == "" {
+		return "", common.NewError("revoke_vesting_failed",
+			"no treasury configured")
+	}
+
+	var vp *vestingPool
+	if vp, err = vsc.getPool(rr.PoolID, balances); err != nil {
+		return "", common.NewError("revoke_vesting_failed",
+			"can't get vesting pool: "+err.Error())
+	}
+
+	if vp.Revoker == "" || vp.Revoker != t.ClientID {
+		return "", common.NewError("revoke_vesting_failed",
+			"only revoker can revoke a vesting")
+	}
+
+	if t.CreationDate > vp.ExpireAt {
+		return "", common.NewError("revoke_vesting_failed", "expired pool")
+	}
+
+	var r *revocation
+	if r, err = vp.revoke(t, rr.Destination, conf.Treasury, balances); err != nil {
+		return "", common.NewError("revoke_vesting_failed", err.Error())
+	}
+
+	if err = vp.save(balances); err != nil {
+		return "", common.NewError("revoke_vesting_failed",
+			"saving pool: "+err.Error())
+	}
+
+	err = vsc.addRevocation(r, balances, vp.ClientID, vp.Revoker)
+	if err != nil {
+		return "", common.NewError("revoke_vesting_failed",
+			"saving client's pools list: "+err.Error())
+	}
+
+	return r.encode(), nil
+}
@@ -0,0 +1,150 @@
+package vestingsc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVestingSmartContract_revoke(t *testing.T) {
+	var (
+		vsc      = newTestVestingSC()
+		balances = newTestBalances()
+		owner    = newClient(1200e10, balances)
+		revoker  = newClient(0, balances)
+		dest1    = newClient(0, balances)
+		dest2    = newClient(0, balances)
+		tp       = common.Timestamp(10)
+		resp     string
+		err      error
+	)
+
+	configureConfig()
+
+	// the owner can't be the revoker, it would get tokens not vested back
+	_, err = owner.add(t, vsc, &addRequest{
+		StartTime: tp,
+		Duration:  100 * time.Second,
+		Destinations: destinations{
+			&destination{ID: dest1.id, Amount: 100e10},
+		},
+		Revoker: owner.id,
+	}, 100e10, tp, balances)
+	assertErrMsg(t, err, "create_vesting_pool_failed: "+
+		"pool owner can't be revoker of the pool")
+
+	resp, err = owner.add(t, vsc, &addRequest{
+		StartTime: tp,
+		Duration:  100 * time.Second,
+		Destinations: destinations{
+			&destination{ID: dest1.id, Amount: 100e10},
+			&destination{ID: dest2.id, Amount: 200e10},
+		},
+		Revoker: revoker.id,
+	}, 300e10, tp, balances)
+	require.NoError(t, err)
+	var vp vestingPool
+	require.NoError(t, vp.Decode([]byte(resp)))
+
+	// revocable pool is listed for the revoker
+	var cp *clientPools
+	cp, err = vsc.getClientPools(revoker.id, balances)
+	require.NoError(t, err)
+	assert.Equal(t, []string{vp.ID}, cp.Revocable)
+	assert.Len(t, cp.Pools, 0)
+
+	// not a revoker
+	_, err = owner.revoke(t, vsc, vp.ID, dest1.id, tp+30, balances)
+	assertErrMsg(t, err, "revoke_vesting_failed: "+
+		"only revoker can revoke a vesting")
+
+	_, err = revoker.revoke(t, vsc, vp.ID, "unknown", tp+30, balances)
+	assertErrMsg(t, err, "revoke_vesting_failed: "+
+		"destination unknown not found in the pool")
+
+	// 30% vested, 70% to treasury
+	resp, err = revoker.revoke(t, vsc, vp.ID, dest1.id, tp+30, balances)
+	require.NoError(t, err)
+	assert.NotZero(t, resp)
+
+	assert.Equal(t, state.Balance(30e10), balances.balances[dest1.id])
+	assert.Equal(t, state.Balance(70e10), balances.balances[treasuryID])
+
+	var got *vestingPool
+	got, err = vsc.getPool(vp.ID, balances)
+	require.NoError(t, err)
+	assert.Equal(t, state.Balance(200e10), got.Balance)
+	require.Len(t, got.Destinations, 1)
+	assert.Equal(t, dest2.id, got.Destinations[0].ID)
+	require.Len(t, got.History, 1)
+	var r = got.History[0]
+	assert.Equal(t, dest1.id, r.Destination)
+	assert.Equal(t, revoker.id, r.Revoker)
+	assert.Equal(t, treasuryID, r.Treasury)
+	assert.Equal(t, state.Balance(30e10), r.Vested)
+	assert.Equal(t, state.Balance(70e10), r.Revoked)
+
+	// surfaced by /getClientPools of the owner and the revoker
+	for _, id := range []string{owner.id, revoker.id} {
+		var val interface{}
+		val, err = vsc.getClientPoolsHandler(context.Background(),
+			url.Values{"client_id": []string{id}}, balances)
+		require.NoError(t, err)
+		assert.Equal(t, []*revocation{r}, val.(*clientPools).Revocations)
+	}
+
+	// the owner can't take the tokens not vested back
+	_, err = owner.stop(t, vsc, vp.ID, dest2.id, tp+40, balances)
+	assertErrMsg(t, err, "stop_vesting_failed: "+
+		"vesting of revocable pool can be revoked only")
+	_, err = owner.delete(t, vsc, vp.ID, tp+40, balances)
+	assertErrMsg(t, err, "delete_vesting_pool_failed: "+
+		"revocable pool can't be deleted before it expires")
+
+	// revoked tokens are limited by the pool balance
+	got.Balance = 150e10
+	require.NoError(t, got.save(balances))
+	_, err = revoker.revoke(t, vsc, vp.ID, dest2.id, tp+50, balances)
+	require.NoError(t, err)
+	assert.Equal(t, state.Balance(100e10), balances.balances[dest2.id])
+	assert.Equal(t, state.Balance(120e10), balances.balances[treasuryID])
+	got, err = vsc.getPool(vp.ID, balances)
+	require.NoError(t, err)
+	assert.Zero(t, got.Balance)
+	require.Len(t, got.History, 2)
+	assert.Equal(t, state.Balance(50e10), got.History[1].Revoked)
+
+	// can be deleted once expired
+	_, err = owner.delete(t, vsc, vp.ID, tp+101, balances)
+	require.NoError(t, err)
+
+	// not revocable pool
+	resp, err = owner.add(t, vsc, &addRequest{
+		StartTime:    tp,
+		Duration:     100 * time.Second,
+		Destinations: destinations{&destination{ID: dest1.id, Amount: 100e10}},
+	}, 100e10, tp, balances)
+	require.NoError(t, err)
+	require.NoError(t, vp.Decode([]byte(resp)))
+	_, err = revoker.revoke(t, vsc, vp.ID, dest1.id, tp+30, balances)
+	assertErrMsg(t, err, "revoke_vesting_failed: "+
+		"only revoker can revoke a vesting")
+}
+
+func Test_clientPools_addRevocation(t *testing.T) {
+	var cp clientPools
+	for i := 0; i < maxClientRevocations+10; i++ {
+		cp.addRevocation(&revocation{Time: common.Timestamp(i)})
+	}
+	require.Len(t, cp.Revocations, maxClientRevocations)
+	assert.Equal(t, common.Timestamp(10), cp.Revocations[0].Time)
+	assert.Equal(t, common.Timestamp(maxClientRevocations+9),
+		cp.Revocations[maxClientRevocations-1].Time)
+}
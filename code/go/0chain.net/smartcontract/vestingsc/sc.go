@@ -54,6 +54,10 @@ func (vsc *VestingSmartContract) SetSC(sc *smartcontractinterface.SmartContract,
 	vsc.SmartContractExecutionStats["stop"] = metrics.GetOrRegisterTimer(
 		fmt.Sprintf("sc:%v:func:%v", vsc.ID, "stop"), nil)
 
+	// revoke a destination of a revocable pool by the pool revoker
+	vsc.SmartContractExecutionStats["revoke"] = metrics.GetOrRegisterTimer(
+		fmt.Sprintf("sc:%v:func:%v", vsc.ID, "revoke"), nil)
+
 	// tokens unlock for an existing pool (as owner, as a destination)
 	vsc.SmartContractExecutionStats["unlock"] = metrics.GetOrRegisterTimer(
 		fmt.Sprintf("sc:%v:func:%v", vsc.ID, "unlock"), nil)
@@ -80,6 +84,8 @@ func (vsc *VestingSmartContract) Execute(t *transaction.Transaction,
 		resp, err = vsc.stop(t, input, balances)
 	case "delete":
 		resp, err = vsc.delete(t, input, balances)
+	case "revoke":
+		resp, err = vsc.revoke(t, input, balances)
 
 	default:
 		err = common.NewError("vesting_sc_failed",
@@ -0,0 +1,118 @@
+package vestingsc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+)
+
+// vesting schedule types
+const (
+	// scheduleLinear vests tokens continuously from pool start to its end.
+	scheduleLinear = "linear"
+	// scheduleCliffLinear is linear schedule, but nothing can be unlocked
+	// before the cliff; at the cliff all tokens vested since the start
+	// become available at once.
+	scheduleCliffLinear = "cliff_linear"
+	// scheduleStepped vests equal parts of tokens at the end of every step,
+	// the last step can be shorter.
+	scheduleStepped = "stepped"
+)
+
+// schedule of a vesting, nil or empty schedule is linear one
+type schedule struct {
+	Type  string        `json:"type,omitempty"`  // schedule type
+	Cliff time.Duration `json:"cliff,omitempty"` // cliff_linear only
+	Step  time.Duration `json:"step,omitempty"`  // stepped only
+}
+
+func (s *schedule) isLinear() bool {
+	return s == nil || s.Type == "" || s.Type == scheduleLinear
+}
+
+// validate the schedule for given vesting duration
+func (s *schedule) validate(duration time.Duration, conf *config) (
+	err error) {
+
+	if s.isLinear() {
+		if s != nil && (s.Cliff != 0 || s.Step != 0) {
+			return errors.New("linear schedule can't have cliff or step")
+		}
+		return
+	}
+	switch s.Type {
+	case scheduleCliffLinear:
+		switch {
+		case s.Step != 0:
+			return errors.New("cliff_linear schedule can't have step")
+		case toSeconds(s.Cliff) < 1:
+			return errors.New("invalid schedule cliff (< 1s)")
+		case s.Cliff >= duration:
+			return errors.New("schedule cliff is not shorter than duration")
+		}
+	case scheduleStepped:
+		switch {
+		case s.Cliff != 0:
+			return errors.New("stepped schedule can't have cliff")
+		case s.Step < conf.MinStep:
+			return errors.New("schedule step is too short")
+		case s.Step > duration:
+			return errors.New("schedule step is longer than duration")
+		case s.steps(toSeconds(duration)) > int64(conf.MaxSteps):
+			return errors.New("too many schedule steps")
+		}
+	default:
+		return fmt.Errorf("unknown schedule type %q", s.Type)
+	}
+	return
+}
+
+// steps of stepped schedule for given vesting duration
+func (s *schedule) steps(duration common.Timestamp) (n int64) {
+	var step = toSeconds(s.Step)
+	return int64((duration + step - 1) / step)
+}
+
+// next returns time of next vesting event after now, e.g. the cliff or the
+// next step; the end for continuous vesting
+func (s *schedule) next(now, start, end common.Timestamp) (
+	next common.Timestamp) {
+
+	switch {
+	case s.isLinear():
+	case s.Type == scheduleCliffLinear:
+		if cliff := start + toSeconds(s.Cliff); now < cliff {
+			return cliff
+		}
+	case s.Type == scheduleStepped:
+		var step = toSeconds(s.Step)
+		next = start + ((now-start)/step+1)*step
+		if next < end {
+			return next
+		}
+	}
+	return end
+}
+
+// stepped returns tokens the destination can unlock now by stepped
+// schedule; the now must be in [start; end] range
+func (s *schedule) stepped(d *destination, now, start,
+	end common.Timestamp) (amount state.Balance) {
+
+	if now == end {
+		return d.left() // pool ending, should drain all
+	}
+	var (
+		steps  = s.steps(end - start)
+		passed = int64((now - start) / toSeconds(s.Step))
+		target = state.Balance(float64(d.Amount) * float64(passed) /
+			float64(steps))
+	)
+	if target <= d.Vested {
+		return 0
+	}
+	return target - d.Vested
+}
@@ -0,0 +1,128 @@
+package vestingsc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_schedule_validate(t *testing.T) {
+
+	var conf = configureConfig()
+
+	for i, tt := range []struct {
+		schedule *schedule
+		err      string
+	}{
+		{nil, ""},
+		{&schedule{}, ""},
+		{&schedule{Type: scheduleLinear, Step: s(60)},
+			"linear schedule can't have cliff or step"},
+		{&schedule{Type: "monthly"}, `unknown schedule type "monthly"`},
+		// cliff
+		{&schedule{Type: scheduleCliffLinear, Cliff: s(60)}, ""},
+		{&schedule{Type: scheduleCliffLinear},
+			"invalid schedule cliff (< 1s)"},
+		{&schedule{Type: scheduleCliffLinear, Cliff: s(600)},
+			"schedule cliff is not shorter than duration"},
+		{&schedule{Type: scheduleCliffLinear, Cliff: s(60), Step: s(60)},
+			"cliff_linear schedule can't have step"},
+		// steps
+		{&schedule{Type: scheduleStepped, Step: s(60)}, ""},
+		{&schedule{Type: scheduleStepped, Step: s(59)},
+			"schedule step is too short"},
+		{&schedule{Type: scheduleStepped, Step: s(601)},
+			"schedule step is longer than duration"},
+		{&schedule{Type: scheduleStepped, Step: s(60), Cliff: s(60)},
+			"stepped schedule can't have cliff"},
+	} {
+		t.Log(i)
+		assertErrMsg(t, tt.schedule.validate(10*time.Minute, conf), tt.err)
+	}
+
+	// 13 steps of 5 minutes, but max_steps is 12
+	var sch = &schedule{Type: scheduleStepped, Step: 5 * time.Minute}
+	assertErrMsg(t, sch.validate(61*time.Minute, conf),
+		"too many schedule steps")
+}
+
+func Test_addRequest_validate_schedule(t *testing.T) {
+
+	var (
+		conf = configureConfig()
+		ar   addRequest
+	)
+	ar.StartTime = 10
+	ar.Duration = 10 * time.Minute
+	ar.Destinations = destinations{
+		&destination{ID: "one", Amount: 10},
+		&destination{ID: "two", Amount: 20, Schedule: &schedule{
+			Type: scheduleStepped, Step: s(10),
+		}},
+	}
+	assertErrMsg(t, ar.validate(10, conf),
+		`schedule of "two": schedule step is too short`)
+	ar.Destinations[1].Schedule.Step = 2 * time.Minute
+	require.NoError(t, ar.validate(10, conf))
+
+	ar.Schedule = &schedule{Type: scheduleCliffLinear}
+	assertErrMsg(t, ar.validate(10, conf), "invalid schedule cliff (< 1s)")
+}
+
+func Test_vestingPool_schedules(t *testing.T) {
+
+	var ar addRequest
+	ar.StartTime = 100
+	ar.Duration = 600 * time.Second
+	ar.Schedule = &schedule{Type: scheduleCliffLinear, Cliff: s(300)}
+	ar.Destinations = destinations{
+		&destination{ID: "cliff", Amount: 60},
+		&destination{ID: "stepped", Amount: 40, Schedule: &schedule{
+			Type: scheduleStepped, Step: s(180), // 4 steps, last is shorter
+		}},
+	}
+
+	var (
+		vp               = newVestingPoolFromReqeust("owner", &ar)
+		cliff, stepped   = vp.Destinations[0], vp.Destinations[1]
+		inf              = vp.info(150)
+		icliff, istepped = inf.Destinations[0], inf.Destinations[1]
+	)
+
+	// before the cliff and the first step
+	assert.Zero(t, icliff.Earned)
+	assert.EqualValues(t, 400, icliff.NextUnlock)
+	assert.EqualValues(t, 30, icliff.NextUnlockAmount)
+	assert.Zero(t, istepped.Earned)
+	assert.EqualValues(t, 280, istepped.NextUnlock)
+	assert.EqualValues(t, 10, istepped.NextUnlockAmount)
+	assert.Equal(t, ar.Destinations[1].Schedule, istepped.Schedule)
+
+	// the cliff
+	assert.EqualValues(t, 0, vp.unlock(cliff, 399, false))
+	assert.EqualValues(t, 30, vp.unlock(cliff, 400, false))
+	assert.EqualValues(t, 10, vp.unlock(cliff, 500, false))
+
+	// the steps
+	assert.EqualValues(t, 20, vp.unlock(stepped, 460, false))
+	assert.EqualValues(t, 0, vp.unlock(stepped, 500, false))
+	inf = vp.info(500)
+	assert.EqualValues(t, 640, inf.Destinations[1].NextUnlock)
+	assert.EqualValues(t, 10, inf.Destinations[1].NextUnlockAmount)
+	assert.EqualValues(t, 20, vp.unlock(stepped, 700, false))
+
+	// linear after the cliff, and the end
+	inf = vp.info(550)
+	assert.EqualValues(t, 550, inf.Destinations[0].NextUnlock)
+	assert.EqualValues(t, 5, inf.Destinations[0].NextUnlockAmount)
+	assert.EqualValues(t, 20, vp.unlock(cliff, 700, false))
+
+	// fully vested
+	inf = vp.info(700)
+	for _, di := range inf.Destinations {
+		assert.Zero(t, di.NextUnlock)
+		assert.Zero(t, di.NextUnlockAmount)
+	}
+}
@@ -64,6 +64,9 @@ type destination struct {
 	// can produce zero tokens transfer (resolution is a second). The move
 	// will be updated only if a triggering really moves tokens (non zero).
 	Move common.Timestamp `json:"move"`
+	// Schedule is custom vesting schedule of the destination, if not set,
+	// then schedule of the pool used.
+	Schedule *schedule `json:"schedule,omitempty"`
 }
 
 // tokens left for this destination
@@ -91,12 +94,35 @@ func (d *destination) move(now common.Timestamp, moved state.Balance) {
 	}
 }
 
-// The unlock returns amount of tokens to vest for current period.
-// The dry argument leave all inside the destination as it was and
+// The unlock returns amount of tokens to vest for current period by given
+// schedule. The dry argument leave all inside the destination as it was and
 // used to obtain pool statistic. The now must not be later then the
 // end. Also, the now must be greater or equal to start time of related
 // vesting pool.
-func (d *destination) unlock(now, end common.Timestamp, dry bool) (
+func (d *destination) unlock(now, start, end common.Timestamp, s *schedule,
+	dry bool) (amount state.Balance) {
+
+	switch {
+	case s.isLinear():
+		amount = d.linear(now, end)
+	case s.Type == scheduleCliffLinear:
+		if now >= start+toSeconds(s.Cliff) {
+			amount = d.linear(now, end)
+		}
+	case s.Type == scheduleStepped:
+		amount = s.stepped(d, now, start, end)
+	}
+
+	if !dry {
+		d.move(now, amount)
+	}
+
+	return
+}
+
+// linear returns amount of tokens to vest for current period, vesting
+// tokens left evenly till the end
+func (d *destination) linear(now, end common.Timestamp) (
 	amount state.Balance) {
 
 	var (
@@ -113,13 +139,7 @@ func (d *destination) unlock(now, end common.Timestamp, dry bool) (
 		ratio = float64(period) / float64(full)
 	}
 
-	amount = state.Balance(float64(left) * ratio)
-
-	if !dry {
-		d.move(now, amount)
-	}
-
-	return
+	return state.Balance(float64(left) * ratio)
 }
 
 //
@@ -146,6 +166,8 @@ type addRequest struct {
 	StartTime    common.Timestamp `json:"start_time"`            //
 	Duration     time.Duration    `json:"duration"`              //
 	Destinations destinations     `json:"destinations"`          //
+	Schedule     *schedule        `json:"schedule,omitempty"`    // linear
+	Revoker      datastore.Key    `json:"revoker,omitempty"`     // optional
 }
 
 func (ar *addRequest) decode(b []byte) error {
@@ -174,12 +196,26 @@ func (ar *addRequest) validate(now common.Timestamp, conf *config) (err error) {
 		return errors.New("no destinations")
 	case len(ar.Destinations) > conf.MaxDestinations:
 		return errors.New("too many destinations")
+	case ar.Revoker != "" && conf.Treasury == "":
+		return errors.New("revocable pools disabled, no treasury configured")
+	}
+
+	if ar.Schedule != nil {
+		if err = ar.Schedule.validate(ar.Duration, conf); err != nil {
+			return
+		}
 	}
 
 	for _, d := range ar.Destinations {
 		if d.Amount < 0 {
 			return fmt.Errorf("negative amount for %q: %d", d.ID, d.Amount)
 		}
+		if d.Schedule == nil {
+			continue
+		}
+		if err = d.Schedule.validate(ar.Duration, conf); err != nil {
+			return fmt.Errorf("schedule of %q: %v", d.ID, err)
+		}
 	}
 	return
 }
@@ -200,6 +236,13 @@ type vestingPool struct {
 	ExpireAt     common.Timestamp `json:"expire_at"`    //
 	Destinations destinations     `json:"destinations"` //
 	ClientID     datastore.Key    `json:"client_id"`    // the pool owner
+	// Schedule of destinations without custom one, nil is linear.
+	Schedule *schedule `json:"schedule,omitempty"`
+	// Revoker can revoke a destination sending tokens not vested yet to
+	// the treasury, empty for not revocable pool.
+	Revoker datastore.Key `json:"revoker,omitempty"`
+	// History of revocations of the pool.
+	History []*revocation `json:"history,omitempty"`
 }
 
 // newVestingPool returns new empty uninitialized vesting pool.
@@ -221,9 +264,26 @@ func newVestingPoolFromReqeust(clientID datastore.Key, ar *addRequest) (
 	vp.ExpireAt = ar.StartTime + toSeconds(ar.Duration)
 	vp.Destinations = ar.Destinations
 	vp.Destinations.start(vp.StartTime)
+	vp.Schedule = ar.Schedule
+	vp.Revoker = ar.Revoker
 	return
 }
 
+// schedule of the destination
+func (vp *vestingPool) schedule(d *destination) *schedule {
+	if d.Schedule != nil {
+		return d.Schedule
+	}
+	return vp.Schedule
+}
+
+// unlock tokens of the destination (see destination.unlock)
+func (vp *vestingPool) unlock(d *destination, now common.Timestamp,
+	dry bool) state.Balance {
+
+	return d.unlock(now, vp.StartTime, vp.ExpireAt, vp.schedule(d), dry)
+}
+
 // Encode the vesting pool from JSON value. Implements
 // required util.Serializale interface.
 func (vp *vestingPool) Encode() (b []byte) {
@@ -330,7 +390,7 @@ func (vp *vestingPool) trigger(t *transaction.Transaction,
 	)
 	sb.WriteByte('[')
 	for _, d := range vp.Destinations {
-		var value = d.unlock(now, end, false)
+		var value = vp.unlock(d, now, false)
 		if value == 0 {
 			continue
 		}
@@ -409,7 +469,7 @@ func (vp *vestingPool) vest(vscID, destID datastore.Key, now common.Timestamp,
 		return
 	}
 
-	var value = d.unlock(now, end, false)
+	var value = vp.unlock(d, now, false)
 	if value == 0 {
 		return "", errZeroVesting
 	}
@@ -477,18 +537,31 @@ func (vp *vestingPool) info(now common.Timestamp) (i *info) {
 
 	var dinfos = make([]*destInfo, 0, len(vp.Destinations))
 	for _, d := range vp.Destinations {
-		var value = d.unlock(now, end, true)
-		dinfos = append(dinfos, &destInfo{
-			ID:     d.ID,
-			Wanted: d.Amount,
-			Earned: value,
-			Vested: d.Vested,
-			Last:   d.Last,
-		})
+		var di = &destInfo{
+			ID:       d.ID,
+			Wanted:   d.Amount,
+			Earned:   vp.unlock(d, now, true),
+			Vested:   d.Vested,
+			Last:     d.Last,
+			Schedule: vp.schedule(d),
+		}
+		// next unlock is now, if there are earned tokens, or next vesting
+		// event (a cliff, a step, or the end of continuous vesting)
+		di.NextUnlock, di.NextUnlockAmount = now, di.Earned
+		if di.Earned == 0 && d.left() > 0 {
+			di.NextUnlock = vp.schedule(d).next(now, vp.StartTime, end)
+			di.NextUnlockAmount = vp.unlock(d, di.NextUnlock, true)
+		}
+		if di.NextUnlockAmount == 0 {
+			di.NextUnlock = 0 // fully vested
+		}
+		dinfos = append(dinfos, di)
 	}
 
 	i.Destinations = dinfos
 	i.ClientID = vp.ClientID
+	i.Revoker = vp.Revoker
+	i.History = vp.History
 	return
 }
 
@@ -498,6 +571,11 @@ type destInfo struct {
 	Earned state.Balance    `json:"earned"` // can unlock
 	Vested state.Balance    `json:"vested"` // tokens already vested
 	Last   common.Timestamp `json:"last"`   // last time unlocked
+	// next time the destination can unlock tokens and amount can be
+	// unlocked at the time
+	NextUnlock       common.Timestamp `json:"next_unlock,omitempty"`
+	NextUnlockAmount state.Balance    `json:"next_unlock_amount,omitempty"`
+	Schedule         *schedule        `json:"schedule,omitempty"` // nil is linear
 }
 
 type info struct {
@@ -509,6 +587,8 @@ type info struct {
 	ExpireAt     common.Timestamp `json:"expire_at"`    // until
 	Destinations []*destInfo      `json:"destinations"` // receivers
 	ClientID     datastore.Key    `json:"client_id"`    // owner
+	Revoker      datastore.Key    `json:"revoker,omitempty"`
+	History      []*revocation    `json:"history,omitempty"`
 }
 
 //
@@ -571,6 +651,13 @@ func (vsc *VestingSmartContract) add(t *transaction.Transaction,
 			"empty client_id of transaction")
 	}
 
+	// the owner stopping or deleting the pool receives tokens not vested
+	// yet back, bypassing the treasury
+	if ar.Revoker == t.ClientID {
+		return "", common.NewError("create_vesting_pool_failed",
+			"pool owner can't be revoker of the pool")
+	}
+
 	var vp = newVestingPoolFromReqeust(t.ClientID, &ar)
 	vp.ID = poolKey(vsc.ID, t.Hash) // set ID by this transaction
 
@@ -600,6 +687,20 @@ func (vsc *VestingSmartContract) add(t *transaction.Transaction,
 			"can't save client's pools list: "+err.Error())
 	}
 
+	if vp.Revoker != "" {
+		var rcp *clientPools
+		rcp, err = vsc.getOrCreateClientPools(vp.Revoker, balances)
+		if err != nil {
+			return "", common.NewError("create_vesting_pool_failed",
+				"unexpected error: "+err.Error())
+		}
+		rcp.addRevocable(vp.ID)
+		if err = rcp.save(vsc.ID, vp.Revoker, balances); err != nil {
+			return "", common.NewError("create_vesting_pool_failed",
+				"can't save revoker's pools list: "+err.Error())
+		}
+	}
+
 	if err = vp.save(balances); err != nil {
 		return "", common.NewError("create_vesting_pool_failed",
 			"can't save pool: "+err.Error())
@@ -633,6 +734,12 @@ func (vsc *VestingSmartContract) stop(t *transaction.Transaction,
 			"only owner can stop a vesting")
 	}
 
+	// otherwise the owner gets tokens not vested back instead of treasury
+	if vp.Revoker != "" {
+		return "", common.NewError("stop_vesting_failed",
+			"vesting of revocable pool can be revoked only")
+	}
+
 	if t.CreationDate > vp.ExpireAt {
 		return "", common.NewError("stop_vesting_failed", "expired pool")
 	}
@@ -685,6 +792,12 @@ func (vsc *VestingSmartContract) delete(t *transaction.Transaction,
 			"only pool owner can delete the pool")
 	}
 
+	// otherwise the owner gets tokens not vested back instead of treasury
+	if vp.Revoker != "" && t.CreationDate <= vp.ExpireAt {
+		return "", common.NewError("delete_vesting_pool_failed",
+			"revocable pool can't be deleted before it expires")
+	}
+
 	// move tokens to destinations
 	if vp.Balance > 0 {
 		if _, err = vp.trigger(t, balances); err != nil {
@@ -712,7 +825,7 @@ func (vsc *VestingSmartContract) delete(t *transaction.Transaction,
 	if len(cp.Pools) > 0 {
 		cp.remove(vp.ID)
 
-		if len(cp.Pools) == 0 {
+		if cp.isEmpty() {
 			_, err = balances.DeleteTrieNode(clientPoolsKey(vsc.ID, t.ClientID))
 			if err != nil {
 				return "", common.NewError("delete_vesting_pool_failed",
@@ -726,6 +839,20 @@ func (vsc *VestingSmartContract) delete(t *transaction.Transaction,
 		}
 	}
 
+	if vp.Revoker != "" {
+		var rcp *clientPools
+		rcp, err = vsc.getOrCreateClientPools(vp.Revoker, balances)
+		if err != nil {
+			return "", common.NewError("delete_vesting_pool_failed",
+				"unexpected error: "+err.Error())
+		}
+		rcp.removeRevocable(vp.ID)
+		if err = rcp.saveOrDelete(vsc.ID, vp.Revoker, balances); err != nil {
+			return "", common.NewError("delete_vesting_pool_failed",
+				"can't save revoker's pools list: "+err.Error())
+		}
+	}
+
 	if _, err = balances.DeleteTrieNode(vp.ID); err != nil {
 		return "", common.NewError("delete_vesting_pool_failed",
 			"can't delete vesting pool: "+err.Error())
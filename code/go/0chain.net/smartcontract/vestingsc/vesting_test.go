@@ -105,8 +105,10 @@ func Test_vestingPool(t *testing.T) {
 	assert.Equal(t, vp.StartTime, inf.StartTime)
 	assert.Equal(t, vp.ExpireAt, inf.ExpireAt)
 	assert.EqualValues(t, []*destInfo{
-		&destInfo{ID: "one", Wanted: 10, Earned: 5, Vested: 0, Last: 10},
-		&destInfo{ID: "two", Wanted: 20, Earned: 10, Vested: 0, Last: 10},
+		&destInfo{ID: "one", Wanted: 10, Earned: 5, Vested: 0, Last: 10,
+			NextUnlock: 11, NextUnlockAmount: 5},
+		&destInfo{ID: "two", Wanted: 20, Earned: 10, Vested: 0, Last: 10,
+			NextUnlock: 11, NextUnlockAmount: 10},
 	}, inf.Destinations) // TODO
 	assert.Equal(t, state.Balance(40), inf.Balance)
 	assert.Equal(t, state.Balance(10), inf.Left)
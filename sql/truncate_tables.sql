@@ -1 +1,2 @@
 truncate zerochain.txn_summary;
+truncate zerochain.txn_event;
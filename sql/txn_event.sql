@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS zerochain.txn_event (
+    type text,
+    round_bucket bigint,
+    round bigint,
+    txn_hash text,
+    idx int,
+    attributes map<text, text>,
+    PRIMARY KEY ((type, round_bucket), round, txn_hash, idx)
+);